@@ -0,0 +1,242 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	"github.com/pusher/faros/pkg/controller/gittrack"
+	gittrackutils "github.com/pusher/faros/pkg/controller/gittrack/utils"
+	"github.com/pusher/faros/pkg/controller/gittrackobject"
+	farosflags "github.com/pusher/faros/pkg/flags"
+	"github.com/pusher/faros/pkg/utils"
+	farosclient "github.com/pusher/faros/pkg/utils/client"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// diffOptions are the options of the diff command
+type diffOptions struct {
+	gitTrack types.NamespacedName
+	dir      string
+	ref      string
+}
+
+// diffSummary counts the changes listed by the diff command
+type diffSummary struct {
+	create, update, prune, kept, ignored, errors int
+}
+
+func newDiffCommand() *cobra.Command {
+	o := &diffOptions{}
+	cmd := &cobra.Command{
+		Use:   "diff NAMESPACE/GITTRACK [directory]",
+		Short: "Show the changes Faros would make to the cluster for a local checkout",
+		Long: `Show the changes Faros would make to the cluster if the GitTrack tracked a
+local checkout of its repository, defaulting to the current directory. Children
+are created, updated and pruned the way the controller would, validating
+changes with a server dry run, but nothing is changed. The flags of the
+controller are accepted to make the same decisions it makes.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			split := strings.SplitN(args[0], "/", 2)
+			if len(split) != 2 || split[0] == "" || split[1] == "" {
+				return fmt.Errorf("%s is invalid, should be of format <namespace>/<name>", args[0])
+			}
+			o.gitTrack = types.NamespacedName{Namespace: split[0], Name: split[1]}
+			o.dir = "."
+			if len(args) > 1 {
+				o.dir = args[1]
+			}
+			return o.run(cmd.OutOrStdout(), os.Stderr)
+		},
+	}
+	cmd.Flags().StringVar(&o.ref, "ref", "", "Reference of the repository in the directory to load files at rather than its working tree")
+	cmd.Flags().AddFlagSet(farosflags.FlagSet)
+	return cmd
+}
+
+// run prints the changes the GitTrack would make to out
+func (o *diffOptions) run(out, warnings io.Writer) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("unable to get kubeconfig: %v", err)
+	}
+	c, err := client.New(cfg, client.Options{Scheme: staticScheme})
+	if err != nil {
+		return fmt.Errorf("unable to create client: %v", err)
+	}
+
+	gt := &farosv1alpha1.GitTrack{}
+	if err = c.Get(context.TODO(), o.gitTrack, gt); err != nil {
+		return fmt.Errorf("unable to get GitTrack %s: %v", o.gitTrack, err)
+	}
+	if gt.Spec.Cluster != nil {
+		return fmt.Errorf("GitTrack %s deploys to a remote cluster, which isn't supported", o.gitTrack)
+	}
+
+	var files map[string]string
+	var revision string
+	if o.ref != "" {
+		files, revision, err = loadFilesAt(o.dir, o.ref, gt.Spec.SubPath)
+	} else {
+		files, err = loadFiles(o.dir, gt.Spec.SubPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	restMapper, err := utils.NewRestMapper(cfg)
+	if err != nil {
+		return err
+	}
+	// The working tree has no commit of its own, record the applied revision
+	// for changed children instead
+	commit := revision
+	if commit == "" {
+		commit = gt.Status.AppliedRevision
+	}
+	r, err := render(files, o.gitTrack.String(), commit, restMapper, warnings)
+	if err != nil {
+		return err
+	}
+	owned, err := gittrack.ListChildren(c, gt)
+	if err != nil {
+		return fmt.Errorf("unable to list children of GitTrack %s: %v", o.gitTrack, err)
+	}
+
+	applier, err := farosclient.NewApplier(cfg, farosclient.Options{})
+	if err != nil {
+		return fmt.Errorf("unable to create applier: %v", err)
+	}
+	ignoredFields, err := farosflags.ParseIgnoredFields()
+	if err != nil {
+		return fmt.Errorf("unable to parse ignored fields: %v", err)
+	}
+	protectedKinds, err := farosflags.ParseProtectedKinds()
+	if err != nil {
+		return fmt.Errorf("unable to parse protected kinds: %v", err)
+	}
+
+	summary := &diffSummary{errors: r.invalid}
+	for _, gto := range r.objects {
+		// As in the GitTrack controller, unchanged children keep the commit
+		// they were last changed by. The existing GitTrackObject is the
+		// controller of the child, so the preview is made as it, and its
+		// status records the data last applied to the child.
+		if existing, ok := owned[gto.GetNamespacedName()]; ok {
+			gittrackutils.RecordSourceCommit(gto, existing, commit)
+			gto.SetUID(existing.GetUID())
+			gto.SetStatus(existing.GetStatus())
+		}
+		printChildPreview(out, summary, c, gt, gto, applier, ignoredFields)
+	}
+	leftovers := make(map[string]farosv1alpha1.GitTrackObjectInterface)
+	for name, gto := range owned {
+		if !r.names[name] {
+			leftovers[name] = gto
+		}
+	}
+	kept, err := gittrack.PreviewPrune(c, gt, leftovers, protectedKinds)
+	if err != nil {
+		return err
+	}
+	if err = gittrack.CheckPruneThreshold(gt, len(owned), len(leftovers)-len(kept), revision); err != nil {
+		fmt.Fprintf(out, "! %v\n", err)
+	} else {
+		for _, name := range sortedNames(leftovers) {
+			gto := leftovers[name]
+			desc := fmt.Sprintf("%s %s (%s)", gto.GetSpec().Kind, strings.TrimLeft(gto.GetNamespace()+"/"+gto.GetSpec().Name, "/"), name)
+			if reason, ok := kept[name]; ok {
+				summary.kept++
+				fmt.Fprintf(out, "  keep %s: %s\n", desc, reason)
+				continue
+			}
+			summary.prune++
+			fmt.Fprintf(out, "- prune %s\n", desc)
+		}
+	}
+
+	ignored := []string{}
+	for name := range r.ignoredFiles {
+		ignored = append(ignored, name)
+	}
+	sort.Strings(ignored)
+	for _, name := range ignored {
+		summary.ignored++
+		fmt.Fprintf(out, "  ignore %s: %s\n", name, strings.TrimSpace(r.ignoredFiles[name]))
+	}
+
+	fmt.Fprintf(out, "\nGitTrack %s: %d to create, %d to update, %d to prune, %d kept, %d ignored, %d errors\n",
+		o.gitTrack, summary.create, summary.update, summary.prune, summary.kept, summary.ignored, summary.errors)
+	if summary.errors > 0 {
+		return fmt.Errorf("%d errors", summary.errors)
+	}
+	return nil
+}
+
+// printChildPreview prints the change applying the GitTrackObject would make
+// to its child
+func printChildPreview(out io.Writer, summary *diffSummary, c client.Reader, gt *farosv1alpha1.GitTrack, gto farosv1alpha1.GitTrackObjectInterface, applier farosclient.Client, ignoredFields map[schema.GroupKind][]string) {
+	child, err := utils.YAMLToUnstructured(gto.GetSpec().Data)
+	if err != nil {
+		summary.errors++
+		fmt.Fprintf(out, "! error %s: unable to unmarshal data: %v\n", gto.GetNamespacedName(), err)
+		return
+	}
+	desc := fmt.Sprintf("%s %s (%s)", child.GetKind(), strings.TrimLeft(child.GetNamespace()+"/"+child.GetName(), "/"), gittrackutils.ObjectNamespacedName(&child))
+
+	preview, err := gittrackobject.PreviewChild(c, applier, staticScheme, gt, gto, ignoredFields, farosflags.ServerDryRun)
+	if err != nil {
+		summary.errors++
+		fmt.Fprintf(out, "! error %s: %v\n", desc, err)
+		return
+	}
+
+	switch preview.Action {
+	case gittrackobject.PreviewCreate:
+		summary.create++
+		fmt.Fprintf(out, "+ create %s\n", desc)
+	case gittrackobject.PreviewUpdate:
+		summary.update++
+		reported := ""
+		if preview.Reported {
+			reported = ", reported by drift policy rather than applied"
+		}
+		fmt.Fprintf(out, "~ update %s: %s%s\n", desc, strings.Join(preview.Fields, ", "), reported)
+		fmt.Fprintf(out, "    %s\n", preview.Patch)
+	}
+}
+
+// sortedNames returns the names of the children in order
+func sortedNames(children map[string]farosv1alpha1.GitTrackObjectInterface) []string {
+	names := []string{}
+	for name := range children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
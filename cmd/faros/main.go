@@ -0,0 +1,51 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	goflag "flag"
+
+	"github.com/spf13/cobra"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+)
+
+func main() {
+	cmd := &cobra.Command{
+		Use:          "faros",
+		Short:        "Work with the repositories tracked by Faros",
+		SilenceUsage: true,
+	}
+	// Expose the kubeconfig flags registered by controller-runtime
+	cmd.PersistentFlags().AddGoFlagSet(goflag.CommandLine)
+	cmd.AddCommand(newRenderCommand())
+	cmd.AddCommand(newDiffCommand())
+	cmd.AddCommand(&cobra.Command{
+		Use:   "version",
+		Short: "Show version and exit",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf("faros %s (built with %s)\n", VERSION, runtime.Version())
+		},
+	})
+
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,333 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/gobwas/glob"
+	"github.com/pusher/faros/pkg/apis"
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	farosv1beta1 "github.com/pusher/faros/pkg/apis/faros/v1beta1"
+	gittrackutils "github.com/pusher/faros/pkg/controller/gittrack/utils"
+	farosflags "github.com/pusher/faros/pkg/flags"
+	"github.com/pusher/faros/pkg/utils"
+	"github.com/spf13/cobra"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// staticScheme holds the kinds mapped to resources without a cluster
+var staticScheme = runtime.NewScheme()
+
+func init() {
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		scheme.AddToScheme,
+		apiextensionsv1beta1.AddToScheme,
+		apis.AddToScheme,
+	} {
+		if err := addToScheme(staticScheme); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// renderOptions are the options of the render command
+type renderOptions struct {
+	dir     string
+	subPath string
+	output  string
+}
+
+// rendered holds the children Faros would create from a checkout and the
+// reasons files and objects would be ignored, by path or child name
+type rendered struct {
+	objects      []farosv1alpha1.GitTrackObjectInterface
+	ignoredFiles map[string]string
+
+	// names are the namespaced names of the children of every object,
+	// including those ignored
+	names map[string]bool
+
+	// invalid counts the files that couldn't be parsed and the objects whose
+	// names collide
+	invalid int
+}
+
+// renderOutput is the document printed by the render command
+type renderOutput struct {
+	Objects      []runtime.Object  `json:"objects"`
+	IgnoredFiles map[string]string `json:"ignoredFiles,omitempty"`
+}
+
+func newRenderCommand() *cobra.Command {
+	o := &renderOptions{}
+	cmd := &cobra.Command{
+		Use:   "render [directory]",
+		Short: "Print the GitTrackObjects Faros would create from a local checkout",
+		Long: `Print the GitTrackObjects Faros would create from a local checkout of a
+repository, defaulting to the current directory, and the files and objects it
+would ignore. Unparseable files and objects with colliding names make the
+command fail.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.dir = "."
+			if len(args) > 0 {
+				o.dir = args[0]
+			}
+			return o.run(cmd.OutOrStdout(), os.Stderr)
+		},
+	}
+	cmd.Flags().StringVar(&o.subPath, "sub-path", "", "Path within the checkout to load files from, as in a GitTrack's spec")
+	cmd.Flags().StringVarP(&o.output, "output", "o", "yaml", "Output format, either yaml or json")
+	cmd.Flags().AddFlag(farosflags.FlagSet.Lookup("namespace"))
+	cmd.Flags().AddFlag(farosflags.FlagSet.Lookup("ignore-resource"))
+	return cmd
+}
+
+// run renders the checkout and prints the result to out
+func (o *renderOptions) run(out, warnings io.Writer) error {
+	if o.output != "yaml" && o.output != "json" {
+		return fmt.Errorf("unknown output format '%s', should be yaml or json", o.output)
+	}
+
+	files, err := loadFiles(o.dir, o.subPath)
+	if err != nil {
+		return err
+	}
+	r, err := render(files, "", "", nil, warnings)
+	if err != nil {
+		return err
+	}
+
+	doc := renderOutput{Objects: []runtime.Object{}, IgnoredFiles: r.ignoredFiles}
+	for _, gto := range r.objects {
+		obj, err := printable(gto)
+		if err != nil {
+			return err
+		}
+		doc.Objects = append(doc.Objects, obj)
+	}
+
+	var data []byte
+	if o.output == "json" {
+		data, err = json.MarshalIndent(doc, "", "  ")
+		data = append(data, '\n')
+	} else {
+		data, err = yaml.Marshal(doc)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to marshal output: %v", err)
+	}
+	if _, err = out.Write(data); err != nil {
+		return err
+	}
+
+	if r.invalid > 0 {
+		return fmt.Errorf("%d files or objects are invalid", r.invalid)
+	}
+	return nil
+}
+
+// render creates the children Faros would create from the files, the same way
+// the GitTrack controller does from a repository. Kinds are mapped to
+// resources by the cluster's restMapper if one is given, falling back to the
+// kinds known without a cluster. The GitTrack and commit are recorded on the
+// children when they are known.
+func render(files map[string]string, gitTrack, commit string, clusterMapper meta.RESTMapper, warnings io.Writer) (*rendered, error) {
+	objects, fileErrors := gittrackutils.ObjectsFrom(files)
+	for _, u := range objects {
+		utils.SetGitTrack(u, gitTrack)
+	}
+	r := &rendered{
+		objects:      []farosv1alpha1.GitTrackObjectInterface{},
+		ignoredFiles: fileErrors,
+		names:        make(map[string]bool),
+		invalid:      len(fileErrors),
+	}
+
+	ignoredGVRs, err := farosflags.ParseIgnoredResources()
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse ignored resources: %v", err)
+	}
+	restMapper, err := utils.NewStaticRestMapper(staticScheme, objects)
+	if err != nil {
+		return nil, err
+	}
+	if clusterMapper != nil {
+		restMapper = meta.FirstHitRESTMapper{MultiRESTMapper: meta.MultiRESTMapper{clusterMapper, restMapper}}
+	}
+
+	collisions := gittrackutils.NameCollisions(objects)
+	for _, u := range objects {
+		name := gittrackutils.ObjectNamespacedName(u)
+		r.names[name] = true
+		if reason, ok := collisions[u]; ok {
+			r.ignoredFiles[gittrackutils.IgnoredObjectKey(u)] = reason
+			r.invalid++
+			continue
+		}
+
+		gvk := u.GroupVersionKind()
+		gvr, namespaced, err := utils.GetAPIResource(restMapper, gvk)
+		if err != nil {
+			// Kinds unknown without a cluster are assumed to be namespaced if
+			// the object has a namespace
+			gvr, _ = meta.UnsafeGuessKindToResource(gvk)
+			namespaced = u.GetNamespace() != ""
+			fmt.Fprintf(warnings, "warning: kind %s is unknown, assuming resource %s.%s/%s (namespaced: %t)\n", gvk.Kind, gvr.Resource, gvr.Group, gvr.Version, namespaced)
+		}
+
+		if ignored, reason := gittrackutils.IgnoreObject(u, gvr, namespaced, farosflags.Namespace, ignoredGVRs); ignored {
+			r.ignoredFiles[name] = reason
+			continue
+		}
+
+		gto, err := gittrackutils.NewGitTrackObject(u, namespaced)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create child '%s': %v", name, err)
+		}
+		gittrackutils.RecordSourceCommit(gto, nil, commit)
+		r.objects = append(r.objects, gto)
+	}
+	return r, nil
+}
+
+// loadFiles reads the files under the subpath of the checkout in dir which
+// match the glob Faros loads files from repositories with, by path relative to
+// dir. Like in repositories, symlinks are ignored.
+func loadFiles(dir, subPath string) (map[string]string, error) {
+	g, err := glob.Compile(gittrackutils.FilesGlob(subPath))
+	if err != nil {
+		return nil, fmt.Errorf("invalid subpath '%s': %v", subPath, err)
+	}
+
+	files := make(map[string]string)
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if !g.Match(rel) {
+			return nil
+		}
+
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[rel] = string(contents)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to load files from '%s': %v", dir, err)
+	} else if len(files) == 0 {
+		return nil, fmt.Errorf("no files for subpath '%s'", subPath)
+	}
+	return files, nil
+}
+
+// loadFilesAt reads the files under the subpath of the repository checked out
+// in dir at the given reference, like loadFiles does from the working tree.
+// The revision of the reference is returned with the files.
+func loadFilesAt(dir, ref, subPath string) (map[string]string, string, error) {
+	g, err := glob.Compile(gittrackutils.FilesGlob(subPath))
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid subpath '%s': %v", subPath, err)
+	}
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to open repository '%s': %v", dir, err)
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to resolve '%s': %v", ref, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to get commit '%s': %v", hash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to get tree of commit '%s': %v", hash, err)
+	}
+
+	files := make(map[string]string)
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if f.Mode == filemode.Symlink || !g.Match(f.Name) {
+			return nil
+		}
+		contents, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		files[f.Name] = contents
+		return nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to load files from '%s' at '%s': %v", dir, ref, err)
+	} else if len(files) == 0 {
+		return nil, "", fmt.Errorf("no files for subpath '%s'", subPath)
+	}
+	return files, hash.String(), nil
+}
+
+// printable converts a child to v1beta1, which embeds the tracked object
+// rather than its encoded bytes
+func printable(gto farosv1alpha1.GitTrackObjectInterface) (runtime.Object, error) {
+	var out interface {
+		runtime.Object
+		ConvertFrom(src runtime.Object) error
+	}
+	switch gto.(type) {
+	case *farosv1alpha1.GitTrackObject:
+		out = &farosv1beta1.GitTrackObject{}
+	case *farosv1alpha1.ClusterGitTrackObject:
+		out = &farosv1beta1.ClusterGitTrackObject{}
+	default:
+		return nil, fmt.Errorf("unexpected child type %T", gto)
+	}
+	if err := out.ConvertFrom(gto); err != nil {
+		return nil, fmt.Errorf("unable to convert child '%s': %v", gto.GetNamespacedName(), err)
+	}
+	out.GetObjectKind().SetGroupVersionKind(farosv1beta1.SchemeGroupVersion.WithKind(gto.GetObjectKind().GroupVersionKind().Kind))
+	return out, nil
+}
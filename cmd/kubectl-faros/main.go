@@ -0,0 +1,112 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	goflag "flag"
+
+	"github.com/pusher/faros/pkg/apis"
+	"github.com/spf13/cobra"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// pluginScheme holds the kinds the plugin reads and writes
+var pluginScheme = k8sruntime.NewScheme()
+
+func init() {
+	for _, addToScheme := range []func(*k8sruntime.Scheme) error{
+		scheme.AddToScheme,
+		apis.AddToScheme,
+	} {
+		if err := addToScheme(pluginScheme); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// globalOptions are the options shared by every command
+type globalOptions struct {
+	namespace     string
+	allNamespaces bool
+}
+
+func main() {
+	o := &globalOptions{}
+	cmd := &cobra.Command{
+		Use:          "kubectl-faros",
+		Short:        "Inspect and control the GitTracks in a cluster",
+		SilenceUsage: true,
+	}
+	// Expose the kubeconfig flags registered by controller-runtime
+	cmd.PersistentFlags().AddGoFlagSet(goflag.CommandLine)
+	cmd.PersistentFlags().StringVarP(&o.namespace, "namespace", "n", "", "Namespace of the GitTracks, defaults to the namespace of the current context")
+	cmd.AddCommand(newStatusCommand(o))
+	cmd.AddCommand(newSyncCommand(o))
+	cmd.AddCommand(newSuspendCommand(o, true))
+	cmd.AddCommand(newSuspendCommand(o, false))
+	cmd.AddCommand(newTraceCommand(o))
+	cmd.AddCommand(&cobra.Command{
+		Use:   "version",
+		Short: "Show version and exit",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf("kubectl-faros %s (built with %s)\n", VERSION, runtime.Version())
+		},
+	})
+
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// client returns a client for the cluster in the kubeconfig and the namespace
+// the command operates in
+func (o *globalOptions) client() (client.Client, string, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to get kubeconfig: %v", err)
+	}
+	c, err := client.New(cfg, client.Options{Scheme: pluginScheme})
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to create client: %v", err)
+	}
+	if o.allNamespaces {
+		return c, "", nil
+	}
+	if o.namespace != "" {
+		return c, o.namespace, nil
+	}
+
+	// Default to the namespace of the current context, like kubectl
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if f := goflag.Lookup("kubeconfig"); f != nil {
+		rules.ExplicitPath = f.Value.String()
+	}
+	namespace, _, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{}).Namespace()
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to get namespace of the current context: %v", err)
+	}
+	return c, namespace, nil
+}
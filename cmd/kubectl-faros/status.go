@@ -0,0 +1,191 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	"github.com/pusher/faros/pkg/controller/gittrack"
+	gittrackobjectutils "github.com/pusher/faros/pkg/controller/gittrackobject/utils"
+	"github.com/pusher/faros/pkg/utils"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newStatusCommand(o *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status [GITTRACK]",
+		Short: "Show the GitTracks with their GitTrackObjects and children",
+		Long: `Show a tree of the GitTracks in the namespace, or of a single GitTrack, with
+the GitTrackObjects they own and the children those manage. Each
+GitTrackObject shows whether its child is in sync with Git and healthy.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 && o.allNamespaces {
+				return fmt.Errorf("a GitTrack can't be named with --all-namespaces")
+			}
+			c, namespace, err := o.client()
+			if err != nil {
+				return err
+			}
+			gts := []farosv1alpha1.GitTrack{}
+			if len(args) == 1 {
+				gt := &farosv1alpha1.GitTrack{}
+				key := types.NamespacedName{Namespace: namespace, Name: args[0]}
+				if err = c.Get(context.TODO(), key, gt); err != nil {
+					return fmt.Errorf("unable to get GitTrack %s: %v", key, err)
+				}
+				gts = append(gts, *gt)
+			} else {
+				list := &farosv1alpha1.GitTrackList{}
+				if err = c.List(context.TODO(), list, client.InNamespace(namespace)); err != nil {
+					return fmt.Errorf("unable to list GitTracks: %v", err)
+				}
+				gts = list.Items
+			}
+			if len(gts) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No GitTracks found")
+				return nil
+			}
+			for i := range gts {
+				if err = printStatus(cmd.OutOrStdout(), c, &gts[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&o.allNamespaces, "all-namespaces", "A", false, "Show the GitTracks in all namespaces")
+	return cmd
+}
+
+// printStatus prints the tree of the GitTrack, its GitTrackObjects and their
+// children
+func printStatus(out io.Writer, c client.Reader, gt *farosv1alpha1.GitTrack) error {
+	children, err := gittrack.ListChildren(c, gt)
+	if err != nil {
+		return fmt.Errorf("unable to list children of GitTrack %s/%s: %v", gt.Namespace, gt.Name, err)
+	}
+
+	details := []string{fmt.Sprintf("%s @ %s", gt.Spec.Reference, shortRevision(gt.Status.AppliedRevision))}
+	details = append(details, fmt.Sprintf("%d/%d in sync", gt.Status.ObjectsInSync, gt.Status.ObjectsApplied))
+	details = append(details, fmt.Sprintf("%d/%d healthy", gt.Status.ObjectsHealthy, gt.Status.ObjectsApplied))
+	if gt.Status.ObjectsIgnored > 0 {
+		details = append(details, fmt.Sprintf("%d ignored", gt.Status.ObjectsIgnored))
+	}
+	if gt.Spec.Suspend {
+		details = append(details, "suspended")
+	}
+	fmt.Fprintf(out, "GitTrack %s/%s (%s)\n", gt.Namespace, gt.Name, strings.Join(details, ", "))
+	for _, cond := range gt.Status.Conditions {
+		if cond.Status == v1.ConditionFalse {
+			fmt.Fprintf(out, "│ ! %s: %s\n", cond.Type, conditionDetails(cond.Reason, cond.Message))
+		}
+	}
+
+	names := []string{}
+	for name := range children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		branch, indent := "├─", "│  "
+		if i == len(names)-1 {
+			branch, indent = "└─", "   "
+		}
+		gto := children[name]
+		fmt.Fprintf(out, "%s %s %s: %s\n", branch, gitTrackObjectKind(gto), name, childState(gto.GetStatus()))
+		fmt.Fprintf(out, "%s└─ %s\n", indent, childDescription(gto))
+	}
+	return nil
+}
+
+// gitTrackObjectKind returns the kind of the GitTrackObject
+func gitTrackObjectKind(gto farosv1alpha1.GitTrackObjectInterface) string {
+	if _, ok := gto.(*farosv1alpha1.ClusterGitTrackObject); ok {
+		return "ClusterGitTrackObject"
+	}
+	return "GitTrackObject"
+}
+
+// childDescription returns the kind and name of the child of the
+// GitTrackObject
+func childDescription(gto farosv1alpha1.GitTrackObjectInterface) string {
+	child, err := utils.YAMLToUnstructured(gto.GetSpec().Data)
+	if err != nil {
+		return fmt.Sprintf("%s %s (unable to unmarshal data: %v)", gto.GetSpec().Kind, gto.GetSpec().Name, err)
+	}
+	return fmt.Sprintf("%s %s", child.GetKind(), strings.TrimLeft(child.GetNamespace()+"/"+child.GetName(), "/"))
+}
+
+// childState summarises the sync and health conditions of a GitTrackObject
+func childState(status farosv1alpha1.GitTrackObjectStatus) string {
+	states := []string{
+		conditionState(status, farosv1alpha1.ObjectInSyncType, "InSync", "OutOfSync"),
+		conditionState(status, farosv1alpha1.ObjectHealthyType, "Healthy", "Unhealthy"),
+	}
+	if cond := gittrackobjectutils.GetGitTrackObjectCondition(status, farosv1alpha1.ObjectDriftedType); cond != nil && cond.Status == v1.ConditionTrue {
+		states = append(states, "Drifted")
+	}
+	if cond := gittrackobjectutils.GetGitTrackObjectCondition(status, farosv1alpha1.ObjectFlappingType); cond != nil && cond.Status == v1.ConditionTrue {
+		states = append(states, "Flapping")
+	}
+	return strings.Join(states, ", ")
+}
+
+// conditionState describes the condition of the given type, including its
+// reason when it isn't true
+func conditionState(status farosv1alpha1.GitTrackObjectStatus, condType farosv1alpha1.GitTrackObjectConditionType, trueState, falseState string) string {
+	cond := gittrackobjectutils.GetGitTrackObjectCondition(status, condType)
+	switch {
+	case cond == nil:
+		return fmt.Sprintf("%s unknown", condType)
+	case cond.Status == v1.ConditionTrue:
+		return trueState
+	case cond.Status == v1.ConditionFalse:
+		return fmt.Sprintf("%s (%s)", falseState, conditionDetails(cond.Reason, cond.Message))
+	default:
+		return fmt.Sprintf("%s unknown (%s)", condType, conditionDetails(cond.Reason, cond.Message))
+	}
+}
+
+// conditionDetails joins the reason and message of a condition on a single
+// line
+func conditionDetails(reason, message string) string {
+	if message == "" {
+		return reason
+	}
+	return fmt.Sprintf("%s: %s", reason, strings.Replace(message, "\n", " ", -1))
+}
+
+// shortRevision abbreviates a commit SHA the way git does
+func shortRevision(revision string) string {
+	if revision == "" {
+		return "unknown revision"
+	}
+	if len(revision) > 7 {
+		return revision[:7]
+	}
+	return revision
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// newSuspendCommand returns the suspend command, or the resume command when
+// suspend is false
+func newSuspendCommand(o *globalOptions, suspend bool) *cobra.Command {
+	use, short, done := "resume", "Resume reconciling a suspended GitTrack", "Resumed"
+	long := `Resume reconciling a suspended GitTrack.
+
+Faros fetches the repository again and reverts any changes made to the
+children of the GitTrack while it was suspended.`
+	if suspend {
+		use, short, done = "suspend", "Stop reconciling a GitTrack until it is resumed", "Suspended"
+		long = `Stop reconciling a GitTrack until it is resumed.
+
+Faros stops fetching the repository and stops managing the GitTrackObjects and
+ClusterGitTrackObjects of the GitTrack, which stop managing their children in
+turn. Changes made to the children while the GitTrack is suspended are not
+reverted until it is resumed.`
+	}
+	return &cobra.Command{
+		Use:   use + " GITTRACK",
+		Short: short,
+		Long:  long,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, namespace, err := o.client()
+			if err != nil {
+				return err
+			}
+			key := types.NamespacedName{Namespace: namespace, Name: args[0]}
+			err = updateGitTrack(c, key, func(gt *farosv1alpha1.GitTrack) error {
+				gt.Spec.Suspend = suspend
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s GitTrack %s\n", done, key)
+			return nil
+		},
+	}
+}
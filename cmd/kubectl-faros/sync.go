@@ -0,0 +1,109 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	"github.com/pusher/faros/pkg/utils"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+	k8swait "k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newSyncCommand(o *globalOptions) *cobra.Command {
+	var wait bool
+	var timeout time.Duration
+	cmd := &cobra.Command{
+		Use:   "sync GITTRACK",
+		Short: "Reconcile a GitTrack immediately",
+		Long: `Request an immediate reconcile of a GitTrack by setting its
+faros.pusher.com/reconcile-requested-at annotation to the current time. The
+repository is fetched and every GitTrackObject is reconciled. With --wait, the
+command returns once the GitTrack reports the request as handled, which it only
+does once a reconcile completes without errors. If the request isn't handled
+in time, the status of the GitTrack is shown to explain why.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, namespace, err := o.client()
+			if err != nil {
+				return err
+			}
+			key := types.NamespacedName{Namespace: namespace, Name: args[0]}
+			requestedAt := time.Now().UTC().Format(time.RFC3339Nano)
+			err = updateGitTrack(c, key, func(gt *farosv1alpha1.GitTrack) error {
+				if gt.Spec.Suspend {
+					return fmt.Errorf("GitTrack %s is suspended, resume it to sync it", key)
+				}
+				annotations := gt.GetAnnotations()
+				if annotations == nil {
+					annotations = make(map[string]string)
+				}
+				annotations[utils.ReconcileRequestedAnnotation] = requestedAt
+				gt.SetAnnotations(annotations)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Requested reconcile of GitTrack %s at %s\n", key, requestedAt)
+			if !wait {
+				return nil
+			}
+
+			gt := &farosv1alpha1.GitTrack{}
+			err = k8swait.PollImmediate(time.Second, timeout, func() (bool, error) {
+				if err := c.Get(context.TODO(), key, gt); err != nil {
+					return false, fmt.Errorf("unable to get GitTrack %s: %v", key, err)
+				}
+				return gt.Status.LastHandledReconcileAt == requestedAt, nil
+			})
+			if err == k8swait.ErrWaitTimeout {
+				if err := printStatus(cmd.OutOrStdout(), c, gt); err != nil {
+					return err
+				}
+				return fmt.Errorf("reconcile of GitTrack %s not completed without errors within %s", key, timeout)
+			} else if err != nil {
+				return fmt.Errorf("reconcile of GitTrack %s not handled: %v", key, err)
+			}
+			return printStatus(cmd.OutOrStdout(), c, gt)
+		},
+	}
+	cmd.Flags().BoolVar(&wait, "wait", false, "Wait for the reconcile to be handled and show the status of the GitTrack")
+	cmd.Flags().DurationVar(&timeout, "timeout", 2*time.Minute, "How long to wait for the reconcile to be handled")
+	return cmd
+}
+
+// updateGitTrack applies the mutation to the latest version of the GitTrack,
+// retrying on conflicts
+func updateGitTrack(c client.Client, key types.NamespacedName, mutate func(*farosv1alpha1.GitTrack) error) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		gt := &farosv1alpha1.GitTrack{}
+		if err := c.Get(context.TODO(), key, gt); err != nil {
+			return fmt.Errorf("unable to get GitTrack %s: %v", key, err)
+		}
+		if err := mutate(gt); err != nil {
+			return err
+		}
+		return c.Update(context.TODO(), gt)
+	})
+}
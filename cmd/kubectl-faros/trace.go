@@ -0,0 +1,167 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	gittrackobjectutils "github.com/pusher/faros/pkg/controller/gittrackobject/utils"
+	"github.com/pusher/faros/pkg/utils"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+func newTraceCommand(o *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "trace KIND/NAME",
+		Short: "Show which GitTrack produced a live object",
+		Long: `Show the GitTrackObject managing a live object and the GitTrack, repository
+and revision it was produced from. KIND is a resource as accepted by kubectl,
+eg. deployment, deployments.apps or deployments.v1.apps.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			split := strings.SplitN(args[0], "/", 2)
+			if len(split) != 2 || split[0] == "" || split[1] == "" {
+				return fmt.Errorf("%s is invalid, should be of format <kind>/<name>", args[0])
+			}
+			c, namespace, err := o.client()
+			if err != nil {
+				return err
+			}
+			obj, err := getLiveObject(c, split[0], namespace, split[1])
+			if err != nil {
+				return err
+			}
+			return printTrace(cmd.OutOrStdout(), c, obj)
+		},
+	}
+}
+
+// getLiveObject gets the object of the kind named by the resource from the
+// cluster
+func getLiveObject(c client.Client, resource, namespace, name string) (*unstructured.Unstructured, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get kubeconfig: %v", err)
+	}
+	restMapper, err := utils.NewRestMapper(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	gvr, gr := schema.ParseResourceArg(strings.ToLower(resource))
+	var gvk schema.GroupVersionKind
+	if gvr != nil {
+		gvk, err = restMapper.KindFor(*gvr)
+	}
+	if gvr == nil || err != nil {
+		gvk, err = restMapper.KindFor(gr.WithVersion(""))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to find kind of resource %s: %v", resource, err)
+	}
+	mapping, err := restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("unable to map kind %s: %v", gvk.Kind, err)
+	}
+
+	key := types.NamespacedName{Name: name}
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		key.Namespace = namespace
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if err = c.Get(context.TODO(), key, obj); err != nil {
+		return nil, fmt.Errorf("unable to get %s %s: %v", gvk.Kind, strings.TrimLeft(key.String(), "/"), err)
+	}
+	return obj, nil
+}
+
+// printTrace prints the GitTrackObject and GitTrack the object was produced
+// by
+func printTrace(out io.Writer, c client.Reader, obj *unstructured.Unstructured) error {
+	desc := fmt.Sprintf("%s %s", obj.GetKind(), strings.TrimLeft(obj.GetNamespace()+"/"+obj.GetName(), "/"))
+	fmt.Fprintln(out, desc)
+
+	ref := metav1.GetControllerOf(obj)
+	if ref == nil || !strings.HasPrefix(ref.APIVersion, farosv1alpha1.SchemeGroupVersion.Group+"/") {
+		if owner := gittrackobjectutils.GetRemoteOwner(obj); owner != "" {
+			fmt.Fprintf(out, "└─ managed from another cluster by %s\n", owner)
+			return nil
+		}
+		return fmt.Errorf("%s is not managed by Faros", desc)
+	}
+
+	var gto farosv1alpha1.GitTrackObjectInterface
+	switch ref.Kind {
+	case "GitTrackObject":
+		gto = &farosv1alpha1.GitTrackObject{}
+	case "ClusterGitTrackObject":
+		gto = &farosv1alpha1.ClusterGitTrackObject{}
+	default:
+		return fmt.Errorf("%s is controlled by unknown kind %s", desc, ref.Kind)
+	}
+	key := types.NamespacedName{Namespace: obj.GetNamespace(), Name: ref.Name}
+	if ref.Kind == "ClusterGitTrackObject" {
+		key.Namespace = ""
+	}
+	if err := c.Get(context.TODO(), key, gto); err != nil {
+		return fmt.Errorf("unable to get %s %s: %v", ref.Kind, ref.Name, err)
+	}
+	fmt.Fprintf(out, "└─ %s %s: %s\n", ref.Kind, gto.GetNamespacedName(), childState(gto.GetStatus()))
+
+	gt, err := gittrackobjectutils.GetOwner(c, gto)
+	if err != nil {
+		return err
+	}
+	if gt == nil {
+		fmt.Fprintln(out, "   └─ not owned by a GitTrack")
+		return nil
+	}
+	fmt.Fprintf(out, "   └─ GitTrack %s/%s\n", gt.Namespace, gt.Name)
+	fmt.Fprintf(out, "      repository: %s\n", gt.Spec.Repository)
+	fmt.Fprintf(out, "      reference:  %s\n", gt.Spec.Reference)
+	if gt.Spec.SubPath != "" {
+		fmt.Fprintf(out, "      sub path:   %s\n", gt.Spec.SubPath)
+	}
+	annotations := obj.GetAnnotations()
+	if file := utils.SourceFile(obj); file != "" {
+		fmt.Fprintf(out, "      file:       %s\n", file)
+	}
+	if commit := annotations[utils.SourceCommitAnnotation]; commit != "" {
+		fmt.Fprintf(out, "      commit:     %s\n", commit)
+	}
+	revision := gt.Status.AppliedRevision
+	if revision == "" {
+		revision = "unknown"
+	}
+	fmt.Fprintf(out, "      revision:   %s\n", revision)
+	if gt.Status.RolledBackRevision != "" {
+		fmt.Fprintf(out, "      rolled back from %s\n", gt.Status.RolledBackRevision)
+	}
+	return nil
+}
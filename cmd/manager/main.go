@@ -28,6 +28,7 @@ import (
 	"github.com/pusher/faros/pkg/controller"
 	farosflags "github.com/pusher/faros/pkg/flags"
 	"github.com/pusher/faros/pkg/utils"
+	"github.com/pusher/faros/pkg/webhook"
 	flag "github.com/spf13/pflag"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/klog"
@@ -45,6 +46,17 @@ var (
 	metricsBindAddress       = flag.String("metrics-bind-address", ":8080", "Specify which address to bind to for serving prometheus metrics")
 	syncPeriod               = flag.Duration("sync-period", 5*time.Minute, "Reconcile sync period")
 	showVersion              = flag.Bool("version", false, "Show version and exit")
+
+	enableWebhooks              = flag.Bool("enable-webhooks", false, "Serve the admission webhooks validating and defaulting Faros resources")
+	webhookHost                 = flag.String("webhook-host", "", "Address the webhook server listens on, defaults to all addresses")
+	webhookPort                 = flag.Int("webhook-port", 9443, "Port the webhook server listens on")
+	webhookCertDir              = flag.String("webhook-cert-dir", "/tmp/k8s-webhook-server/serving-certs", "Directory containing tls.crt and tls.key for the webhook server, a self-signed certificate is generated if they don't exist")
+	webhookServiceName          = flag.String("webhook-service-name", "faros-webhook-service", "Name of the Service the API server reaches the webhook server through")
+	webhookServiceNamespace     = flag.String("webhook-service-namespace", "faros-system", "Namespace of the Service the API server reaches the webhook server through")
+	mutatingWebhookConfigName   = flag.String("mutating-webhook-configuration", "faros-mutating-webhook-configuration", "Name of the MutatingWebhookConfiguration to inject the CA bundle into")
+	validatingWebhookConfigName = flag.String("validating-webhook-configuration", "faros-validating-webhook-configuration", "Name of the ValidatingWebhookConfiguration to inject the CA bundle into")
+	enableChildLock             = flag.Bool("enable-child-lock", false, "Serve the admission webhook rejecting changes to children not made by Faros or a break-glass group, requires --enable-webhooks")
+	childLockWebhookConfigName  = flag.String("child-lock-webhook-configuration", "faros-child-lock-webhook-configuration", "Name of the ValidatingWebhookConfiguration of the child lock to inject the CA bundle into")
 )
 
 func main() {
@@ -115,6 +127,25 @@ func main() {
 		panic(err)
 	}
 
+	// Setup the admission webhooks
+	if *enableWebhooks {
+		err = webhook.AddToManager(mgr, webhook.Options{
+			Host:                        *webhookHost,
+			Port:                        *webhookPort,
+			CertDir:                     *webhookCertDir,
+			ServiceName:                 *webhookServiceName,
+			ServiceNamespace:            *webhookServiceNamespace,
+			MutatingWebhookConfigName:   *mutatingWebhookConfigName,
+			ValidatingWebhookConfigName: *validatingWebhookConfigName,
+			EnableChildLock:             *enableChildLock,
+			ChildLockWebhookConfigName:  *childLockWebhookConfigName,
+		})
+		if err != nil {
+			log.Error(err, "couldn't register webhooks")
+			panic(err)
+		}
+	}
+
 	log.V(0).Info("Starting controllers...")
 
 	// Start the Cmd
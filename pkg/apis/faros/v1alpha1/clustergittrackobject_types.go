@@ -27,6 +27,7 @@ import (
 // ClusterGitTrackObject is the Schema for the clustergittrackobjects API
 // +k8s:openapi-gen=true
 // +kubebuilder:printcolumn:name="In Sync",type="string",JSONPath=".status.conditions[?(@.type=="ObjectInSync")].status"
+// +kubebuilder:printcolumn:name="Healthy",type="string",JSONPath=".status.conditions[?(@.type=="ObjectHealthy")].status"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 type ClusterGitTrackObject struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -31,6 +31,44 @@ const (
 	GitCredentialTypeHTTPBasicAuth = "HTTPBasicAuth"
 )
 
+// GitTrackDeletionPolicy defines what happens to the children of a GitTrack
+// when the GitTrack is deleted
+type GitTrackDeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the children of a GitTrack along with it
+	DeletionPolicyDelete GitTrackDeletionPolicy = "Delete"
+	// DeletionPolicyOrphan leaves the children of a GitTrack in place when it
+	// is deleted
+	DeletionPolicyOrphan GitTrackDeletionPolicy = "Orphan"
+)
+
+// GitTrackAdoptionPolicy defines whether Faros takes over resources that
+// already exist in the cluster
+type GitTrackAdoptionPolicy string
+
+const (
+	// AdoptionAlways takes over existing resources regardless of their owner
+	AdoptionAlways GitTrackAdoptionPolicy = "Always"
+	// AdoptionIfUnowned takes over existing resources that are not controlled
+	// by another owner
+	AdoptionIfUnowned GitTrackAdoptionPolicy = "IfUnowned"
+	// AdoptionNever never takes over existing resources
+	AdoptionNever GitTrackAdoptionPolicy = "Never"
+)
+
+// GitTrackDriftPolicy defines what happens when a child is modified outside of
+// Git
+type GitTrackDriftPolicy string
+
+const (
+	// DriftPolicyRevert reverts changes made to children outside of Git
+	DriftPolicyRevert GitTrackDriftPolicy = "Revert"
+	// DriftPolicyReport reports changes made to children outside of Git
+	// without reverting them
+	DriftPolicyReport GitTrackDriftPolicy = "Report"
+)
+
 // GitTrackSpec defines the desired state of GitTrack
 type GitTrackSpec struct {
 	// Reference contains the git reference this GitTrack tracks
@@ -45,6 +83,90 @@ type GitTrackSpec struct {
 
 	// DeployKey holds a reference to an SSH key needed to access the repository
 	DeployKey GitTrackDeployKey `json:"deployKey,omitempty"`
+
+	// Cluster holds a reference to a kubeconfig for the cluster the children
+	// are deployed to. Defaults to the cluster Faros is running in.
+	Cluster *GitTrackCluster `json:"cluster,omitempty"`
+
+	// ServiceAccountName is the name of a ServiceAccount in the namespace of
+	// the GitTrack that Faros impersonates when creating, updating and deleting
+	// children. Defaults to Faros' own identity.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// Adoption determines whether resources that already exist in the cluster
+	// are taken over by Faros. Accepted values are "Always", "IfUnowned",
+	// "Never". Defaults to "Always".
+	// +kubebuilder:validation:Enum=Always,IfUnowned,Never
+	Adoption GitTrackAdoptionPolicy `json:"adoption,omitempty"`
+
+	// DeletionPolicy determines what happens to the children when the GitTrack
+	// is deleted. Accepted values are "Delete", "Orphan". Defaults to "Delete".
+	// +kubebuilder:validation:Enum=Delete,Orphan
+	DeletionPolicy GitTrackDeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// DriftPolicy determines what happens when a child is modified outside of
+	// Git. Accepted values are "Revert", "Report". Defaults to "Revert".
+	// +kubebuilder:validation:Enum=Revert,Report
+	DriftPolicy GitTrackDriftPolicy `json:"driftPolicy,omitempty"`
+
+	// IgnoreFields lists fields of children that Faros never updates, such as
+	// fields managed by other controllers
+	IgnoreFields []GitTrackIgnoreRule `json:"ignoreFields,omitempty"`
+
+	// Prune determines whether children removed from the repository are
+	// deleted. Defaults to true.
+	Prune *bool `json:"prune,omitempty"`
+
+	// PruneThreshold limits the number of children that may be pruned at once.
+	// Overrides the thresholds configured on the controller.
+	PruneThreshold *GitTrackPruneThreshold `json:"pruneThreshold,omitempty"`
+
+	// Rollback enables automatic rollback to the last revision under which
+	// every child was healthy
+	Rollback *GitTrackRollback `json:"rollback,omitempty"`
+
+	// Suspend stops Faros from fetching the repository and applying its
+	// files to the children until it is unset. Children that already exist
+	// are left in place and changes made to them aren't reverted.
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// GitTrackIgnoreRule lists fields that Faros never updates on children of a
+// kind
+type GitTrackIgnoreRule struct {
+	// Group is the API group of the children. Empty for the core API group.
+	Group string `json:"group,omitempty"`
+
+	// Kind is the kind of the children
+	Kind string `json:"kind"`
+
+	// Fields are the paths of the fields to ignore, either as JSON pointers
+	// (eg. /spec/replicas) or JSONPaths (eg. .spec.replicas)
+	Fields []string `json:"fields"`
+}
+
+// GitTrackPruneThreshold configures the thresholds above which children are not
+// pruned until the deletion is acknowledged
+type GitTrackPruneThreshold struct {
+	// Count is the maximum number of children that may be pruned at once.
+	// A value of 0 disables the threshold.
+	// +kubebuilder:validation:Minimum=0
+	Count *int64 `json:"count,omitempty"`
+
+	// Percent is the maximum percentage of the currently owned children that
+	// may be pruned at once. A value of 0 disables the threshold.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Percent *int64 `json:"percent,omitempty"`
+}
+
+// GitTrackRollback configures automatic rollback of a GitTrack
+type GitTrackRollback struct {
+	// ProgressDeadlineSeconds is the number of seconds children may remain
+	// unhealthy after a new revision is applied before it is rolled back.
+	// Defaults to 600 seconds.
+	// +kubebuilder:validation:Minimum=1
+	ProgressDeadlineSeconds *int64 `json:"progressDeadlineSeconds,omitempty"`
 }
 
 // GitTrackDeployKey holds a reference to a secret such as an SSH key or HTTP Basic Auth credentials needed to access the repository
@@ -60,6 +182,17 @@ type GitTrackDeployKey struct {
 	Type GitCredentialType `json:"type,omitempty"`
 }
 
+// GitTrackCluster holds a reference to a secret containing the kubeconfig of a
+// remote cluster
+type GitTrackCluster struct {
+	// SecretName is the name of the Secret object containing the kubeconfig
+	SecretName string `json:"secretName"`
+
+	// Key is the key within the Secret object that contains the kubeconfig.
+	// Defaults to "kubeconfig".
+	Key string `json:"key,omitempty"`
+}
+
 // GitTrackStatus defines the observed state of GitTrack
 type GitTrackStatus struct {
 	// ObjectsDiscovered is the number of k8s objects found in the repository path
@@ -74,9 +207,38 @@ type GitTrackStatus struct {
 	// ObjectsInSync is the number of GitTrackObjects that were successfully applied to the cluster
 	ObjectsInSync int64 `json:"objectsInSync"`
 
+	// ObjectsHealthy is the number of GitTrackObjects whose child has reached its desired state
+	ObjectsHealthy int64 `json:"objectsHealthy"`
+
+	// Revision is the commit SHA the Reference resolved to when it was last fetched
+	Revision string `json:"revision,omitempty"`
+
+	// AppliedRevision is the commit SHA whose files were last applied to the children
+	AppliedRevision string `json:"appliedRevision,omitempty"`
+
+	// AppliedTime is the time at which AppliedRevision was first applied
+	AppliedTime *metav1.Time `json:"appliedTime,omitempty"`
+
+	// LastHealthyRevision is the last commit SHA under which every child was healthy
+	LastHealthyRevision string `json:"lastHealthyRevision,omitempty"`
+
+	// RolledBackRevision is the commit SHA that was rolled back because its
+	// children did not become healthy. Faros keeps applying LastHealthyRevision
+	// until the Reference resolves to a newer commit.
+	RolledBackRevision string `json:"rolledBackRevision,omitempty"`
+
 	// IgnoredFiles is the list of YAML files containing invalid k8s manifests.
 	IgnoredFiles map[string]string `json:"ignoredFiles,omitempty"`
 
+	// KeptObjects is the list of children that were removed from the repository
+	// but were not deleted, and the reason they were kept
+	KeptObjects map[string]string `json:"keptObjects,omitempty"`
+
+	// LastHandledReconcileAt is the value of the
+	// faros.pusher.com/reconcile-requested-at annotation when a requested
+	// reconcile was last completed
+	LastHandledReconcileAt string `json:"lastHandledReconcileAt,omitempty"`
+
 	// Conditions are the conditions on this GitTrack
 	Conditions []GitTrackCondition `json:"conditions,omitempty"`
 }
@@ -99,6 +261,10 @@ const (
 	// ChildrenGarbageCollectedType referes to whether all children that were meant to
 	// be GC'd have been GC'
 	ChildrenGarbageCollectedType GitTrackConditionType = "ChildrenGarbageCollected"
+
+	// RolledBackType refers to whether the GitTrack has been rolled back to
+	// the last revision under which every child was healthy
+	RolledBackType GitTrackConditionType = "RolledBack"
 )
 
 // GitTrackCondition is a status condition for a GitTrack
@@ -133,6 +299,7 @@ type GitTrackCondition struct {
 // +kubebuilder:printcolumn:name="Resources Discovered",type="integer",JSONPath=".status.objectsDiscovered"
 // +kubebuilder:printcolumn:name="Resources Ignored",type="integer",JSONPath=".status.objectsIgnored"
 // +kubebuilder:printcolumn:name="Children In Sync",type="integer",JSONPath=".status.objectsInSync"
+// +kubebuilder:printcolumn:name="Children Healthy",type="integer",JSONPath=".status.objectsHealthy"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 type GitTrack struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -39,6 +39,28 @@ type GitTrackObjectSpec struct {
 type GitTrackObjectStatus struct {
 	// Conditions of this object
 	Conditions []GitTrackObjectCondition `json:"conditions,omitempty"`
+
+	// LastAppliedPatch is the patch Faros last applied to update the child.
+	// It is truncated and the values of Secrets are redacted.
+	LastAppliedPatch string `json:"lastAppliedPatch,omitempty"`
+
+	// DriftPatch is the patch that would revert the changes made to the child
+	// outside of Git, when they are reported rather than reverted. It is
+	// truncated and the values of Secrets are redacted.
+	DriftPatch string `json:"driftPatch,omitempty"`
+
+	// LastHandledReconcileAt is the value of the
+	// faros.pusher.com/reconcile-requested-at annotation when a requested
+	// reconcile was last completed
+	LastHandledReconcileAt string `json:"lastHandledReconcileAt,omitempty"`
+
+	// ObservedDataHash is a hash of the data of the tracked object that the
+	// conditions were last updated for
+	ObservedDataHash string `json:"observedDataHash,omitempty"`
+
+	// AppliedDataHash is a hash of the data of the tracked object that was
+	// last applied to the child
+	AppliedDataHash string `json:"appliedDataHash,omitempty"`
 }
 
 // GitTrackObjectConditionType is the type of a GitTrackObjectCondition
@@ -47,6 +69,18 @@ type GitTrackObjectConditionType string
 const (
 	// ObjectInSyncType whether the tracked object is in sync or not
 	ObjectInSyncType GitTrackObjectConditionType = "ObjectInSync"
+
+	// ObjectHealthyType whether the tracked object has reached its desired
+	// state according to its live status
+	ObjectHealthyType GitTrackObjectConditionType = "ObjectHealthy"
+
+	// ObjectDriftedType whether the tracked object has been modified outside
+	// of Git and differs from its desired state
+	ObjectDriftedType GitTrackObjectConditionType = "ObjectDrifted"
+
+	// ObjectFlappingType whether the tracked object is repeatedly being changed
+	// by another controller and reverted by Faros
+	ObjectFlappingType GitTrackObjectConditionType = "ObjectFlapping"
 )
 
 // GitTrackObjectCondition is a status condition for a GitTrackObject
@@ -76,6 +110,7 @@ type GitTrackObjectCondition struct {
 // GitTrackObject is the Schema for the gittrackobjects API
 // +k8s:openapi-gen=true
 // +kubebuilder:printcolumn:name="In Sync",type="string",JSONPath=".status.conditions[?(@.type=="ObjectInSync")].status"
+// +kubebuilder:printcolumn:name="Healthy",type="string",JSONPath=".status.conditions[?(@.type=="ObjectHealthy")].status"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 type GitTrackObject struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -90,7 +90,7 @@ func (in *GitTrack) DeepCopyInto(out *GitTrack) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
@@ -131,6 +131,22 @@ func (in *GitTrackCondition) DeepCopy() *GitTrackCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitTrackCluster) DeepCopyInto(out *GitTrackCluster) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitTrackCluster.
+func (in *GitTrackCluster) DeepCopy() *GitTrackCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(GitTrackCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitTrackDeployKey) DeepCopyInto(out *GitTrackDeployKey) {
 	*out = *in
@@ -147,6 +163,27 @@ func (in *GitTrackDeployKey) DeepCopy() *GitTrackDeployKey {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitTrackIgnoreRule) DeepCopyInto(out *GitTrackIgnoreRule) {
+	*out = *in
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitTrackIgnoreRule.
+func (in *GitTrackIgnoreRule) DeepCopy() *GitTrackIgnoreRule {
+	if in == nil {
+		return nil
+	}
+	out := new(GitTrackIgnoreRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitTrackList) DeepCopyInto(out *GitTrackList) {
 	*out = *in
@@ -303,10 +340,84 @@ func (in *GitTrackObjectStatus) DeepCopy() *GitTrackObjectStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitTrackPruneThreshold) DeepCopyInto(out *GitTrackPruneThreshold) {
+	*out = *in
+	if in.Count != nil {
+		in, out := &in.Count, &out.Count
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Percent != nil {
+		in, out := &in.Percent, &out.Percent
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitTrackPruneThreshold.
+func (in *GitTrackPruneThreshold) DeepCopy() *GitTrackPruneThreshold {
+	if in == nil {
+		return nil
+	}
+	out := new(GitTrackPruneThreshold)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitTrackRollback) DeepCopyInto(out *GitTrackRollback) {
+	*out = *in
+	if in.ProgressDeadlineSeconds != nil {
+		in, out := &in.ProgressDeadlineSeconds, &out.ProgressDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitTrackRollback.
+func (in *GitTrackRollback) DeepCopy() *GitTrackRollback {
+	if in == nil {
+		return nil
+	}
+	out := new(GitTrackRollback)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitTrackSpec) DeepCopyInto(out *GitTrackSpec) {
 	*out = *in
 	out.DeployKey = in.DeployKey
+	if in.Cluster != nil {
+		in, out := &in.Cluster, &out.Cluster
+		*out = new(GitTrackCluster)
+		**out = **in
+	}
+	if in.IgnoreFields != nil {
+		in, out := &in.IgnoreFields, &out.IgnoreFields
+		*out = make([]GitTrackIgnoreRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Prune != nil {
+		in, out := &in.Prune, &out.Prune
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PruneThreshold != nil {
+		in, out := &in.PruneThreshold, &out.PruneThreshold
+		*out = new(GitTrackPruneThreshold)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollback != nil {
+		in, out := &in.Rollback, &out.Rollback
+		*out = new(GitTrackRollback)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -323,6 +434,10 @@ func (in *GitTrackSpec) DeepCopy() *GitTrackSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitTrackStatus) DeepCopyInto(out *GitTrackStatus) {
 	*out = *in
+	if in.AppliedTime != nil {
+		in, out := &in.AppliedTime, &out.AppliedTime
+		*out = (*in).DeepCopy()
+	}
 	if in.IgnoredFiles != nil {
 		in, out := &in.IgnoredFiles, &out.IgnoredFiles
 		*out = make(map[string]string, len(*in))
@@ -330,6 +445,13 @@ func (in *GitTrackStatus) DeepCopyInto(out *GitTrackStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.KeptObjects != nil {
+		in, out := &in.KeptObjects, &out.KeptObjects
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]GitTrackCondition, len(*in))
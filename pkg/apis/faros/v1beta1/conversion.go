@@ -0,0 +1,287 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+
+	"github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// ConvertTo converts the GitTrack to the v1alpha1 GitTrack dst. v1alpha1 is the
+// storage version that other versions are converted through.
+func (g *GitTrack) ConvertTo(dst runtime.Object) error {
+	hub, ok := dst.(*v1alpha1.GitTrack)
+	if !ok {
+		return fmt.Errorf("unable to convert GitTrack to %T", dst)
+	}
+	in := g.DeepCopy()
+	hub.ObjectMeta = in.ObjectMeta
+
+	hub.Spec = v1alpha1.GitTrackSpec{
+		Repository:         in.Spec.Source.Repository,
+		Reference:          in.Spec.Source.Reference,
+		SubPath:            in.Spec.Source.SubPath,
+		Cluster:            (*v1alpha1.GitTrackCluster)(in.Spec.Cluster),
+		ServiceAccountName: in.Spec.ServiceAccountName,
+		Adoption:           v1alpha1.GitTrackAdoptionPolicy(in.Spec.Adoption),
+		DeletionPolicy:     v1alpha1.GitTrackDeletionPolicy(in.Spec.DeletionPolicy),
+		DriftPolicy:        v1alpha1.GitTrackDriftPolicy(in.Spec.DriftPolicy),
+		Prune:              in.Spec.Prune,
+		PruneThreshold:     (*v1alpha1.GitTrackPruneThreshold)(in.Spec.PruneThreshold),
+		Rollback:           (*v1alpha1.GitTrackRollback)(in.Spec.Rollback),
+		Suspend:            in.Spec.Suspend,
+	}
+	if creds := in.Spec.Source.Credentials; creds != nil {
+		hub.Spec.DeployKey = v1alpha1.GitTrackDeployKey{
+			SecretName: creds.SecretName,
+			Key:        creds.Key,
+			Type:       v1alpha1.GitCredentialType(creds.Type),
+		}
+	}
+	for _, rule := range in.Spec.IgnoreFields {
+		hub.Spec.IgnoreFields = append(hub.Spec.IgnoreFields, v1alpha1.GitTrackIgnoreRule(rule))
+	}
+
+	hub.Status = v1alpha1.GitTrackStatus{
+		ObjectsDiscovered:      in.Status.ObjectsDiscovered,
+		ObjectsApplied:         in.Status.ObjectsApplied,
+		ObjectsIgnored:         in.Status.ObjectsIgnored,
+		ObjectsInSync:          in.Status.ObjectsInSync,
+		ObjectsHealthy:         in.Status.ObjectsHealthy,
+		Revision:               in.Status.Revision,
+		AppliedRevision:        in.Status.AppliedRevision,
+		AppliedTime:            in.Status.AppliedTime,
+		LastHealthyRevision:    in.Status.LastHealthyRevision,
+		RolledBackRevision:     in.Status.RolledBackRevision,
+		IgnoredFiles:           in.Status.IgnoredFiles,
+		KeptObjects:            in.Status.KeptObjects,
+		LastHandledReconcileAt: in.Status.LastHandledReconcileAt,
+	}
+	for _, c := range in.Status.Conditions {
+		hub.Status.Conditions = append(hub.Status.Conditions, v1alpha1.GitTrackCondition{
+			Type:               v1alpha1.GitTrackConditionType(c.Type),
+			Status:             c.Status,
+			LastUpdateTime:     c.LastUpdateTime,
+			LastTransitionTime: c.LastTransitionTime,
+			Reason:             c.Reason,
+			Message:            c.Message,
+		})
+	}
+	return nil
+}
+
+// ConvertFrom converts the v1alpha1 GitTrack src to this version
+func (g *GitTrack) ConvertFrom(src runtime.Object) error {
+	hub, ok := src.(*v1alpha1.GitTrack)
+	if !ok {
+		return fmt.Errorf("unable to convert %T to GitTrack", src)
+	}
+	in := hub.DeepCopy()
+	g.ObjectMeta = in.ObjectMeta
+
+	g.Spec = GitTrackSpec{
+		Source: GitTrackSource{
+			Repository: in.Spec.Repository,
+			Reference:  in.Spec.Reference,
+			SubPath:    in.Spec.SubPath,
+		},
+		Cluster:            (*GitTrackCluster)(in.Spec.Cluster),
+		ServiceAccountName: in.Spec.ServiceAccountName,
+		Adoption:           GitTrackAdoptionPolicy(in.Spec.Adoption),
+		DeletionPolicy:     GitTrackDeletionPolicy(in.Spec.DeletionPolicy),
+		DriftPolicy:        GitTrackDriftPolicy(in.Spec.DriftPolicy),
+		Prune:              in.Spec.Prune,
+		PruneThreshold:     (*GitTrackPruneThreshold)(in.Spec.PruneThreshold),
+		Rollback:           (*GitTrackRollback)(in.Spec.Rollback),
+		Suspend:            in.Spec.Suspend,
+	}
+	if key := in.Spec.DeployKey; key != (v1alpha1.GitTrackDeployKey{}) {
+		g.Spec.Source.Credentials = &GitTrackCredentials{
+			Type:       GitCredentialType(key.Type),
+			SecretName: key.SecretName,
+			Key:        key.Key,
+		}
+	}
+	for _, rule := range in.Spec.IgnoreFields {
+		g.Spec.IgnoreFields = append(g.Spec.IgnoreFields, GitTrackIgnoreRule(rule))
+	}
+
+	g.Status = GitTrackStatus{
+		ObjectsDiscovered:      in.Status.ObjectsDiscovered,
+		ObjectsApplied:         in.Status.ObjectsApplied,
+		ObjectsIgnored:         in.Status.ObjectsIgnored,
+		ObjectsInSync:          in.Status.ObjectsInSync,
+		ObjectsHealthy:         in.Status.ObjectsHealthy,
+		Revision:               in.Status.Revision,
+		AppliedRevision:        in.Status.AppliedRevision,
+		AppliedTime:            in.Status.AppliedTime,
+		LastHealthyRevision:    in.Status.LastHealthyRevision,
+		RolledBackRevision:     in.Status.RolledBackRevision,
+		IgnoredFiles:           in.Status.IgnoredFiles,
+		KeptObjects:            in.Status.KeptObjects,
+		LastHandledReconcileAt: in.Status.LastHandledReconcileAt,
+	}
+	for _, c := range in.Status.Conditions {
+		g.Status.Conditions = append(g.Status.Conditions, GitTrackCondition{
+			Type:               GitTrackConditionType(c.Type),
+			Status:             c.Status,
+			LastUpdateTime:     c.LastUpdateTime,
+			LastTransitionTime: c.LastTransitionTime,
+			Reason:             c.Reason,
+			Message:            c.Message,
+		})
+	}
+	return nil
+}
+
+// ConvertTo converts the GitTrackObject to the v1alpha1 GitTrackObject dst
+func (g *GitTrackObject) ConvertTo(dst runtime.Object) error {
+	hub, ok := dst.(*v1alpha1.GitTrackObject)
+	if !ok {
+		return fmt.Errorf("unable to convert GitTrackObject to %T", dst)
+	}
+	in := g.DeepCopy()
+	hub.ObjectMeta = in.ObjectMeta
+	hub.Spec = convertSpecTo(in.Spec)
+	hub.Status = convertStatusTo(in.Status)
+	return nil
+}
+
+// ConvertFrom converts the v1alpha1 GitTrackObject src to this version
+func (g *GitTrackObject) ConvertFrom(src runtime.Object) error {
+	hub, ok := src.(*v1alpha1.GitTrackObject)
+	if !ok {
+		return fmt.Errorf("unable to convert %T to GitTrackObject", src)
+	}
+	in := hub.DeepCopy()
+	spec, err := convertSpecFrom(in.Spec)
+	if err != nil {
+		return err
+	}
+	g.ObjectMeta = in.ObjectMeta
+	g.Spec = spec
+	g.Status = convertStatusFrom(in.Status)
+	return nil
+}
+
+// ConvertTo converts the ClusterGitTrackObject to the v1alpha1
+// ClusterGitTrackObject dst
+func (g *ClusterGitTrackObject) ConvertTo(dst runtime.Object) error {
+	hub, ok := dst.(*v1alpha1.ClusterGitTrackObject)
+	if !ok {
+		return fmt.Errorf("unable to convert ClusterGitTrackObject to %T", dst)
+	}
+	in := g.DeepCopy()
+	hub.ObjectMeta = in.ObjectMeta
+	hub.Spec = convertSpecTo(in.Spec)
+	hub.Status = convertStatusTo(in.Status)
+	return nil
+}
+
+// ConvertFrom converts the v1alpha1 ClusterGitTrackObject src to this version
+func (g *ClusterGitTrackObject) ConvertFrom(src runtime.Object) error {
+	hub, ok := src.(*v1alpha1.ClusterGitTrackObject)
+	if !ok {
+		return fmt.Errorf("unable to convert %T to ClusterGitTrackObject", src)
+	}
+	in := hub.DeepCopy()
+	spec, err := convertSpecFrom(in.Spec)
+	if err != nil {
+		return err
+	}
+	g.ObjectMeta = in.ObjectMeta
+	g.Spec = spec
+	g.Status = convertStatusFrom(in.Status)
+	return nil
+}
+
+// convertSpecTo converts the spec of a (Cluster)GitTrackObject to v1alpha1.
+// The embedded manifest is stored as JSON.
+func convertSpecTo(spec GitTrackObjectSpec) v1alpha1.GitTrackObjectSpec {
+	return v1alpha1.GitTrackObjectSpec{
+		Name: spec.Name,
+		Kind: spec.Kind,
+		Data: spec.Data.Raw,
+	}
+}
+
+// convertSpecFrom converts the spec of a v1alpha1 (Cluster)GitTrackObject to
+// this version. Data written as YAML is converted to JSON so it can be
+// embedded in the object.
+func convertSpecFrom(spec v1alpha1.GitTrackObjectSpec) (GitTrackObjectSpec, error) {
+	data := spec.Data
+	if len(data) > 0 {
+		var err error
+		data, err = yaml.ToJSON(data)
+		if err != nil {
+			return GitTrackObjectSpec{}, fmt.Errorf("unable to convert data of %s %s to JSON: %v", spec.Kind, spec.Name, err)
+		}
+	}
+	return GitTrackObjectSpec{
+		Name: spec.Name,
+		Kind: spec.Kind,
+		Data: runtime.RawExtension{Raw: data},
+	}, nil
+}
+
+// convertStatusTo converts the status of a (Cluster)GitTrackObject to v1alpha1
+func convertStatusTo(status GitTrackObjectStatus) v1alpha1.GitTrackObjectStatus {
+	out := v1alpha1.GitTrackObjectStatus{
+		LastAppliedPatch:       status.LastAppliedPatch,
+		DriftPatch:             status.DriftPatch,
+		LastHandledReconcileAt: status.LastHandledReconcileAt,
+		ObservedDataHash:       status.ObservedDataHash,
+		AppliedDataHash:        status.AppliedDataHash,
+	}
+	for _, c := range status.Conditions {
+		out.Conditions = append(out.Conditions, v1alpha1.GitTrackObjectCondition{
+			Type:               v1alpha1.GitTrackObjectConditionType(c.Type),
+			Status:             c.Status,
+			LastUpdateTime:     c.LastUpdateTime,
+			LastTransitionTime: c.LastTransitionTime,
+			Reason:             c.Reason,
+			Message:            c.Message,
+		})
+	}
+	return out
+}
+
+// convertStatusFrom converts the status of a v1alpha1 (Cluster)GitTrackObject
+// to this version
+func convertStatusFrom(status v1alpha1.GitTrackObjectStatus) GitTrackObjectStatus {
+	out := GitTrackObjectStatus{
+		LastAppliedPatch:       status.LastAppliedPatch,
+		DriftPatch:             status.DriftPatch,
+		LastHandledReconcileAt: status.LastHandledReconcileAt,
+		ObservedDataHash:       status.ObservedDataHash,
+		AppliedDataHash:        status.AppliedDataHash,
+	}
+	for _, c := range status.Conditions {
+		out.Conditions = append(out.Conditions, GitTrackObjectCondition{
+			Type:               GitTrackObjectConditionType(c.Type),
+			Status:             c.Status,
+			LastUpdateTime:     c.LastUpdateTime,
+			LastTransitionTime: c.LastTransitionTime,
+			Reason:             c.Reason,
+			Message:            c.Message,
+		})
+	}
+	return out
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestConvertGitTrackRoundTrip(t *testing.T) {
+	prune := false
+	count := int64(5)
+	now := metav1.Now()
+	hub := &v1alpha1.GitTrack{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", Labels: map[string]string{"hello": "world"}},
+		Spec: v1alpha1.GitTrackSpec{
+			Repository: "git@github.com:example/example.git",
+			Reference:  "master",
+			SubPath:    "config",
+			DeployKey: v1alpha1.GitTrackDeployKey{
+				SecretName: "deploy-key",
+				Key:        "id_rsa",
+				Type:       v1alpha1.GitCredentialTypeSSH,
+			},
+			Cluster:            &v1alpha1.GitTrackCluster{SecretName: "kubeconfig"},
+			ServiceAccountName: "deployer",
+			Adoption:           v1alpha1.AdoptionIfUnowned,
+			DeletionPolicy:     v1alpha1.DeletionPolicyOrphan,
+			DriftPolicy:        v1alpha1.DriftPolicyReport,
+			IgnoreFields:       []v1alpha1.GitTrackIgnoreRule{{Group: "apps", Kind: "Deployment", Fields: []string{"/spec/replicas"}}},
+			Prune:              &prune,
+			PruneThreshold:     &v1alpha1.GitTrackPruneThreshold{Count: &count},
+			Rollback:           &v1alpha1.GitTrackRollback{},
+			Suspend:            true,
+		},
+		Status: v1alpha1.GitTrackStatus{
+			ObjectsDiscovered:      3,
+			ObjectsApplied:         2,
+			Revision:               "abc123",
+			AppliedTime:            &now,
+			IgnoredFiles:           map[string]string{"invalid.yaml": "unable to parse"},
+			LastHandledReconcileAt: "2019-01-01T00:00:00Z",
+			Conditions: []v1alpha1.GitTrackCondition{
+				{Type: v1alpha1.FilesParsedType, Status: v1.ConditionFalse, LastUpdateTime: now, Reason: "ErrorParsingFiles"},
+			},
+		},
+	}
+	g := gomega.NewGomegaWithT(t)
+
+	// Test v1alpha1 -> v1beta1
+	gt := &GitTrack{}
+	g.Expect(gt.ConvertFrom(hub)).To(gomega.Succeed())
+	g.Expect(gt.Name).To(gomega.Equal("foo"))
+	g.Expect(gt.Spec.Source).To(gomega.Equal(GitTrackSource{
+		Repository:  "git@github.com:example/example.git",
+		Reference:   "master",
+		SubPath:     "config",
+		Credentials: &GitTrackCredentials{Type: GitCredentialTypeSSH, SecretName: "deploy-key", Key: "id_rsa"},
+	}))
+	g.Expect(gt.Spec.IgnoreFields).To(gomega.Equal([]GitTrackIgnoreRule{{Group: "apps", Kind: "Deployment", Fields: []string{"/spec/replicas"}}}))
+	g.Expect(gt.Status.Conditions).To(gomega.HaveLen(1))
+	g.Expect(gt.Status.Conditions[0].Type).To(gomega.Equal(FilesParsedType))
+
+	// Test v1beta1 -> v1alpha1
+	converted := &v1alpha1.GitTrack{}
+	g.Expect(gt.ConvertTo(converted)).To(gomega.Succeed())
+	g.Expect(converted).To(gomega.Equal(hub))
+
+	// Test the conversion doesn't share memory
+	*converted.Spec.Prune = true
+	converted.Spec.IgnoreFields[0].Fields[0] = "/spec/template"
+	g.Expect(*gt.Spec.Prune).To(gomega.BeFalse())
+	g.Expect(gt.Spec.IgnoreFields[0].Fields[0]).To(gomega.Equal("/spec/replicas"))
+}
+
+func TestConvertGitTrackWithoutCredentials(t *testing.T) {
+	gt := &GitTrack{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: GitTrackSpec{
+			Source: GitTrackSource{Repository: "https://github.com/example/example", Reference: "master"},
+		},
+	}
+	g := gomega.NewGomegaWithT(t)
+
+	hub := &v1alpha1.GitTrack{}
+	g.Expect(gt.ConvertTo(hub)).To(gomega.Succeed())
+	g.Expect(hub.Spec.DeployKey).To(gomega.Equal(v1alpha1.GitTrackDeployKey{}))
+
+	converted := &GitTrack{}
+	g.Expect(converted.ConvertFrom(hub)).To(gomega.Succeed())
+	g.Expect(converted).To(gomega.Equal(gt))
+}
+
+func TestConvertGitTrackObjectRoundTrip(t *testing.T) {
+	gto := &GitTrackObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "configmap-foo", Namespace: "default"},
+		Spec: GitTrackObjectSpec{
+			Name: "foo",
+			Kind: "ConfigMap",
+			Data: runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo"}}`)},
+		},
+		Status: GitTrackObjectStatus{
+			Conditions: []GitTrackObjectCondition{
+				{Type: ObjectInSyncType, Status: v1.ConditionTrue},
+			},
+			LastAppliedPatch:       `{"data":{"key":"value"}}`,
+			LastHandledReconcileAt: "2019-01-01T00:00:00Z",
+			ObservedDataHash:       "5d41402abc4b2a76b9719d911017c592",
+			AppliedDataHash:        "5d41402abc4b2a76b9719d911017c592",
+		},
+	}
+	g := gomega.NewGomegaWithT(t)
+
+	// Test v1beta1 -> v1alpha1
+	hub := &v1alpha1.GitTrackObject{}
+	g.Expect(gto.ConvertTo(hub)).To(gomega.Succeed())
+	g.Expect(hub.Spec.Data).To(gomega.Equal(gto.Spec.Data.Raw))
+	g.Expect(hub.Status.Conditions[0].Type).To(gomega.Equal(v1alpha1.ObjectInSyncType))
+
+	// Test v1alpha1 -> v1beta1
+	converted := &GitTrackObject{}
+	g.Expect(converted.ConvertFrom(hub)).To(gomega.Succeed())
+	g.Expect(converted).To(gomega.Equal(gto))
+}
+
+func TestConvertGitTrackObjectFromYAML(t *testing.T) {
+	hub := &v1alpha1.GitTrackObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "configmap-foo", Namespace: "default"},
+		Spec: v1alpha1.GitTrackObjectSpec{
+			Name: "foo",
+			Kind: "ConfigMap",
+			Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: foo\n"),
+		},
+	}
+	g := gomega.NewGomegaWithT(t)
+
+	// Test YAML data is embedded as JSON
+	gto := &GitTrackObject{}
+	g.Expect(gto.ConvertFrom(hub)).To(gomega.Succeed())
+	g.Expect(gto.Spec.Data.Raw).To(gomega.MatchJSON(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo"}}`))
+
+	// Test the JSON data round trips unchanged
+	converted := &v1alpha1.GitTrackObject{}
+	g.Expect(gto.ConvertTo(converted)).To(gomega.Succeed())
+	again := &GitTrackObject{}
+	g.Expect(again.ConvertFrom(converted)).To(gomega.Succeed())
+	g.Expect(again).To(gomega.Equal(gto))
+
+	// Test invalid data can't be converted
+	hub.Spec.Data = []byte("key: [value")
+	g.Expect((&GitTrackObject{}).ConvertFrom(hub)).NotTo(gomega.Succeed())
+}
+
+func TestConvertClusterGitTrackObjectRoundTrip(t *testing.T) {
+	hub := &v1alpha1.ClusterGitTrackObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "namespace-foo"},
+		Spec: v1alpha1.GitTrackObjectSpec{
+			Name: "foo",
+			Kind: "Namespace",
+			Data: []byte(`{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"foo"}}`),
+		},
+		Status: v1alpha1.GitTrackObjectStatus{DriftPatch: `{"metadata":{"labels":null}}`},
+	}
+	g := gomega.NewGomegaWithT(t)
+
+	gto := &ClusterGitTrackObject{}
+	g.Expect(gto.ConvertFrom(hub)).To(gomega.Succeed())
+	g.Expect(gto.Spec.Data.Raw).To(gomega.Equal(hub.Spec.Data))
+
+	converted := &v1alpha1.ClusterGitTrackObject{}
+	g.Expect(gto.ConvertTo(converted)).To(gomega.Succeed())
+	g.Expect(converted).To(gomega.Equal(hub))
+
+	// Test converting to the wrong kind
+	g.Expect(gto.ConvertTo(&v1alpha1.GitTrackObject{})).NotTo(gomega.Succeed())
+}
@@ -0,0 +1,332 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GitCredentialType defines the type of git credential
+type GitCredentialType string
+
+const (
+	// GitCredentialTypeSSH defines a private SSH key credential type
+	GitCredentialTypeSSH GitCredentialType = "SSH"
+	// GitCredentialTypeHTTPBasicAuth defines an http basic auth type
+	GitCredentialTypeHTTPBasicAuth GitCredentialType = "HTTPBasicAuth"
+)
+
+// GitTrackDeletionPolicy defines what happens to the children of a GitTrack
+// when the GitTrack is deleted
+type GitTrackDeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the children of a GitTrack along with it
+	DeletionPolicyDelete GitTrackDeletionPolicy = "Delete"
+	// DeletionPolicyOrphan leaves the children of a GitTrack in place when it
+	// is deleted
+	DeletionPolicyOrphan GitTrackDeletionPolicy = "Orphan"
+)
+
+// GitTrackAdoptionPolicy defines whether Faros takes over resources that
+// already exist in the cluster
+type GitTrackAdoptionPolicy string
+
+const (
+	// AdoptionAlways takes over existing resources regardless of their owner
+	AdoptionAlways GitTrackAdoptionPolicy = "Always"
+	// AdoptionIfUnowned takes over existing resources that are not controlled
+	// by another owner
+	AdoptionIfUnowned GitTrackAdoptionPolicy = "IfUnowned"
+	// AdoptionNever never takes over existing resources
+	AdoptionNever GitTrackAdoptionPolicy = "Never"
+)
+
+// GitTrackDriftPolicy defines what happens when a child is modified outside of
+// Git
+type GitTrackDriftPolicy string
+
+const (
+	// DriftPolicyRevert reverts changes made to children outside of Git
+	DriftPolicyRevert GitTrackDriftPolicy = "Revert"
+	// DriftPolicyReport reports changes made to children outside of Git
+	// without reverting them
+	DriftPolicyReport GitTrackDriftPolicy = "Report"
+)
+
+// GitTrackSpec defines the desired state of GitTrack
+type GitTrackSpec struct {
+	// Source is the location in git of the children of this GitTrack
+	Source GitTrackSource `json:"source"`
+
+	// Cluster holds a reference to a kubeconfig for the cluster the children
+	// are deployed to. Defaults to the cluster Faros is running in.
+	Cluster *GitTrackCluster `json:"cluster,omitempty"`
+
+	// ServiceAccountName is the name of a ServiceAccount in the namespace of
+	// the GitTrack that Faros impersonates when creating, updating and deleting
+	// children. Defaults to Faros' own identity.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// Adoption determines whether resources that already exist in the cluster
+	// are taken over by Faros. Accepted values are "Always", "IfUnowned",
+	// "Never". Defaults to "Always".
+	// +kubebuilder:validation:Enum=Always,IfUnowned,Never
+	Adoption GitTrackAdoptionPolicy `json:"adoption,omitempty"`
+
+	// DeletionPolicy determines what happens to the children when the GitTrack
+	// is deleted. Accepted values are "Delete", "Orphan". Defaults to "Delete".
+	// +kubebuilder:validation:Enum=Delete,Orphan
+	DeletionPolicy GitTrackDeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// DriftPolicy determines what happens when a child is modified outside of
+	// Git. Accepted values are "Revert", "Report". Defaults to "Revert".
+	// +kubebuilder:validation:Enum=Revert,Report
+	DriftPolicy GitTrackDriftPolicy `json:"driftPolicy,omitempty"`
+
+	// IgnoreFields lists fields of children that Faros never updates, such as
+	// fields managed by other controllers
+	IgnoreFields []GitTrackIgnoreRule `json:"ignoreFields,omitempty"`
+
+	// Prune determines whether children removed from the repository are
+	// deleted. Defaults to true.
+	Prune *bool `json:"prune,omitempty"`
+
+	// PruneThreshold limits the number of children that may be pruned at once.
+	// Overrides the thresholds configured on the controller.
+	PruneThreshold *GitTrackPruneThreshold `json:"pruneThreshold,omitempty"`
+
+	// Rollback enables automatic rollback to the last revision under which
+	// every child was healthy
+	Rollback *GitTrackRollback `json:"rollback,omitempty"`
+
+	// Suspend stops Faros from fetching the repository and applying its
+	// files to the children until it is unset. Children that already exist
+	// are left in place and changes made to them aren't reverted.
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// GitTrackSource is the location in git of the children of a GitTrack
+type GitTrackSource struct {
+	// Repository is the git repository URI to clone from
+	Repository string `json:"repository"`
+
+	// Reference contains the git reference this GitTrack tracks
+	Reference string `json:"reference"`
+
+	// +kubebuilder:validation:Pattern=^[a-zA-Z0-9/\-.]*$
+	// SubPath is the subpath within the repository underneath which files are considered
+	SubPath string `json:"subPath,omitempty"`
+
+	// Credentials holds a reference to the credentials needed to access the
+	// repository
+	Credentials *GitTrackCredentials `json:"credentials,omitempty"`
+}
+
+// GitTrackCredentials holds a reference to a secret such as an SSH key or HTTP
+// Basic Auth credentials needed to access the repository
+type GitTrackCredentials struct {
+	// Type is the type of credential. Accepted values are "SSH", "HTTPBasicAuth". Defaults to "SSH".
+	// +kubebuilder:validation:Enum=SSH,HTTPBasicAuth
+	Type GitCredentialType `json:"type,omitempty"`
+
+	// SecretName is the name of the Secret object containing the credentials
+	SecretName string `json:"secretName"`
+
+	// Key is the key within the Secret object that contains the credentials
+	Key string `json:"key"`
+}
+
+// GitTrackIgnoreRule lists fields that Faros never updates on children of a
+// kind
+type GitTrackIgnoreRule struct {
+	// Group is the API group of the children. Empty for the core API group.
+	Group string `json:"group,omitempty"`
+
+	// Kind is the kind of the children
+	Kind string `json:"kind"`
+
+	// Fields are the paths of the fields to ignore, either as JSON pointers
+	// (eg. /spec/replicas) or JSONPaths (eg. .spec.replicas)
+	Fields []string `json:"fields"`
+}
+
+// GitTrackPruneThreshold configures the thresholds above which children are not
+// pruned until the deletion is acknowledged
+type GitTrackPruneThreshold struct {
+	// Count is the maximum number of children that may be pruned at once.
+	// A value of 0 disables the threshold.
+	// +kubebuilder:validation:Minimum=0
+	Count *int64 `json:"count,omitempty"`
+
+	// Percent is the maximum percentage of the currently owned children that
+	// may be pruned at once. A value of 0 disables the threshold.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Percent *int64 `json:"percent,omitempty"`
+}
+
+// GitTrackRollback configures automatic rollback of a GitTrack
+type GitTrackRollback struct {
+	// ProgressDeadlineSeconds is the number of seconds children may remain
+	// unhealthy after a new revision is applied before it is rolled back.
+	// Defaults to 600 seconds.
+	// +kubebuilder:validation:Minimum=1
+	ProgressDeadlineSeconds *int64 `json:"progressDeadlineSeconds,omitempty"`
+}
+
+// GitTrackCluster holds a reference to a secret containing the kubeconfig of a
+// remote cluster
+type GitTrackCluster struct {
+	// SecretName is the name of the Secret object containing the kubeconfig
+	SecretName string `json:"secretName"`
+
+	// Key is the key within the Secret object that contains the kubeconfig.
+	// Defaults to "kubeconfig".
+	Key string `json:"key,omitempty"`
+}
+
+// GitTrackStatus defines the observed state of GitTrack
+type GitTrackStatus struct {
+	// ObjectsDiscovered is the number of k8s objects found in the repository path
+	ObjectsDiscovered int64 `json:"objectsDiscovered"`
+
+	// ObjectsApplied is the number of k8s objects for which a GitTrackObjects was created
+	ObjectsApplied int64 `json:"objectsApplied"`
+
+	// ObjectsIgnored is the number of k8s objects found in the repository path for which no GitTrackObject was created
+	ObjectsIgnored int64 `json:"objectsIgnored"`
+
+	// ObjectsInSync is the number of GitTrackObjects that were successfully applied to the cluster
+	ObjectsInSync int64 `json:"objectsInSync"`
+
+	// ObjectsHealthy is the number of GitTrackObjects whose child has reached its desired state
+	ObjectsHealthy int64 `json:"objectsHealthy"`
+
+	// Revision is the commit SHA the Reference resolved to when it was last fetched
+	Revision string `json:"revision,omitempty"`
+
+	// AppliedRevision is the commit SHA whose files were last applied to the children
+	AppliedRevision string `json:"appliedRevision,omitempty"`
+
+	// AppliedTime is the time at which AppliedRevision was first applied
+	AppliedTime *metav1.Time `json:"appliedTime,omitempty"`
+
+	// LastHealthyRevision is the last commit SHA under which every child was healthy
+	LastHealthyRevision string `json:"lastHealthyRevision,omitempty"`
+
+	// RolledBackRevision is the commit SHA that was rolled back because its
+	// children did not become healthy. Faros keeps applying LastHealthyRevision
+	// until the Reference resolves to a newer commit.
+	RolledBackRevision string `json:"rolledBackRevision,omitempty"`
+
+	// IgnoredFiles is the list of YAML files containing invalid k8s manifests.
+	IgnoredFiles map[string]string `json:"ignoredFiles,omitempty"`
+
+	// KeptObjects is the list of children that were removed from the repository
+	// but were not deleted, and the reason they were kept
+	KeptObjects map[string]string `json:"keptObjects,omitempty"`
+
+	// LastHandledReconcileAt is the value of the
+	// faros.pusher.com/reconcile-requested-at annotation when a requested
+	// reconcile was last completed
+	LastHandledReconcileAt string `json:"lastHandledReconcileAt,omitempty"`
+
+	// Conditions are the conditions on this GitTrack
+	Conditions []GitTrackCondition `json:"conditions,omitempty"`
+}
+
+// GitTrackConditionType is the type of a GitTrackCondition
+type GitTrackConditionType string
+
+const (
+	// FilesParsedType refers to whether all files parsed successfully
+	FilesParsedType GitTrackConditionType = "FilesParsed"
+
+	// FilesFetchedType refers to whether all files where fetched from git
+	// successfully
+	FilesFetchedType GitTrackConditionType = "FilesFetched"
+
+	// ChildrenUpToDateType refers to whether all children were created/updated
+	// successfully
+	ChildrenUpToDateType GitTrackConditionType = "ChildrenUpToDate"
+
+	// ChildrenGarbageCollectedType refers to whether all children that were
+	// meant to be garbage collected have been
+	ChildrenGarbageCollectedType GitTrackConditionType = "ChildrenGarbageCollected"
+
+	// RolledBackType refers to whether the GitTrack has been rolled back to
+	// the last revision under which every child was healthy
+	RolledBackType GitTrackConditionType = "RolledBack"
+)
+
+// GitTrackCondition is a status condition for a GitTrack
+type GitTrackCondition struct {
+	// Type of this condition
+	Type GitTrackConditionType `json:"type"`
+
+	// Status of this condition
+	Status v1.ConditionStatus `json:"status"`
+
+	// LastUpdateTime of this condition
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// LastTransitionTime of this condition
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// Reason for the current status of this condition
+	Reason string `json:"reason,omitempty"`
+
+	// Message associated with this condition
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// GitTrack is the Schema for the gittracks API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Repository",type="string",JSONPath=".spec.source.repository",priority=1
+// +kubebuilder:printcolumn:name="Reference",type="string",JSONPath=".spec.source.reference"
+// +kubebuilder:printcolumn:name="Children Created",type="integer",JSONPath=".status.objectsApplied"
+// +kubebuilder:printcolumn:name="Resources Discovered",type="integer",JSONPath=".status.objectsDiscovered"
+// +kubebuilder:printcolumn:name="Resources Ignored",type="integer",JSONPath=".status.objectsIgnored"
+// +kubebuilder:printcolumn:name="Children In Sync",type="integer",JSONPath=".status.objectsInSync"
+// +kubebuilder:printcolumn:name="Children Healthy",type="integer",JSONPath=".status.objectsHealthy"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type GitTrack struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GitTrackSpec   `json:"spec,omitempty"`
+	Status GitTrackStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// GitTrackList contains a list of GitTrack
+type GitTrackList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GitTrack `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GitTrack{}, &GitTrackList{})
+}
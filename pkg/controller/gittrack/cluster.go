@@ -0,0 +1,68 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrack
+
+import (
+	"bytes"
+	"fmt"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	"github.com/pusher/faros/pkg/utils"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// remoteMapper holds the RESTMapper of a remote cluster and the kubeconfig it
+// was created from
+type remoteMapper struct {
+	kubeconfig []byte
+	mapper     meta.RESTMapper
+}
+
+// restMapperFor returns the RESTMapper of the cluster the children of the
+// GitTrack are deployed to
+func (r *ReconcileGitTrack) restMapperFor(gt *farosv1alpha1.GitTrack) (meta.RESTMapper, error) {
+	if gt.Spec.Cluster == nil {
+		return r.restMapper, nil
+	}
+
+	kubeconfig, err := utils.GetKubeconfig(r, gt.Namespace, gt.Spec.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	key := utils.ClusterKey(gt.Namespace, gt.Spec.Cluster)
+	r.mutex.RLock()
+	remote, ok := r.remoteMappers[key]
+	r.mutex.RUnlock()
+	if ok && bytes.Equal(remote.kubeconfig, kubeconfig) {
+		return remote.mapper, nil
+	}
+
+	config, err := utils.RESTConfigFromKubeconfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := utils.NewRestMapper(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create rest mapper for cluster: %v", err)
+	}
+
+	r.mutex.Lock()
+	r.remoteMappers[key] = &remoteMapper{kubeconfig: kubeconfig, mapper: mapper}
+	r.mutex.Unlock()
+	return mapper, nil
+}
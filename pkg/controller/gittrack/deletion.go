@@ -0,0 +1,131 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrack
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	"github.com/pusher/faros/pkg/utils"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// orphanFinalizer is added to GitTracks with the Orphan deletion policy so
+// that their children can be orphaned before the GitTrack is deleted
+const orphanFinalizer = "faros.pusher.com/orphan-children"
+
+// orphanRequeuePeriod is how long to wait before checking whether the children
+// of a GitTrack being deleted have been orphaned
+const orphanRequeuePeriod = 5 * time.Second
+
+// ensureFinalizer adds the orphan finalizer to GitTracks with the Orphan
+// deletion policy and removes it from all other GitTracks
+func (r *ReconcileGitTrack) ensureFinalizer(gt *farosv1alpha1.GitTrack) error {
+	orphan := gt.Spec.DeletionPolicy == farosv1alpha1.DeletionPolicyOrphan
+	if orphan == hasFinalizer(gt, orphanFinalizer) {
+		return nil
+	}
+
+	if orphan {
+		gt.SetFinalizers(append(gt.GetFinalizers(), orphanFinalizer))
+	} else {
+		removeFinalizer(gt, orphanFinalizer)
+	}
+	if err := r.Update(context.TODO(), gt); err != nil {
+		return fmt.Errorf("unable to update finalizers: %v", err)
+	}
+	return nil
+}
+
+// handleDeletion orphans the children of a GitTrack being deleted, if its
+// deletion policy requires it, before allowing the GitTrack to be deleted
+func (r *ReconcileGitTrack) handleDeletion(gt *farosv1alpha1.GitTrack) (reconcile.Result, error) {
+	if !hasFinalizer(gt, orphanFinalizer) {
+		return reconcile.Result{}, nil
+	}
+
+	if gt.Spec.DeletionPolicy == farosv1alpha1.DeletionPolicyOrphan {
+		children, err := r.listObjectsByName(gt)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if len(children) > 0 {
+			// Deleting the GitTrackObjects with the orphan propagation policy
+			// makes the garbage collector strip their owner references from the
+			// children before the GitTrackObjects are removed
+			for name, child := range children {
+				if child.GetDeletionTimestamp() != nil {
+					continue
+				}
+				err := r.orphanChild(child)
+				if err != nil && !errors.IsNotFound(err) {
+					return reconcile.Result{}, fmt.Errorf("failed to orphan child for '%s': %v", name, err)
+				}
+				r.log.V(0).Info("Child orphaned", "child name", name)
+			}
+			r.log.V(1).Info("Waiting for children to be orphaned", "children", len(children))
+			return reconcile.Result{RequeueAfter: orphanRequeuePeriod}, nil
+		}
+		r.recorder.Eventf(gt, apiv1.EventTypeNormal, "ChildrenOrphaned", "Orphaned all children")
+	}
+
+	removeFinalizer(gt, orphanFinalizer)
+	if err := r.Update(context.TODO(), gt); err != nil {
+		return reconcile.Result{}, fmt.Errorf("unable to remove finalizer: %v", err)
+	}
+	return reconcile.Result{}, nil
+}
+
+// orphanChild deletes the (Cluster)GitTrackObject with the orphan propagation
+// policy so that its child is kept. Children in remote clusters are kept by
+// removing the finalizer that would delete them.
+func (r *ReconcileGitTrack) orphanChild(gto farosv1alpha1.GitTrackObjectInterface) error {
+	if hasFinalizer(gto, utils.RemoteChildFinalizer) {
+		removeFinalizer(gto, utils.RemoteChildFinalizer)
+		if err := r.Update(context.TODO(), gto); err != nil {
+			return err
+		}
+	}
+	return r.Delete(context.TODO(), gto, client.PropagationPolicy(metav1.DeletePropagationOrphan))
+}
+
+// hasFinalizer returns whether the object has the given finalizer
+func hasFinalizer(obj metav1.Object, finalizer string) bool {
+	for _, f := range obj.GetFinalizers() {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// removeFinalizer removes the given finalizer from the object
+func removeFinalizer(obj metav1.Object, finalizer string) {
+	finalizers := []string{}
+	for _, f := range obj.GetFinalizers() {
+		if f != finalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	obj.SetFinalizers(finalizers)
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/go-logr/logr"
 	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
 	gittrackutils "github.com/pusher/faros/pkg/controller/gittrack/utils"
+	gittrackobjectutils "github.com/pusher/faros/pkg/controller/gittrackobject/utils"
 	farosflags "github.com/pusher/faros/pkg/flags"
 	utils "github.com/pusher/faros/pkg/utils"
 	farosclient "github.com/pusher/faros/pkg/utils/client"
@@ -38,6 +39,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
@@ -70,6 +72,11 @@ func newReconciler(mgr manager.Manager) reconcile.Reconciler {
 		panic(fmt.Errorf("unable to parse ignored resources: %v", err))
 	}
 
+	protectedKinds, err := farosflags.ParseProtectedKinds()
+	if err != nil {
+		panic(fmt.Errorf("unable to parse protected kinds: %v", err))
+	}
+
 	applier, err := farosclient.NewApplier(mgr.GetConfig(), farosclient.Options{})
 	if err != nil {
 		panic(fmt.Errorf("unable to create applier: %v", err))
@@ -77,12 +84,15 @@ func newReconciler(mgr manager.Manager) reconcile.Reconciler {
 
 	return &ReconcileGitTrack{
 		Client:          mgr.GetClient(),
+		config:          mgr.GetConfig(),
 		scheme:          mgr.GetScheme(),
 		store:           gitstore.NewRepoStore(),
 		restMapper:      restMapper,
 		recorder:        mgr.GetEventRecorderFor("gittrack-controller"),
 		ignoredGVRs:     gvrs,
+		protectedKinds:  protectedKinds,
 		lastUpdateTimes: make(map[string]time.Time),
+		remoteMappers:   make(map[string]*remoteMapper),
 		mutex:           &sync.RWMutex{},
 		applier:         applier,
 		log:             rlogr.Log.WithName("gittrack-controller"),
@@ -127,12 +137,15 @@ var _ reconcile.Reconciler = &ReconcileGitTrack{}
 // ReconcileGitTrack reconciles a GitTrack object
 type ReconcileGitTrack struct {
 	client.Client
+	config          *rest.Config
 	scheme          *runtime.Scheme
 	store           *gitstore.RepoStore
 	restMapper      meta.RESTMapper
 	recorder        record.EventRecorder
 	ignoredGVRs     map[schema.GroupVersionResource]interface{}
+	protectedKinds  map[schema.GroupKind]bool
 	lastUpdateTimes map[string]time.Time
+	remoteMappers   map[string]*remoteMapper
 	mutex           *sync.RWMutex
 	applier         farosclient.Client
 	log             logr.Logger
@@ -144,8 +157,10 @@ func (r *ReconcileGitTrack) withValues(keysAndValues ...interface{}) *ReconcileG
 	return &reconciler
 }
 
-// checkoutRepo checks out the repository at reference and returns a pointer to said repository
-func (r *ReconcileGitTrack) checkoutRepo(url string, ref string, gitCreds *gitCredentials) (*gitstore.Repo, error) {
+// checkoutRepo checks out the repository at reference and returns a pointer to said repository.
+// When fetch is true the latest commits are fetched from the remote rather than relying on the
+// repository store to have fetched them.
+func (r *ReconcileGitTrack) checkoutRepo(url string, ref string, gitCreds *gitCredentials, fetch bool) (*gitstore.Repo, error) {
 	r.log.V(1).Info("Getting repository", "url", url)
 	repoRef, err := createRepoRefFromCreds(url, gitCreds)
 	if err != nil {
@@ -167,9 +182,16 @@ func (r *ReconcileGitTrack) checkoutRepo(url string, ref string, gitCreds *gitCr
 		return repo, fmt.Errorf("timed out getting repository '%s'", url)
 	}
 
-	r.log.V(1).Info("Checking out reference", "reference", ref)
 	ctx, cancel := context.WithTimeout(context.Background(), farosflags.FetchTimeout)
 	defer cancel()
+	if fetch {
+		r.log.V(1).Info("Fetching repository", "url", url)
+		if err = repo.FetchContext(ctx); err != nil {
+			return &gitstore.Repo{}, fmt.Errorf("failed to fetch repository '%s': %v", url, err)
+		}
+	}
+
+	r.log.V(1).Info("Checking out reference", "reference", ref)
 	err = repo.CheckoutContext(ctx, ref)
 	if err != nil {
 		return &gitstore.Repo{}, fmt.Errorf("failed to checkout '%s': %v", ref, err)
@@ -219,40 +241,49 @@ func (r *ReconcileGitTrack) fetchGitCredentials(namespace string, deployKey faro
 	return &gitCredentials{secret: secretData, credentialType: deployKey.Type}, nil
 }
 
-// getFiles checks out the Spec.Repository at Spec.Reference and returns a map of filename to
-// gitstore.File pointers
-func (r *ReconcileGitTrack) getFiles(gt *farosv1alpha1.GitTrack) (map[string]*gitstore.File, error) {
-	r.recorder.Eventf(gt, apiv1.EventTypeNormal, "CheckoutStarted", "Checking out '%s' at '%s'", gt.Spec.Repository, gt.Spec.Reference)
+// revisionOf returns the SHA of the commit currently checked out in the repository
+func revisionOf(repo *gitstore.Repo) (string, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD of repository: %v", err)
+	}
+	return head.Hash().String(), nil
+}
+
+// getFiles checks out the Spec.Repository at the given reference and returns a map of filename to
+// gitstore.File pointers along with the SHA of the commit checked out
+func (r *ReconcileGitTrack) getFiles(gt *farosv1alpha1.GitTrack, ref string, fetch bool) (map[string]*gitstore.File, string, error) {
+	r.recorder.Eventf(gt, apiv1.EventTypeNormal, "CheckoutStarted", "Checking out '%s' at '%s'", gt.Spec.Repository, ref)
 	gitCreds, err := r.fetchGitCredentials(gt.Namespace, gt.Spec.DeployKey)
 	if err != nil {
-		r.recorder.Eventf(gt, apiv1.EventTypeWarning, "CheckoutFailed", "Failed to checkout '%s' at '%s'", gt.Spec.Repository, gt.Spec.Reference)
-		return nil, fmt.Errorf("unable to retrieve git credentials from secret: %v", err)
+		r.recorder.Eventf(gt, apiv1.EventTypeWarning, "CheckoutFailed", "Failed to checkout '%s' at '%s'", gt.Spec.Repository, ref)
+		return nil, "", fmt.Errorf("unable to retrieve git credentials from secret: %v", err)
 	}
 
-	repo, err := r.checkoutRepo(gt.Spec.Repository, gt.Spec.Reference, gitCreds)
+	repo, err := r.checkoutRepo(gt.Spec.Repository, ref, gitCreds, fetch)
 	if err != nil {
-		r.recorder.Eventf(gt, apiv1.EventTypeWarning, "CheckoutFailed", "Failed to checkout '%s' at '%s'", gt.Spec.Repository, gt.Spec.Reference)
-		return nil, err
+		r.recorder.Eventf(gt, apiv1.EventTypeWarning, "CheckoutFailed", "Failed to checkout '%s' at '%s'", gt.Spec.Repository, ref)
+		return nil, "", err
 	}
 
-	subPath := gt.Spec.SubPath
-	if !strings.HasSuffix(subPath, "/") {
-		subPath += "/"
+	revision, err := revisionOf(repo)
+	if err != nil {
+		r.recorder.Eventf(gt, apiv1.EventTypeWarning, "CheckoutFailed", "Failed to checkout '%s' at '%s'", gt.Spec.Repository, ref)
+		return nil, "", err
 	}
 
-	r.log.V(1).Info("Loading files from subpath", "subpath", subPath)
-	globbedSubPath := strings.TrimPrefix(subPath, "/") + "{**/*,*}.{yaml,yml,json}"
-	files, err := repo.GetAllFiles(globbedSubPath, true)
+	r.log.V(1).Info("Loading files from subpath", "subpath", gt.Spec.SubPath)
+	files, err := repo.GetAllFiles(gittrackutils.FilesGlob(gt.Spec.SubPath), true)
 	if err != nil {
 		r.recorder.Eventf(gt, apiv1.EventTypeWarning, "CheckoutFailed", "Failed to get files for SubPath '%s'", gt.Spec.SubPath)
-		return nil, fmt.Errorf("failed to get all files for subpath '%s': %v", gt.Spec.SubPath, err)
+		return nil, "", fmt.Errorf("failed to get all files for subpath '%s': %v", gt.Spec.SubPath, err)
 	} else if len(files) == 0 {
 		r.recorder.Eventf(gt, apiv1.EventTypeWarning, "CheckoutFailed", "No files for SubPath '%s'", gt.Spec.SubPath)
-		return nil, fmt.Errorf("no files for subpath '%s'", gt.Spec.SubPath)
+		return nil, "", fmt.Errorf("no files for subpath '%s'", gt.Spec.SubPath)
 	}
 
-	r.log.V(1).Info("Loaded files from repository", "file count", len(files))
-	return files, nil
+	r.log.V(1).Info("Loaded files from repository", "file count", len(files), "revision", revision)
+	return files, revision, nil
 }
 
 // fetchInstance attempts to fetch the GitTrack resource by the name in the given Request
@@ -274,10 +305,16 @@ func (r *ReconcileGitTrack) fetchInstance(req reconcile.Request) (*farosv1alpha1
 // listObjectsByName lists and filters GitTrackObjects by the `faros.pusher.com/owned-by` label,
 // and returns a map of names to GitTrackObject mappings
 func (r *ReconcileGitTrack) listObjectsByName(owner *farosv1alpha1.GitTrack) (map[string]farosv1alpha1.GitTrackObjectInterface, error) {
+	return ListChildren(r, owner)
+}
+
+// ListChildren lists the GitTrackObjects and ClusterGitTrackObjects controlled
+// by the GitTrack, by namespaced name
+func ListChildren(c client.Reader, owner *farosv1alpha1.GitTrack) (map[string]farosv1alpha1.GitTrackObjectInterface, error) {
 	result := make(map[string]farosv1alpha1.GitTrackObjectInterface)
 
 	gtos := &farosv1alpha1.GitTrackObjectList{}
-	err := r.List(context.TODO(), gtos)
+	err := c.List(context.TODO(), gtos)
 	if err != nil {
 		return nil, err
 	}
@@ -288,7 +325,7 @@ func (r *ReconcileGitTrack) listObjectsByName(owner *farosv1alpha1.GitTrack) (ma
 	}
 
 	cgtos := &farosv1alpha1.ClusterGitTrackObjectList{}
-	err = r.List(context.TODO(), cgtos)
+	err = c.List(context.TODO(), cgtos)
 	if err != nil {
 		return nil, err
 	}
@@ -308,6 +345,7 @@ type result struct {
 	Ignored        bool
 	Reason         string
 	InSync         bool
+	Healthy        bool
 	TimeToDeploy   time.Duration
 }
 
@@ -322,53 +360,26 @@ func ignoreResult(namespacedName string, reason string) result {
 }
 
 // successResult is a convenience function for creating a success result
-func successResult(namespacedName string, timeToDeploy time.Duration, inSync bool) result {
-	return result{NamespacedName: namespacedName, TimeToDeploy: timeToDeploy, InSync: inSync}
+func successResult(namespacedName string, timeToDeploy time.Duration, inSync, healthy bool) result {
+	return result{NamespacedName: namespacedName, TimeToDeploy: timeToDeploy, InSync: inSync, Healthy: healthy}
 }
 
-func (r *ReconcileGitTrack) newGitTrackObjectInterface(name string, u *unstructured.Unstructured) (farosv1alpha1.GitTrackObjectInterface, error) {
-	var instance farosv1alpha1.GitTrackObjectInterface
+func (r *ReconcileGitTrack) newGitTrackObjectInterface(u *unstructured.Unstructured) (farosv1alpha1.GitTrackObjectInterface, error) {
 	_, namespaced, err := utils.GetAPIResource(r.restMapper, u.GetObjectKind().GroupVersionKind())
 	if err != nil {
 		return nil, fmt.Errorf("error getting API resource: %v", err)
 	}
-	if namespaced {
-		instance = &farosv1alpha1.GitTrackObject{
-			TypeMeta: farosv1alpha1.GitTrackObjectTypeMeta,
-		}
-	} else {
-		instance = &farosv1alpha1.ClusterGitTrackObject{
-			TypeMeta: farosv1alpha1.ClusterGitTrackObjectTypeMeta,
-		}
-	}
-	instance.SetName(name)
-	instance.SetNamespace(u.GetNamespace())
-
-	data, err := u.MarshalJSON()
-	if err != nil {
-		return nil, fmt.Errorf("error marshalling JSON: %v", err)
-	}
-
-	instance.SetSpec(farosv1alpha1.GitTrackObjectSpec{
-		Name: u.GetName(),
-		Kind: u.GetKind(),
-		Data: data,
-	})
-	return instance, nil
-}
-
-// objectName constructs a name from an Unstructured object
-func objectName(u *unstructured.Unstructured) string {
-	return strings.ToLower(fmt.Sprintf("%s-%s", u.GetKind(), strings.Replace(u.GetName(), ":", "-", -1)))
+	return gittrackutils.NewGitTrackObject(u, namespaced)
 }
 
-// handleObject either creates or updates a GitTrackObject
-func (r *ReconcileGitTrack) handleObject(u *unstructured.Unstructured, owner *farosv1alpha1.GitTrack) result {
-	name := objectName(u)
-	gto, err := r.newGitTrackObjectInterface(name, u)
+// handleObject either creates or updates a GitTrackObject for an object
+// produced from the given commit
+func (r *ReconcileGitTrack) handleObject(u *unstructured.Unstructured, owner *farosv1alpha1.GitTrack, commit string) result {
+	name := gittrackutils.ObjectName(u)
+	source := utils.DescribeSource(u)
+	gto, err := r.newGitTrackObjectInterface(u)
 	if err != nil {
-		namespacedName := strings.TrimLeft(fmt.Sprintf("%s/%s", u.GetNamespace(), name), "/")
-		return errorResult(namespacedName, err)
+		return errorResult(gittrackutils.ObjectNamespacedName(u), err)
 	}
 
 	ignored, reason, err := r.ignoreObject(u)
@@ -386,32 +397,65 @@ func (r *ReconcileGitTrack) handleObject(u *unstructured.Unstructured, owner *fa
 	if err = controllerutil.SetControllerReference(owner, gto, r.scheme); err != nil {
 		return errorResult(gto.GetNamespacedName(), err)
 	}
+	requestReconcile(owner, gto)
 	found := gto.DeepCopyInterface()
 	err = r.Get(context.TODO(), types.NamespacedName{Name: gto.GetName(), Namespace: gto.GetNamespace()}, found)
 	if err != nil && errors.IsNotFound(err) {
-		return r.createChild(name, timeToDeploy, owner, found, gto)
+		gittrackutils.RecordSourceCommit(gto, nil, commit)
+		return r.createChild(name, utils.DescribeSource(gto), timeToDeploy, owner, gto)
 	} else if err != nil {
 		return errorResult(gto.GetNamespacedName(), fmt.Errorf("failed to get child for '%s': %v", name, err))
 	}
+	// The commit is only recorded in the metadata of the GitTrackObject, and
+	// only changes along with its data, so that commits which don't change
+	// the object don't update it or its child
+	gittrackutils.RecordSourceCommit(gto, found, commit)
+	source = utils.DescribeSource(gto)
 
 	err = checkOwner(owner, found, r.scheme)
+	if err != nil && releasable(found) {
+		// The current owner has released the child, take over as its controller
+		if err = r.adoptChild(owner, found); err != nil {
+			return errorResult(gto.GetNamespacedName(), fmt.Errorf("failed to adopt child '%s': %v", name, err))
+		}
+		r.recorder.Eventf(owner, apiv1.EventTypeNormal, "ChildAdopted", "Adopted releasable child '%s'", name)
+	}
 	if err != nil {
-		r.recorder.Eventf(owner, apiv1.EventTypeWarning, "ControllerMismatch", "Child '%s' is owned by another controller: %v", name, err)
+		r.recorder.Eventf(owner, apiv1.EventTypeWarning, "ControllerMismatch", "Child '%s' from '%s' is owned by another controller: %v", name, source, err)
 		return ignoreResult(gto.GetNamespacedName(), "child is owned by another controller")
 	}
 
 	inSync := childInSync(found)
+	// The health of the child only counts once it has been assessed for the
+	// data being applied, rather than for that of an earlier revision
+	healthy := childHealthy(found) && gittrackobjectutils.StatusObserved(found, gittrackobjectutils.DataHash(gto))
 	childUpdated, err := r.updateChild(found, gto)
 	if err != nil {
-		r.recorder.Eventf(owner, apiv1.EventTypeWarning, "UpdateFailed", "Failed to update child '%s'", name)
-		return errorResult(gto.GetNamespacedName(), fmt.Errorf("failed to update child resource: %v", err))
+		r.recorder.Eventf(owner, apiv1.EventTypeWarning, "UpdateFailed", "Failed to update child '%s' from '%s'", name, source)
+		return errorResult(gto.GetNamespacedName(), fmt.Errorf("failed to update child resource from '%s': %v", source, err))
 	}
 	if childUpdated {
 		inSync = false
-		r.log.V(0).Info("Child updated", "child name", name)
-		r.recorder.Eventf(owner, apiv1.EventTypeNormal, "UpdateSuccessful", "Updated child '%s'", name)
+		r.log.V(0).Info("Child updated", "child name", name, "source", source)
+		r.recorder.Eventf(owner, apiv1.EventTypeNormal, "UpdateSuccessful", "Updated child '%s' from '%s'", name, source)
 	}
-	return successResult(gto.GetNamespacedName(), timeToDeploy, inSync)
+	return successResult(gto.GetNamespacedName(), timeToDeploy, inSync, healthy)
+}
+
+// requestReconcile copies the `faros.pusher.com/reconcile-requested-at`
+// annotation of the GitTrack to the GitTrackObject so that a reconcile
+// requested for the GitTrack also reconciles its children
+func requestReconcile(owner *farosv1alpha1.GitTrack, gto farosv1alpha1.GitTrackObjectInterface) {
+	token, ok := owner.GetAnnotations()[utils.ReconcileRequestedAnnotation]
+	if !ok {
+		return
+	}
+	annotations := gto.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[utils.ReconcileRequestedAnnotation] = token
+	gto.SetAnnotations(annotations)
 }
 
 func childInSync(child farosv1alpha1.GitTrackObjectInterface) bool {
@@ -423,15 +467,24 @@ func childInSync(child farosv1alpha1.GitTrackObjectInterface) bool {
 	return false
 }
 
-func (r *ReconcileGitTrack) createChild(name string, timeToDeploy time.Duration, owner *farosv1alpha1.GitTrack, foundGTO, childGTO farosv1alpha1.GitTrackObjectInterface) result {
-	r.recorder.Eventf(owner, apiv1.EventTypeNormal, "CreateStarted", "Creating child '%s'", name)
+func childHealthy(child farosv1alpha1.GitTrackObjectInterface) bool {
+	for _, condition := range child.GetStatus().Conditions {
+		if condition.Type == farosv1alpha1.ObjectHealthyType && condition.Status == apiv1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ReconcileGitTrack) createChild(name, source string, timeToDeploy time.Duration, owner *farosv1alpha1.GitTrack, childGTO farosv1alpha1.GitTrackObjectInterface) result {
+	r.recorder.Eventf(owner, apiv1.EventTypeNormal, "CreateStarted", "Creating child '%s' from '%s'", name, source)
 	if err := r.applier.Apply(context.TODO(), &farosclient.ApplyOptions{}, childGTO); err != nil {
-		r.recorder.Eventf(owner, apiv1.EventTypeWarning, "CreateFailed", "Failed to create child '%s'", name)
-		return errorResult(childGTO.GetNamespacedName(), fmt.Errorf("failed to create child for '%s': %v", name, err))
+		r.recorder.Eventf(owner, apiv1.EventTypeWarning, "CreateFailed", "Failed to create child '%s' from '%s'", name, source)
+		return errorResult(childGTO.GetNamespacedName(), fmt.Errorf("failed to create child for '%s' from '%s': %v", name, source, err))
 	}
-	r.recorder.Eventf(owner, apiv1.EventTypeNormal, "CreateSuccessful", "Created child '%s'", name)
-	r.log.V(0).Info("Child created", "child name", name)
-	return successResult(childGTO.GetNamespacedName(), timeToDeploy, false)
+	r.recorder.Eventf(owner, apiv1.EventTypeNormal, "CreateSuccessful", "Created child '%s' from '%s'", name, source)
+	r.log.V(0).Info("Child created", "child name", name, "source", source)
+	return successResult(childGTO.GetNamespacedName(), timeToDeploy, false, false)
 }
 
 // UpdateChild compares the two GitTrackObjects and updates the foundGTO if the
@@ -451,12 +504,61 @@ func (r *ReconcileGitTrack) updateChild(foundGTO, childGTO farosv1alpha1.GitTrac
 	return true, nil
 }
 
-// deleteResources deletes any resources that are present in the given map
-func (r *ReconcileGitTrack) deleteResources(leftovers map[string]farosv1alpha1.GitTrackObjectInterface) error {
+// deleteResources deletes any resources that are present in the given map,
+// apart from those that PreviewPrune reported would be kept. Kept children
+// that pruning was disabled for by annotation or that are protected from
+// deletion are orphaned.
+func (r *ReconcileGitTrack) deleteResources(owner *farosv1alpha1.GitTrack, leftovers map[string]farosv1alpha1.GitTrackObjectInterface, kept map[string]string) error {
 	if len(leftovers) > 0 {
 		r.log.V(0).Info("Found leftover resources to clean up", "leftover resources", string(len(leftovers)))
 	}
 	for name, obj := range leftovers {
+		reason, ok := kept[name]
+		if ok && reason == prunePolicyReason {
+			r.log.V(1).Info("Pruning disabled, keeping child", "child name", name)
+			continue
+		}
+		if ok {
+			// Orphan the child so that it is kept when the GitTrackObject is deleted
+			if err := r.orphanChild(obj); err != nil {
+				return fmt.Errorf("failed to orphan child for '%s': '%s'", name, err)
+			}
+			r.log.V(0).Info("Child orphaned", "child name", name)
+			if reason == deleteProtectedReason {
+				r.recorder.Eventf(owner, apiv1.EventTypeNormal, "ChildOrphaned", "Orphaned child '%s' as it is protected from deletion", name)
+			} else {
+				r.recorder.Eventf(owner, apiv1.EventTypeNormal, "ChildOrphaned", "Orphaned child '%s' as pruning is disabled by annotation", name)
+			}
+			continue
+		}
+
+		child, err := utils.YAMLToUnstructured(obj.GetSpec().Data)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal child for '%s': %v", name, err)
+		}
+
+		// Delete the child directly when a propagation policy is set, as the
+		// garbage collector would otherwise delete it in the background.
+		// Children in remote clusters are deleted by the GitTrackObject
+		// controller.
+		propagation, err := utils.GetDeletionPropagation(&child)
+		if err != nil {
+			return fmt.Errorf("failed to get deletion propagation of child '%s': %v", name, err)
+		}
+		if propagation != nil && owner.Spec.Cluster == nil {
+			children, err := r.childClientFor(owner)
+			if err != nil {
+				return fmt.Errorf("failed to create client for child '%s': %v", name, err)
+			}
+			err = children.Delete(context.TODO(), &child, client.PropagationPolicy(*propagation))
+			if errors.IsForbidden(err) {
+				r.recorder.Eventf(owner, apiv1.EventTypeWarning, "PermissionDenied", "Not permitted to delete child '%s': %v", name, err)
+				return fmt.Errorf("failed to delete child '%s': %v", name, err)
+			} else if err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete child '%s': %v", name, err)
+			}
+		}
+
 		if err := r.Delete(context.TODO(), obj); err != nil {
 			return fmt.Errorf("failed to delete child for '%s': '%s'", name, err)
 		}
@@ -467,19 +569,11 @@ func (r *ReconcileGitTrack) deleteResources(leftovers map[string]farosv1alpha1.G
 
 // objectsFrom iterates through all the files given and attempts to create Unstructured objects
 func objectsFrom(files map[string]*gitstore.File) ([]*unstructured.Unstructured, map[string]string) {
-	objects := []*unstructured.Unstructured{}
-	fileErrors := make(map[string]string)
+	contents := make(map[string]string)
 	for path, file := range files {
-		// TODO (@JoelSpeed): What happens if there are multiple resources in one file,
-		// but one of them is invalid? Can we still get the rest?
-		us, err := utils.YAMLToUnstructuredSlice([]byte(file.Contents()))
-		if err != nil {
-			fileErrors[path] = fmt.Sprintf("unable to parse '%s': %v\n", path, err)
-			continue
-		}
-		objects = append(objects, us...)
+		contents[path] = file.Contents()
 	}
-	return objects, fileErrors
+	return gittrackutils.ObjectsFrom(contents)
 }
 
 // checkOwner checks the owner reference of an object from the API to see if it
@@ -505,17 +599,11 @@ func (r *ReconcileGitTrack) ignoreObject(u *unstructured.Unstructured) (bool, st
 		return false, "", err
 	}
 
-	// Ignore namespaced objects not in the namespace managed by the controller
-	if namespaced && farosflags.Namespace != "" && farosflags.Namespace != u.GetNamespace() {
-		r.log.V(1).Info("Object not in namespace", "object namespace", u.GetNamespace(), "managed namespace", farosflags.Namespace)
-		return true, fmt.Sprintf("namespace `%s` is not managed by this Faros", u.GetNamespace()), nil
-	}
-	// Ignore GVKs in the ignoredGVKs set
-	if _, ok := r.ignoredGVRs[gvr]; ok {
-		r.log.V(1).Info("Object group version ignored globally", "group version resource", gvr.String())
-		return true, fmt.Sprintf("resource `%s.%s/%s` ignored globally by flag", gvr.Resource, gvr.Group, gvr.Version), nil
+	ignored, reason := gittrackutils.IgnoreObject(u, gvr, namespaced, farosflags.Namespace, r.ignoredGVRs)
+	if ignored {
+		r.log.V(1).Info("Object ignored", "group version resource", gvr.String(), "object namespace", u.GetNamespace(), "reason", reason)
 	}
-	return false, "", nil
+	return ignored, reason, nil
 }
 
 // Reconcile reads the state of the cluster for a GitTrack object and makes changes based on the state read
@@ -536,11 +624,36 @@ func (r *ReconcileGitTrack) Reconcile(request reconcile.Request) (reconcile.Resu
 	)
 	reconciler.log.V(1).Info("Reconcile started")
 
+	if instance.GetDeletionTimestamp() != nil {
+		reconciler.log.V(1).Info("GitTrack is being deleted")
+		return reconciler.handleDeletion(instance)
+	}
+	if err = reconciler.ensureFinalizer(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+	if instance.Spec.Suspend {
+		reconciler.log.V(1).Info("GitTrack is suspended")
+		return reconcile.Result{}, nil
+	}
+
 	sOpts := newStatusOpts()
+	sOpts.revisionsFrom(instance.Status)
 	mOpts := newMetricOpts(sOpts)
 
+	// A requested reconcile fetches the repository and is recorded as handled
+	// once this reconcile finishes without errors. Otherwise the request is
+	// retried along with the reconcile.
+	sOpts.lastHandledReconcileAt = instance.Status.LastHandledReconcileAt
+	requested := utils.ReconcileRequest(instance, instance.Status.LastHandledReconcileAt)
+	if requested != "" {
+		reconciler.log.V(0).Info("Reconcile requested", "requested at", requested)
+	}
+
 	// Update the GitTrack status when we leave this function
 	defer func() {
+		if requested != "" && !sOpts.failed() {
+			sOpts.lastHandledReconcileAt = requested
+		}
 		err := reconciler.updateStatus(instance, sOpts)
 		mErr := reconciler.updateMetrics(instance, mOpts)
 
@@ -563,16 +676,37 @@ func (r *ReconcileGitTrack) Reconcile(request reconcile.Request) (reconcile.Resu
 	// Set the repository for metrics
 	mOpts.repository = instance.Spec.Repository
 
+	// Map children to the API of the cluster they are deployed to
+	reconciler.restMapper, err = reconciler.restMapperFor(instance)
+	if err != nil {
+		sOpts.upToDateError = err
+		sOpts.upToDateReason = gittrackutils.ErrorConnectingToCluster
+		return reconcile.Result{}, err
+	}
+
 	// Get a map of the files that are in the Spec
-	files, err := reconciler.getFiles(instance)
+	files, revision, err := reconciler.getFiles(instance, instance.Spec.Reference, requested != "")
 	if err != nil {
 		sOpts.gitError = err
 		sOpts.gitReason = gittrackutils.ErrorFetchingFiles
 		return reconcile.Result{}, err
 	}
+
+	// If the revision has been rolled back, apply the last healthy revision instead
+	target := targetRevision(instance, revision)
+	if target != revision {
+		reconciler.log.V(1).Info("Revision rolled back, using last healthy revision", "revision", revision, "last healthy revision", target)
+		files, _, err = reconciler.getFiles(instance, target, false)
+		if err != nil {
+			sOpts.gitError = err
+			sOpts.gitReason = gittrackutils.ErrorFetchingFiles
+			return reconcile.Result{}, err
+		}
+	}
+	setRevisions(instance, sOpts, revision, target)
 	// Git successful, set condition
 	sOpts.gitReason = gittrackutils.GitFetchSuccess
-	reconciler.recorder.Eventf(instance, apiv1.EventTypeNormal, "CheckoutSuccessful", "Successfully checked out '%s' at '%s'", instance.Spec.Repository, instance.Spec.Reference)
+	reconciler.recorder.Event(instance, apiv1.EventTypeNormal, "CheckoutSuccessful", checkoutMessage(instance, revision, target))
 
 	// Attempt to parse k8s objects from files
 	objects, fileErrors := objectsFrom(files)
@@ -589,6 +723,11 @@ func (r *ReconcileGitTrack) Reconcile(request reconcile.Request) (reconcile.Resu
 		sOpts.parseReason = gittrackutils.FileParseSuccess
 	}
 
+	// Record the GitTrack that each object was produced by
+	for _, obj := range objects {
+		utils.SetGitTrack(obj, fmt.Sprintf("%s/%s", instance.Namespace, instance.Name))
+	}
+
 	// Update status with the number of objects discovered
 	sOpts.discovered = int64(len(objects))
 
@@ -597,17 +736,32 @@ func (r *ReconcileGitTrack) Reconcile(request reconcile.Request) (reconcile.Resu
 	if err != nil {
 		return reconcile.Result{}, err
 	}
-	// Process the objects and feed back the results
-	resultsChan := make(chan result, len(objects))
+	owned := len(objectsByName)
+
+	// Ignore objects whose children would take the name of an earlier object's,
+	// in order of file path, recording them under the file they came from
+	collisions := gittrackutils.NameCollisions(objects)
+	tracked := []*unstructured.Unstructured{}
 	for _, obj := range objects {
+		if reason, ok := collisions[obj]; ok {
+			sOpts.ignoredFiles[gittrackutils.IgnoredObjectKey(obj)] = reason
+			sOpts.ignored++
+			continue
+		}
+		tracked = append(tracked, obj)
+	}
+
+	// Process the objects and feed back the results
+	resultsChan := make(chan result, len(tracked))
+	for _, obj := range tracked {
 		go func(obj *unstructured.Unstructured) {
-			resultsChan <- reconciler.handleObject(obj, instance)
+			resultsChan <- reconciler.handleObject(obj, instance, target)
 		}(obj)
 	}
 
 	handlerErrors := []string{}
 	// Iterate through results and update status accordingly
-	for range objects {
+	for range tracked {
 		res := <-resultsChan
 		if res.Ignored {
 			sOpts.ignoredFiles[res.NamespacedName] = res.Reason
@@ -619,6 +773,9 @@ func (r *ReconcileGitTrack) Reconcile(request reconcile.Request) (reconcile.Resu
 		if res.InSync {
 			sOpts.inSync++
 		}
+		if res.Healthy {
+			sOpts.healthy++
+		}
 		delete(objectsByName, res.NamespacedName)
 		if res.Error != nil {
 			handlerErrors = append(handlerErrors, res.Error.Error())
@@ -634,14 +791,34 @@ func (r *ReconcileGitTrack) Reconcile(request reconcile.Request) (reconcile.Resu
 		sOpts.upToDateReason = gittrackutils.ChildrenUpdateSuccess
 	}
 
-	// Cleanup potentially leftover resources
-	if err = reconciler.deleteResources(objectsByName); err != nil {
+	// Refuse to clean up leftover resources if too many would be deleted at
+	// once. Leftovers that would be kept rather than deleted don't count.
+	kept, err := PreviewPrune(reconciler, instance, objectsByName, reconciler.protectedKinds)
+	if err != nil {
 		sOpts.gcError = err
 		sOpts.gcReason = gittrackutils.ErrorDeletingChildren
 		reconciler.recorder.Eventf(instance, apiv1.EventTypeWarning, "CleanupFailed", "Failed to clean-up leftover resources")
 		return reconcile.Result{}, fmt.Errorf("failed to clean-up tracked objects: %v", err)
 	}
-	sOpts.gcReason = gittrackutils.GCSuccess
+	pruned := len(objectsByName) - len(kept)
+	if err = CheckPruneThreshold(instance, owned, pruned, sOpts.appliedRevision); err != nil {
+		sOpts.gcError = err
+		sOpts.gcReason = gittrackutils.PruneThresholdExceeded
+		sOpts.keptObjects = keepLeftovers(objectsByName, kept, pruneThresholdReason)
+		reconciler.recorder.Eventf(instance, apiv1.EventTypeWarning, "PruneThresholdExceeded", "Refusing to clean-up %d leftover resources", pruned)
+	} else {
+		// Cleanup potentially leftover resources
+		sOpts.keptObjects = kept
+		err = reconciler.deleteResources(instance, objectsByName, kept)
+		if err != nil {
+			sOpts.gcError = err
+			sOpts.gcReason = gittrackutils.ErrorDeletingChildren
+			reconciler.recorder.Eventf(instance, apiv1.EventTypeWarning, "CleanupFailed", "Failed to clean-up leftover resources")
+			return reconcile.Result{}, fmt.Errorf("failed to clean-up tracked objects: %v", err)
+		}
+		sOpts.gcReason = gittrackutils.GCSuccess
+	}
 
-	return reconcile.Result{}, nil
+	allHealthy := len(handlerErrors) == 0 && len(fileErrors) == 0 && sOpts.healthy == sOpts.applied
+	return reconciler.handleRollback(instance, sOpts, allHealthy), nil
 }
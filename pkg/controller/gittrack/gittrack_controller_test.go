@@ -30,7 +30,9 @@ import (
 	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
 	"github.com/pusher/faros/pkg/controller/gittrack/metrics"
 	gittrackutils "github.com/pusher/faros/pkg/controller/gittrack/utils"
+	gittrackobjectutils "github.com/pusher/faros/pkg/controller/gittrackobject/utils"
 	farosflags "github.com/pusher/faros/pkg/flags"
+	"github.com/pusher/faros/pkg/utils"
 	farosclient "github.com/pusher/faros/pkg/utils/client"
 	testevents "github.com/pusher/faros/test/events"
 	testutils "github.com/pusher/faros/test/utils"
@@ -205,6 +207,7 @@ var _ = Describe("GitTrack Suite", func() {
 				setsMetric("applied", 2.0)
 				setsMetric("ignored", 0.0)
 				setsMetric("inSync", 0.0)
+				setsMetric("healthy", 0.0)
 			})
 
 			It("creates GitTrackObjects", func() {
@@ -244,6 +247,18 @@ var _ = Describe("GitTrack Suite", func() {
 				Expect(serviceGto.GetAnnotations()).To(HaveKey(farosclient.LastAppliedAnnotation))
 			})
 
+			It("records the provenance of created GitTrackObjects", func() {
+				deployGto := &farosv1alpha1.GitTrackObject{}
+				Eventually(func() error {
+					return c.Get(context.TODO(), types.NamespacedName{Name: "deployment-nginx", Namespace: "default"}, deployGto)
+				}, timeout).Should(Succeed())
+				annotations := deployGto.GetAnnotations()
+				Expect(annotations).To(HaveKey(utils.SourcePathAnnotation))
+				Expect(annotations).To(HaveKeyWithValue(utils.SourceCommitAnnotation, "a14443638218c782b84cae56a14f1090ee9e5c9c"))
+				Expect(annotations).To(HaveKeyWithValue(utils.GitTrackAnnotation, "default/example"))
+				Expect(string(deployGto.Spec.Data)).NotTo(ContainSubstring(utils.SourceCommitAnnotation))
+			})
+
 			It("sends events about checking out configured Git repository", func() {
 				events := &v1.EventList{}
 				Eventually(func() error { return c.List(context.TODO(), events) }, timeout).Should(Succeed())
@@ -408,6 +423,32 @@ var _ = Describe("GitTrack Suite", func() {
 			})
 		})
 
+		Context("with objects whose GitTrackObjects would have the same name", func() {
+			BeforeEach(func() {
+				instance.Spec.SubPath = "collision"
+				createInstance(instance, "49f70363060ece5a78c50afadee36bb81273c706")
+				// Wait for client cache to expire
+				waitForInstanceCreated(key)
+			})
+
+			It("tracks the object from the first file", func() {
+				gto := &farosv1alpha1.GitTrackObject{}
+				Eventually(func() error {
+					return c.Get(context.TODO(), types.NamespacedName{Name: "deployment-nginx", Namespace: "default"}, gto)
+				}, timeout).Should(Succeed())
+				Expect(gto.Annotations).To(HaveKeyWithValue(utils.SourcePathAnnotation, "collision/deployment.yaml"))
+			})
+
+			It("adds the object from the later file to the ignoredFiles status", func() {
+				Eventually(func() (int64, error) {
+					err := c.Get(context.TODO(), key, instance)
+					return instance.Status.ObjectsIgnored, err
+				}, timeout).Should(Equal(int64(1)))
+				Expect(instance.Status.IgnoredFiles).To(HaveLen(1))
+				Expect(instance.Status.IgnoredFiles).To(HaveKeyWithValue("collision/extensions-deployment.yaml", ContainSubstring("which is tracked instead")))
+			})
+		})
+
 		Context("with a child owned by another controller", func() {
 			truth := true
 			var existingChild *farosv1alpha1.GitTrackObject
@@ -471,6 +512,76 @@ var _ = Describe("GitTrack Suite", func() {
 			})
 		})
 
+		Context("with a releasable child owned by another controller", func() {
+			truth := true
+			var existingChild *farosv1alpha1.GitTrackObject
+			BeforeEach(func() {
+				existingChild = &farosv1alpha1.GitTrackObject{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "deployment-nginx",
+						Namespace: "default",
+						Annotations: map[string]string{
+							gittrackutils.ReleasableAnnotation: "true",
+						},
+						OwnerReferences: []metav1.OwnerReference{
+							{
+								APIVersion:         "faros.pusher.com/v1alpha1",
+								Kind:               "GitTrack",
+								Name:               doesNotExistPath,
+								UID:                "12345",
+								Controller:         &truth,
+								BlockOwnerDeletion: &truth,
+							},
+						},
+					},
+					Spec: farosv1alpha1.GitTrackObjectSpec{
+						Name: "nginx",
+						Kind: "Deployment",
+						Data: []byte("kind: Deployment"),
+					},
+				}
+				err := c.Create(context.TODO(), existingChild)
+				Expect(err).ToNot(HaveOccurred())
+
+				createInstance(instance, "4c31dbdd7103dc209c8bb21b75d78b3efafadc31")
+				// Wait for client cache to expire
+				waitForInstanceCreated(key)
+			})
+
+			It("should adopt the existing child", func() {
+				deployGto := &farosv1alpha1.GitTrackObject{}
+				Eventually(func() error {
+					err := c.Get(context.TODO(), types.NamespacedName{Name: "deployment-nginx", Namespace: "default"}, deployGto)
+					if err != nil {
+						return err
+					}
+					if !metav1.IsControlledBy(deployGto, instance) {
+						return fmt.Errorf("child not adopted")
+					}
+					return nil
+				}, timeout).Should(Succeed())
+
+				Expect(deployGto.UID).To(Equal(existingChild.UID))
+				Expect(deployGto.OwnerReferences).To(HaveLen(1))
+				Expect(deployGto.Annotations).NotTo(HaveKey(gittrackutils.ReleasableAnnotation))
+				Expect(deployGto.Spec.Data).NotTo(Equal(existingChild.Spec.Data))
+			})
+
+			It("should record itself in the data of the adopted child", func() {
+				// The GitTrackObject controller applies the data, re-parenting the
+				// child onto the new GitTrack
+				deployGto := &farosv1alpha1.GitTrackObject{}
+				Eventually(func() (map[string]string, error) {
+					err := c.Get(context.TODO(), types.NamespacedName{Name: "deployment-nginx", Namespace: "default"}, deployGto)
+					return deployGto.Annotations, err
+				}, timeout).Should(HaveKeyWithValue(utils.GitTrackAnnotation, "default/example"))
+
+				data, err := utils.YAMLToUnstructured(deployGto.Spec.Data)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(data.GetAnnotations()).To(HaveKeyWithValue(utils.GitTrackAnnotation, "default/example"))
+			})
+		})
+
 		Context("with a child resource that has a name that contains `:`", func() {
 			BeforeEach(func() {
 				createInstance(instance, "241786090da55894dca4e91e3f5023c024d3d9a8")
@@ -508,9 +619,201 @@ var _ = Describe("GitTrack Suite", func() {
 				Eventually(requests, timeout).ShouldNot(Receive())
 			})
 		})
+
+		Context("that is suspended", func() {
+			BeforeEach(func() {
+				instance.Spec.Suspend = true
+				createInstance(instance, "a14443638218c782b84cae56a14f1090ee9e5c9c")
+				Eventually(requests, timeout).Should(Receive(Equal(reconcile.Request{NamespacedName: key})))
+			})
+
+			It("does not create GitTrackObjects", func() {
+				gtos := &farosv1alpha1.GitTrackObjectList{}
+				Consistently(func() ([]farosv1alpha1.GitTrackObject, error) {
+					err := c.List(context.TODO(), gtos)
+					return gtos.Items, err
+				}, time.Second).Should(BeEmpty())
+			})
+
+			It("does not update its status", func() {
+				gt := &farosv1alpha1.GitTrack{}
+				Consistently(func() ([]farosv1alpha1.GitTrackCondition, error) {
+					err := c.Get(context.TODO(), key, gt)
+					return gt.Status.Conditions, err
+				}, time.Second).Should(BeEmpty())
+			})
+		})
+
+		Context("with the Orphan deletion policy", func() {
+			BeforeEach(func() {
+				instance.Spec.DeletionPolicy = farosv1alpha1.DeletionPolicyOrphan
+				createInstance(instance, "a14443638218c782b84cae56a14f1090ee9e5c9c")
+				waitForInstanceCreated(key)
+			})
+
+			AfterEach(func() {
+				// The controller is stopped before the GitTrack is deleted so
+				// the finalizer must be removed manually
+				Eventually(func() error {
+					err := c.Get(context.TODO(), key, instance)
+					if err != nil {
+						return err
+					}
+					removeFinalizer(instance, orphanFinalizer)
+					return c.Update(context.TODO(), instance)
+				}, timeout).Should(Succeed())
+			})
+
+			It("adds the orphan finalizer", func() {
+				Eventually(func() error {
+					err := c.Get(context.TODO(), key, instance)
+					if err != nil {
+						return err
+					}
+					if !hasFinalizer(instance, orphanFinalizer) {
+						return fmt.Errorf("finalizer not added")
+					}
+					return nil
+				}, timeout).Should(Succeed())
+			})
+
+			It("removes the orphan finalizer when the policy is changed", func() {
+				Eventually(func() error {
+					err := c.Get(context.TODO(), key, instance)
+					if err != nil {
+						return err
+					}
+					instance.Spec.DeletionPolicy = farosv1alpha1.DeletionPolicyDelete
+					return c.Update(context.TODO(), instance)
+				}, timeout).Should(Succeed())
+
+				Eventually(func() error {
+					err := c.Get(context.TODO(), key, instance)
+					if err != nil {
+						return err
+					}
+					if hasFinalizer(instance, orphanFinalizer) {
+						return fmt.Errorf("finalizer not removed")
+					}
+					return nil
+				}, timeout).Should(Succeed())
+			})
+		})
 	})
 
 	Context("When a GitTrack resource is updated", func() {
+		Context("and a reconcile is requested", func() {
+			BeforeEach(func() {
+				createInstance(instance, "a14443638218c782b84cae56a14f1090ee9e5c9c")
+				// Wait for client cache to expire
+				waitForInstanceCreated(key)
+
+				Eventually(func() error {
+					err := c.Get(context.TODO(), key, instance)
+					if err != nil {
+						return err
+					}
+					instance.SetAnnotations(map[string]string{utils.ReconcileRequestedAnnotation: "2019-01-01T00:00:00Z"})
+					return c.Update(context.TODO(), instance)
+				}, timeout).Should(Succeed())
+			})
+
+			It("records the request as handled in its status", func() {
+				Eventually(func() (string, error) {
+					err := c.Get(context.TODO(), key, instance)
+					return instance.Status.LastHandledReconcileAt, err
+				}, timeout).Should(Equal("2019-01-01T00:00:00Z"))
+			})
+
+			It("requests a reconcile of its GitTrackObjects", func() {
+				gto := &farosv1alpha1.GitTrackObject{}
+				Eventually(func() (map[string]string, error) {
+					err := c.Get(context.TODO(), types.NamespacedName{Name: "deployment-nginx", Namespace: "default"}, gto)
+					return gto.GetAnnotations(), err
+				}, timeout).Should(HaveKeyWithValue(utils.ReconcileRequestedAnnotation, "2019-01-01T00:00:00Z"))
+			})
+		})
+
+		Context("and a reconcile is requested for an invalid Reference", func() {
+			BeforeEach(func() {
+				instance.SetAnnotations(map[string]string{utils.ReconcileRequestedAnnotation: "2019-01-01T00:00:00Z"})
+				createInstance(instance, doesNotExistPath)
+				// Wait for client cache to expire
+				waitForInstanceCreated(key)
+			})
+
+			It("doesn't record the failed request as handled", func() {
+				Consistently(func() (string, error) {
+					err := c.Get(context.TODO(), key, instance)
+					return instance.Status.LastHandledReconcileAt, err
+				}, time.Second).Should(BeEmpty())
+			})
+		})
+
+		Context("and a new revision doesn't become healthy", func() {
+			const healthyReference = "a14443638218c782b84cae56a14f1090ee9e5c9c"
+
+			// markHealthy reports the child of the GitTrackObject as healthy for
+			// its current data, as the GitTrackObject controller would
+			var markHealthy = func(name string) {
+				Eventually(func() error {
+					gto := &farosv1alpha1.GitTrackObject{}
+					err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "default"}, gto)
+					if err != nil {
+						return err
+					}
+					now := metav1.NewTime(time.Now())
+					gto.Status.Conditions = []farosv1alpha1.GitTrackObjectCondition{
+						{
+							Type:               farosv1alpha1.ObjectHealthyType,
+							Status:             v1.ConditionTrue,
+							LastTransitionTime: now,
+							LastUpdateTime:     now,
+						},
+					}
+					gto.Status.ObservedDataHash = gittrackobjectutils.DataHash(gto)
+					return c.Update(context.TODO(), gto)
+				}, timeout).Should(Succeed())
+			}
+
+			// statusOf lets a pending reconcile finish and returns the status of
+			// the GitTrack
+			var statusOf = func() farosv1alpha1.GitTrackStatus {
+				select {
+				case <-requests:
+				default:
+				}
+				gt := &farosv1alpha1.GitTrack{}
+				c.Get(context.TODO(), key, gt)
+				return gt.Status
+			}
+
+			BeforeEach(func() {
+				deadline := int64(1)
+				instance.Spec.Rollback = &farosv1alpha1.GitTrackRollback{ProgressDeadlineSeconds: &deadline}
+				createInstance(instance, healthyReference)
+				// Wait for client cache to expire
+				waitForInstanceCreated(key)
+
+				markHealthy("deployment-nginx")
+				markHealthy("service-nginx")
+				Eventually(func() string { return statusOf().LastHealthyRevision }, timeout).Should(Equal(healthyReference))
+
+				Eventually(func() error { return c.Get(context.TODO(), key, instance) }, timeout).Should(Succeed())
+				instance.Spec.Reference = repeatedReference
+				Expect(c.Update(context.TODO(), instance)).To(Succeed())
+			})
+
+			It("doesn't record the new revision as healthy", func() {
+				Consistently(func() string { return statusOf().LastHealthyRevision }, time.Second).Should(Equal(healthyReference))
+			})
+
+			It("rolls back to the last healthy revision", func() {
+				Eventually(func() string { return statusOf().RolledBackRevision }, timeout).Should(Equal(repeatedReference))
+				Eventually(func() string { return statusOf().AppliedRevision }, timeout).Should(Equal(healthyReference))
+			})
+		})
+
 		Context("and resources are added to the repository", func() {
 			BeforeEach(func() {
 				createInstance(instance, "28928ccaeb314b96293e18cc8889997f0f46b79b")
@@ -584,6 +887,130 @@ var _ = Describe("GitTrack Suite", func() {
 			})
 		})
 
+		Context("and resources are removed from the repository with pruning disabled", func() {
+			BeforeEach(func() {
+				prune := false
+				instance.Spec.Prune = &prune
+				createInstance(instance, "4532b487a5aaf651839f5401371556aa16732a6e")
+				// Wait for client cache to expire
+				waitForInstanceCreated(key)
+
+				// Check the configmap to be kept was created
+				Eventually(func() error {
+					return c.Get(context.TODO(), types.NamespacedName{Name: "configmap-deleted-config", Namespace: "default"}, &farosv1alpha1.GitTrackObject{})
+				}, timeout).Should(Succeed())
+
+				// Update the repository
+				Eventually(func() error { return c.Get(context.TODO(), key, instance) }, timeout).Should(Succeed())
+				instance.Spec.Reference = "28928ccaeb314b96293e18cc8889997f0f46b79b"
+				err := c.Update(context.TODO(), instance)
+				Expect(err).ToNot(HaveOccurred())
+
+				// Wait for cache to sync
+				waitForInstanceCreated(key)
+			})
+
+			It("doesn't delete the removed resources", func() {
+				Consistently(func() error {
+					return c.Get(context.TODO(), types.NamespacedName{Name: "configmap-deleted-config", Namespace: "default"}, &farosv1alpha1.GitTrackObject{})
+				}, time.Second).Should(Succeed())
+			})
+
+			It("lists the kept resources in the status", func() {
+				Eventually(func() error {
+					err := c.Get(context.TODO(), key, instance)
+					if err != nil {
+						return err
+					}
+					if _, ok := instance.Status.KeptObjects["default/configmap-deleted-config"]; !ok {
+						return fmt.Errorf("kept objects not updated")
+					}
+					return nil
+				}, timeout).Should(Succeed())
+			})
+		})
+
+		Context("and resources are removed from the repository above the prune threshold", func() {
+			var removedGTO *farosv1alpha1.GitTrackObject
+
+			// updateRepository updates the repository to remove the configmap
+			updateRepository := func() {
+				Eventually(func() error { return c.Get(context.TODO(), key, instance) }, timeout).Should(Succeed())
+				instance.Spec.Reference = "28928ccaeb314b96293e18cc8889997f0f46b79b"
+				err := c.Update(context.TODO(), instance)
+				Expect(err).ToNot(HaveOccurred())
+
+				// Wait for cache to sync
+				waitForInstanceCreated(key)
+			}
+
+			// keptReason returns the reason the removed configmap was kept
+			keptReason := func() (string, error) {
+				err := c.Get(context.TODO(), key, instance)
+				if err != nil {
+					return "", err
+				}
+				return instance.Status.KeptObjects["default/configmap-deleted-config"], nil
+			}
+
+			BeforeEach(func() {
+				percent := int64(10)
+				instance.Spec.PruneThreshold = &farosv1alpha1.GitTrackPruneThreshold{Percent: &percent}
+				createInstance(instance, "4532b487a5aaf651839f5401371556aa16732a6e")
+				// Wait for client cache to expire
+				waitForInstanceCreated(key)
+
+				// Check the configmap to be removed was created
+				removedGTO = &farosv1alpha1.GitTrackObject{}
+				Eventually(func() error {
+					return c.Get(context.TODO(), types.NamespacedName{Name: "configmap-deleted-config", Namespace: "default"}, removedGTO)
+				}, timeout).Should(Succeed())
+			})
+
+			Context("when the removed resources would be deleted", func() {
+				BeforeEach(func() {
+					updateRepository()
+				})
+
+				It("doesn't delete the removed resources", func() {
+					Consistently(func() error {
+						return c.Get(context.TODO(), types.NamespacedName{Name: "configmap-deleted-config", Namespace: "default"}, &farosv1alpha1.GitTrackObject{})
+					}, time.Second).Should(Succeed())
+				})
+
+				It("lists the kept resources in the status", func() {
+					Eventually(keptReason, timeout).Should(Equal(pruneThresholdReason))
+				})
+
+				It("sets the ChildrenGarbageCollected condition", func() {
+					Eventually(func() (string, error) {
+						err := c.Get(context.TODO(), key, instance)
+						if err != nil {
+							return "", err
+						}
+						for _, condition := range instance.Status.Conditions {
+							if condition.Type == farosv1alpha1.ChildrenGarbageCollectedType {
+								return condition.Reason, nil
+							}
+						}
+						return "", nil
+					}, timeout).Should(Equal(string(gittrackutils.PruneThresholdExceeded)))
+				})
+			})
+
+			Context("when pruning the removed resources is disabled by annotation", func() {
+				BeforeEach(func() {
+					removedGTO.SetAnnotations(map[string]string{gittrackutils.PruneAnnotation: gittrackutils.PruneDisabledValue})
+					Expect(c.Update(context.TODO(), removedGTO)).To(Succeed())
+					updateRepository()
+				})
+
+				It("doesn't count them towards the prune threshold", func() {
+					Eventually(keptReason, timeout).Should(Equal(pruneAnnotationReason))
+				})
+			})
+		})
+
 		Context("and resources in the repository are updated", func() {
 			BeforeEach(func() {
 				createInstance(instance, "a14443638218c782b84cae56a14f1090ee9e5c9c")
@@ -619,6 +1046,7 @@ var _ = Describe("GitTrack Suite", func() {
 					return nil
 				}, timeout).Should(Succeed())
 				Expect(after.Spec).ToNot(Equal(before.Spec))
+				Expect(after.GetAnnotations()).To(HaveKeyWithValue(utils.SourceCommitAnnotation, repeatedReference))
 			})
 
 			It("doesn't modify any other resources", func() {
@@ -643,6 +1071,9 @@ var _ = Describe("GitTrack Suite", func() {
 					return c.Get(context.TODO(), types.NamespacedName{Name: "service-nginx", Namespace: "default"}, after)
 				}, timeout).Should(Succeed())
 				Expect(after.Spec).To(Equal(before.Spec))
+				// The commit of unchanged resources isn't updated either
+				Expect(after.GetAnnotations()).To(HaveKeyWithValue(utils.SourceCommitAnnotation, "a14443638218c782b84cae56a14f1090ee9e5c9c"))
+				Expect(after.GetResourceVersion()).To(Equal(before.GetResourceVersion()))
 			})
 
 			It("sends events about updating resources", func() {
@@ -1154,7 +1585,7 @@ var getsFilesFromRepo = func(path string, count int) {
 			}
 			Eventually(requests, timeout).Should(Receive(Equal(req)))
 
-			files, err = reconciler.getFiles(gt)
+			files, _, err = reconciler.getFiles(gt, gt.Spec.Reference, false)
 			Expect(err).ToNot(HaveOccurred())
 		})
 
@@ -0,0 +1,82 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrack
+
+import (
+	"context"
+	"fmt"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	gittrackutils "github.com/pusher/faros/pkg/controller/gittrack/utils"
+	utils "github.com/pusher/faros/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// releasable returns whether the GitTrackObject has been marked as releasable
+// by its current owner, either by annotating the GitTrackObject itself or the
+// child within it
+func releasable(gto farosv1alpha1.GitTrackObjectInterface) bool {
+	if gittrackutils.Releasable(gto) {
+		return true
+	}
+	child, err := utils.YAMLToUnstructured(gto.GetSpec().Data)
+	if err != nil {
+		return false
+	}
+	return gittrackutils.Releasable(&child)
+}
+
+// adoptChild transfers the controller reference of the GitTrackObject to the
+// owner. The child keeps its owner reference to the GitTrackObject so it is
+// never deleted or recreated.
+//
+// The child itself is not updated here. Its controller reference names the
+// GitTrackObject, whose UID doesn't change, and everything else tying it to a
+// GitTrack (the GitTrack annotation, and the cluster, ServiceAccount and
+// policies it is managed with) is read by the GitTrackObject controller from
+// the controller reference and the data of the GitTrackObject. The data is
+// updated straight after adoption, which has the GitTrackObject controller
+// re-parent the child onto the owner as it applies it.
+func (r *ReconcileGitTrack) adoptChild(owner *farosv1alpha1.GitTrack, gto farosv1alpha1.GitTrackObjectInterface) error {
+	previous := metav1.GetControllerOf(gto)
+
+	ownerRefs := []metav1.OwnerReference{}
+	for _, ref := range gto.GetOwnerReferences() {
+		if ref.Controller == nil || !*ref.Controller {
+			ownerRefs = append(ownerRefs, ref)
+		}
+	}
+	gto.SetOwnerReferences(ownerRefs)
+	if err := controllerutil.SetControllerReference(owner, gto, r.scheme); err != nil {
+		return fmt.Errorf("unable to set controller reference: %v", err)
+	}
+
+	// Remove the releasable annotation so the child isn't handed over again
+	annotations := gto.GetAnnotations()
+	delete(annotations, gittrackutils.ReleasableAnnotation)
+	gto.SetAnnotations(annotations)
+
+	if err := r.Update(context.TODO(), gto); err != nil {
+		return fmt.Errorf("unable to update controller reference: %v", err)
+	}
+
+	if previous != nil {
+		r.log.V(0).Info("Child adopted", "child name", gto.GetNamespacedName(), "previous owner", previous.Name)
+	}
+	return nil
+}
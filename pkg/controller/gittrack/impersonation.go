@@ -0,0 +1,33 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrack
+
+import (
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	"github.com/pusher/faros/pkg/utils"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// childClientFor returns the client used to delete children of the GitTrack
+// directly, impersonating the ServiceAccount of the GitTrack if it sets one
+func (r *ReconcileGitTrack) childClientFor(gt *farosv1alpha1.GitTrack) (client.Client, error) {
+	if gt.Spec.ServiceAccountName == "" {
+		return r.Client, nil
+	}
+	config := utils.ImpersonateServiceAccount(r.config, gt.Namespace, gt.Spec.ServiceAccountName)
+	return client.New(config, client.Options{Scheme: r.scheme, Mapper: r.restMapper})
+}
@@ -47,6 +47,7 @@ func (r *ReconcileGitTrack) updateMetrics(gt *farosv1alpha1.GitTrack, opts *metr
 			"applied":    opts.status.applied,
 			"ignored":    opts.status.ignored,
 			"inSync":     opts.status.inSync,
+			"healthy":    opts.status.healthy,
 		},
 	)
 	if err != nil {
@@ -0,0 +1,173 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrack
+
+import (
+	"context"
+	"fmt"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	gittrackutils "github.com/pusher/faros/pkg/controller/gittrack/utils"
+	farosflags "github.com/pusher/faros/pkg/flags"
+	"github.com/pusher/faros/pkg/utils"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// prunePolicyReason is the reason children are kept when the GitTrack
+	// disables pruning
+	prunePolicyReason = "pruning is disabled for this GitTrack"
+
+	// pruneAnnotationReason is the reason children are kept when the prune
+	// annotation disables pruning
+	pruneAnnotationReason = fmt.Sprintf("pruning is disabled by the `%s` annotation, child orphaned", gittrackutils.PruneAnnotation)
+
+	// deleteProtectedReason is the reason children protected from deletion are
+	// kept
+	deleteProtectedReason = "child is protected from deletion, child orphaned"
+
+	// pruneThresholdReason is the reason children are kept when pruning them
+	// would exceed the prune thresholds
+	pruneThresholdReason = "pruning would exceed the prune threshold of this GitTrack"
+)
+
+// pruneThresholds returns the maximum number and percentage of children the
+// GitTrack may prune at once. Thresholds not set on the GitTrack default to
+// those set by flags.
+func pruneThresholds(gt *farosv1alpha1.GitTrack) (count, percent int64) {
+	count, percent = farosflags.PruneThresholdCount, farosflags.PruneThresholdPercent
+	if threshold := gt.Spec.PruneThreshold; threshold != nil {
+		if threshold.Count != nil {
+			count = *threshold.Count
+		}
+		if threshold.Percent != nil {
+			percent = *threshold.Percent
+		}
+	}
+	return
+}
+
+// CheckPruneThreshold returns an error if pruning the given number of children
+// would exceed the GitTrack's prune thresholds, unless the pruning has been
+// acknowledged for the revision being applied. Only children that would be
+// deleted, rather than kept as PreviewPrune reports, should be counted.
+func CheckPruneThreshold(gt *farosv1alpha1.GitTrack, owned, pruned int, revision string) error {
+	if pruned == 0 || (gt.Spec.Prune != nil && !*gt.Spec.Prune) {
+		return nil
+	}
+	if revision != "" && gt.GetAnnotations()[gittrackutils.PruneAcknowledgedAnnotation] == revision {
+		return nil
+	}
+
+	count, percent := pruneThresholds(gt)
+	exceeded := ""
+	if count > 0 && int64(pruned) > count {
+		exceeded = fmt.Sprintf("more than %d children", count)
+	} else if percent > 0 && owned > 0 && int64(pruned)*100 > percent*int64(owned) {
+		exceeded = fmt.Sprintf("more than %d%% of children", percent)
+	}
+	if exceeded == "" {
+		return nil
+	}
+
+	return fmt.Errorf("refusing to prune %d of %d children as this is %s, annotate the GitTrack with '%s: %s' to proceed",
+		pruned, owned, exceeded, gittrackutils.PruneAcknowledgedAnnotation, revision)
+}
+
+// PreviewPrune returns the reasons the leftover children of the GitTrack would
+// be kept rather than pruned, by name. The other leftovers would be pruned,
+// unless this exceeds the prune thresholds of the GitTrack.
+func PreviewPrune(c client.Reader, owner *farosv1alpha1.GitTrack, leftovers map[string]farosv1alpha1.GitTrackObjectInterface, protectedKinds map[schema.GroupKind]bool) (map[string]string, error) {
+	kept := make(map[string]string)
+	for name, obj := range leftovers {
+		if owner.Spec.Prune != nil && !*owner.Spec.Prune {
+			kept[name] = prunePolicyReason
+			continue
+		}
+
+		disabled, err := pruneDisabled(c, owner, obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check prune policy of child '%s': %v", name, err)
+		}
+		if disabled {
+			kept[name] = pruneAnnotationReason
+			continue
+		}
+
+		child, err := utils.YAMLToUnstructured(obj.GetSpec().Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal child for '%s': %v", name, err)
+		}
+		protected, err := utils.DeleteProtected(&child, protectedKinds)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check delete protection of child '%s': %v", name, err)
+		}
+		if protected {
+			kept[name] = deleteProtectedReason
+		}
+	}
+	return kept, nil
+}
+
+// keepLeftovers returns the reasons all leftover children are kept, using the
+// given reason for those that would otherwise have been pruned
+func keepLeftovers(leftovers map[string]farosv1alpha1.GitTrackObjectInterface, kept map[string]string, reason string) map[string]string {
+	all := make(map[string]string)
+	for name := range leftovers {
+		all[name] = reason
+		if keptReason, ok := kept[name]; ok {
+			all[name] = keptReason
+		}
+	}
+	return all
+}
+
+// pruneDisabled checks whether the `faros.pusher.com/prune` annotation
+// disables pruning on either the GitTrackObject, the child within it or the
+// child as it exists in the cluster. Children in remote clusters are only
+// checked within the GitTrackObject.
+func pruneDisabled(c client.Reader, owner *farosv1alpha1.GitTrack, gto farosv1alpha1.GitTrackObjectInterface) (bool, error) {
+	if gittrackutils.PruneDisabled(gto) {
+		return true, nil
+	}
+
+	child, err := utils.YAMLToUnstructured(gto.GetSpec().Data)
+	if err != nil {
+		return false, fmt.Errorf("unable to unmarshal data: %v", err)
+	}
+	if gittrackutils.PruneDisabled(&child) {
+		return true, nil
+	}
+	if owner.Spec.Cluster != nil {
+		return false, nil
+	}
+
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(child.GroupVersionKind())
+	err = c.Get(context.TODO(), types.NamespacedName{Name: child.GetName(), Namespace: child.GetNamespace()}, live)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("unable to get child: %v", err)
+	}
+	return gittrackutils.PruneDisabled(live), nil
+}
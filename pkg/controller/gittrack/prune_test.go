@@ -0,0 +1,166 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrack
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	gittrackutils "github.com/pusher/faros/pkg/controller/gittrack/utils"
+	farosflags "github.com/pusher/faros/pkg/flags"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("Prune threshold", func() {
+	const revision = "28928ccaeb314b96293e18cc8889997f0f46b79b"
+
+	var gt *farosv1alpha1.GitTrack
+	var count, percent int64
+
+	BeforeEach(func() {
+		count, percent = 5, 50
+		gt = &farosv1alpha1.GitTrack{
+			Spec: farosv1alpha1.GitTrackSpec{
+				PruneThreshold: &farosv1alpha1.GitTrackPruneThreshold{
+					Count:   &count,
+					Percent: &percent,
+				},
+			},
+		}
+	})
+
+	It("allows pruning below the thresholds", func() {
+		Expect(CheckPruneThreshold(gt, 20, 5, revision)).To(Succeed())
+	})
+
+	It("refuses pruning above the count threshold", func() {
+		Expect(CheckPruneThreshold(gt, 20, 6, revision)).NotTo(Succeed())
+	})
+
+	It("refuses pruning above the percentage threshold", func() {
+		Expect(CheckPruneThreshold(gt, 6, 4, revision)).NotTo(Succeed())
+	})
+
+	It("allows pruning once acknowledged for the revision", func() {
+		gt.SetAnnotations(map[string]string{gittrackutils.PruneAcknowledgedAnnotation: revision})
+		Expect(CheckPruneThreshold(gt, 20, 20, revision)).To(Succeed())
+	})
+
+	It("refuses pruning when acknowledged for another revision", func() {
+		gt.SetAnnotations(map[string]string{gittrackutils.PruneAcknowledgedAnnotation: "09d24c51c191b4caacd35cda23bd44c86f16edc6"})
+		Expect(CheckPruneThreshold(gt, 20, 20, revision)).NotTo(Succeed())
+	})
+
+	It("allows pruning above the thresholds when pruning is disabled", func() {
+		prune := false
+		gt.Spec.Prune = &prune
+		Expect(CheckPruneThreshold(gt, 20, 20, revision)).To(Succeed())
+	})
+
+	Context("without thresholds on the GitTrack", func() {
+		BeforeEach(func() {
+			gt.Spec.PruneThreshold = nil
+		})
+
+		AfterEach(func() {
+			farosflags.PruneThresholdCount = 0
+			farosflags.PruneThresholdPercent = 0
+		})
+
+		It("allows pruning when no thresholds are configured", func() {
+			Expect(CheckPruneThreshold(gt, 20, 20, revision)).To(Succeed())
+		})
+
+		It("uses the thresholds configured by flags", func() {
+			farosflags.PruneThresholdCount = 2
+			Expect(CheckPruneThreshold(gt, 20, 3, revision)).NotTo(Succeed())
+		})
+	})
+})
+
+var _ = Describe("PreviewPrune", func() {
+	var gt *farosv1alpha1.GitTrack
+	var leftovers map[string]farosv1alpha1.GitTrackObjectInterface
+	var protectedKinds map[schema.GroupKind]bool
+
+	newLeftover := func(kind, name string, annotations map[string]string) farosv1alpha1.GitTrackObjectInterface {
+		return &farosv1alpha1.GitTrackObject{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations},
+			Spec: farosv1alpha1.GitTrackObjectSpec{
+				Kind: kind,
+				Name: name,
+				Data: []byte(`{"apiVersion":"v1","kind":"` + kind + `","metadata":{"name":"` + name + `","namespace":"default"}}`),
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		// Children in remote clusters are checked without reading them from
+		// the cluster
+		gt = &farosv1alpha1.GitTrack{
+			Spec: farosv1alpha1.GitTrackSpec{Cluster: &farosv1alpha1.GitTrackCluster{SecretName: "remote"}},
+		}
+		leftovers = map[string]farosv1alpha1.GitTrackObjectInterface{
+			"default/configmap-example": newLeftover("ConfigMap", "example", nil),
+		}
+		protectedKinds = map[schema.GroupKind]bool{{Kind: "PersistentVolumeClaim"}: true}
+	})
+
+	It("prunes leftovers by default", func() {
+		kept, err := PreviewPrune(nil, gt, leftovers, protectedKinds)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kept).To(BeEmpty())
+	})
+
+	It("keeps leftovers when the GitTrack disables pruning", func() {
+		prune := false
+		gt.Spec.Prune = &prune
+		kept, err := PreviewPrune(nil, gt, leftovers, protectedKinds)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kept).To(HaveKeyWithValue("default/configmap-example", prunePolicyReason))
+	})
+
+	It("keeps leftovers whose prune annotation disables pruning", func() {
+		leftovers["default/configmap-example"] = newLeftover("ConfigMap", "example", map[string]string{gittrackutils.PruneAnnotation: gittrackutils.PruneDisabledValue})
+		kept, err := PreviewPrune(nil, gt, leftovers, protectedKinds)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kept).To(HaveKeyWithValue("default/configmap-example", pruneAnnotationReason))
+	})
+
+	It("keeps leftovers protected from deletion", func() {
+		leftovers["default/persistentvolumeclaim-data"] = newLeftover("PersistentVolumeClaim", "data", nil)
+		kept, err := PreviewPrune(nil, gt, leftovers, protectedKinds)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kept).To(HaveLen(1))
+		Expect(kept).To(HaveKeyWithValue("default/persistentvolumeclaim-data", deleteProtectedReason))
+	})
+})
+
+var _ = Describe("keepLeftovers", func() {
+	It("keeps the leftovers that would be pruned for the given reason", func() {
+		leftovers := map[string]farosv1alpha1.GitTrackObjectInterface{
+			"default/configmap-pruned":    &farosv1alpha1.GitTrackObject{},
+			"default/configmap-protected": &farosv1alpha1.GitTrackObject{},
+		}
+		kept := map[string]string{"default/configmap-protected": deleteProtectedReason}
+		Expect(keepLeftovers(leftovers, kept, pruneThresholdReason)).To(Equal(map[string]string{
+			"default/configmap-pruned":    pruneThresholdReason,
+			"default/configmap-protected": deleteProtectedReason,
+		}))
+	})
+})
@@ -0,0 +1,132 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrack
+
+import (
+	"fmt"
+	"time"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	gittrackutils "github.com/pusher/faros/pkg/controller/gittrack/utils"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// defaultProgressDeadline is how long children may remain unhealthy after a
+// new revision is applied when the rollback policy doesn't specify a deadline
+const defaultProgressDeadline = 10 * time.Minute
+
+// progressDeadline returns the progress deadline of the rollback policy
+func progressDeadline(policy *farosv1alpha1.GitTrackRollback) time.Duration {
+	if policy == nil || policy.ProgressDeadlineSeconds == nil {
+		return defaultProgressDeadline
+	}
+	return time.Duration(*policy.ProgressDeadlineSeconds) * time.Second
+}
+
+// targetRevision determines which revision should be applied given the
+// revision the GitTrack's reference currently resolves to.
+//
+// If the revision was previously rolled back, the last healthy revision is
+// applied instead until a newer commit appears.
+func targetRevision(gt *farosv1alpha1.GitTrack, revision string) string {
+	status := gt.Status
+	if gt.Spec.Rollback != nil && status.RolledBackRevision == revision && status.LastHealthyRevision != "" {
+		return status.LastHealthyRevision
+	}
+	return revision
+}
+
+// checkoutMessage describes the revision checked out for the GitTrack, which is
+// the last healthy revision rather than its reference after a roll back
+func checkoutMessage(gt *farosv1alpha1.GitTrack, revision, target string) string {
+	if target != revision {
+		return fmt.Sprintf("Successfully checked out '%s' at last healthy revision '%s' as '%s' was rolled back", gt.Spec.Repository, target, revision)
+	}
+	return fmt.Sprintf("Successfully checked out '%s' at '%s'", gt.Spec.Repository, gt.Spec.Reference)
+}
+
+// revisionsFrom carries the revisions recorded in the status of the GitTrack
+// over to the status options
+func (opts *statusOpts) revisionsFrom(status farosv1alpha1.GitTrackStatus) {
+	opts.revision = status.Revision
+	opts.appliedRevision = status.AppliedRevision
+	opts.appliedTime = status.AppliedTime
+	opts.lastHealthyRevision = status.LastHealthyRevision
+	opts.rolledBackRevision = status.RolledBackRevision
+}
+
+// setRevisions records the revision of the reference and the revision that is
+// about to be applied in the status options
+func setRevisions(gt *farosv1alpha1.GitTrack, opts *statusOpts, revision, target string) {
+	opts.revision = revision
+	if gt.Status.AppliedRevision != target || gt.Status.AppliedTime == nil {
+		now := metav1.Now()
+		opts.appliedTime = &now
+	}
+	opts.appliedRevision = target
+	if target == revision {
+		// The reference has moved on from any revision that was rolled back
+		opts.rolledBackRevision = ""
+	}
+}
+
+// handleRollback rolls back the applied revision if its children haven't
+// become healthy within the progress deadline of the GitTrack's rollback
+// policy.
+//
+// It should be called once the children of the GitTrack have been handled.
+func (r *ReconcileGitTrack) handleRollback(gt *farosv1alpha1.GitTrack, opts *statusOpts, allHealthy bool) reconcile.Result {
+	// The children only report the health of a revision once the
+	// GitTrackObject controller has applied it, so a revision is never
+	// recorded as healthy by the reconcile that applies it
+	if allHealthy && gt.Status.AppliedRevision == opts.appliedRevision {
+		opts.lastHealthyRevision = opts.appliedRevision
+	}
+	if gt.Spec.Rollback == nil {
+		return reconcile.Result{}
+	}
+
+	if opts.rolledBackRevision != "" {
+		opts.rollbackReason = gittrackutils.ChildrenUnhealthy
+		opts.rolledBack = true
+		opts.rollbackMessage = fmt.Sprintf("rolled back from '%s' to '%s'", opts.rolledBackRevision, opts.appliedRevision)
+		return reconcile.Result{}
+	}
+
+	opts.rollbackReason = gittrackutils.RollbackNotRequired
+	if allHealthy || opts.lastHealthyRevision == "" || opts.lastHealthyRevision == opts.appliedRevision {
+		return reconcile.Result{}
+	}
+
+	deadline := opts.appliedTime.Add(progressDeadline(gt.Spec.Rollback))
+	if remaining := time.Until(deadline); remaining > 0 {
+		// Check the health of the children again once the deadline has passed
+		return reconcile.Result{RequeueAfter: remaining}
+	}
+
+	r.log.V(0).Info("Rolling back revision", "revision", opts.appliedRevision, "last healthy revision", opts.lastHealthyRevision)
+	r.recorder.Eventf(gt, apiv1.EventTypeWarning, "RolledBack", "Children not healthy after %s, rolled back from '%s' to '%s'", progressDeadline(gt.Spec.Rollback), opts.appliedRevision, opts.lastHealthyRevision)
+	opts.rollbackReason = gittrackutils.ChildrenUnhealthy
+	opts.rolledBack = true
+	opts.rollbackMessage = fmt.Sprintf("rolled back from '%s' to '%s'", opts.appliedRevision, opts.lastHealthyRevision)
+	opts.rolledBackRevision = opts.appliedRevision
+
+	// Requeue so that the last healthy revision is applied
+	return reconcile.Result{Requeue: true}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrack
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	gittrackutils "github.com/pusher/faros/pkg/controller/gittrack/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	rlogr "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var _ = Describe("Rollback", func() {
+	const (
+		healthyRevision   = "28928ccaeb314b96293e18cc8889997f0f46b79b"
+		unhealthyRevision = "09d24c51c191b4caacd35cda23bd44c86f16edc6"
+		newerRevision     = "4532b487a5aaf651839f5401371556aa16732a6e"
+	)
+
+	var gt *farosv1alpha1.GitTrack
+	var opts *statusOpts
+	var recorder *record.FakeRecorder
+	var r *ReconcileGitTrack
+
+	BeforeEach(func() {
+		deadline := int64(60)
+		gt = &farosv1alpha1.GitTrack{
+			Spec: farosv1alpha1.GitTrackSpec{
+				Rollback: &farosv1alpha1.GitTrackRollback{
+					ProgressDeadlineSeconds: &deadline,
+				},
+			},
+			Status: farosv1alpha1.GitTrackStatus{
+				LastHealthyRevision: healthyRevision,
+			},
+		}
+		opts = newStatusOpts()
+		recorder = record.NewFakeRecorder(10)
+		r = &ReconcileGitTrack{recorder: recorder, log: rlogr.Log}
+	})
+
+	Context("targetRevision", func() {
+		It("returns the revision if it has not been rolled back", func() {
+			Expect(targetRevision(gt, unhealthyRevision)).To(Equal(unhealthyRevision))
+		})
+
+		It("returns the last healthy revision if the revision was rolled back", func() {
+			gt.Status.RolledBackRevision = unhealthyRevision
+			Expect(targetRevision(gt, unhealthyRevision)).To(Equal(healthyRevision))
+		})
+
+		It("returns a newer revision after a roll back", func() {
+			gt.Status.RolledBackRevision = unhealthyRevision
+			Expect(targetRevision(gt, newerRevision)).To(Equal(newerRevision))
+		})
+
+		It("returns the revision if rollback is disabled", func() {
+			gt.Spec.Rollback = nil
+			gt.Status.RolledBackRevision = unhealthyRevision
+			Expect(targetRevision(gt, unhealthyRevision)).To(Equal(unhealthyRevision))
+		})
+	})
+
+	Context("checkoutMessage", func() {
+		BeforeEach(func() {
+			gt.Spec.Repository = "https://github.com/pusher/faros"
+			gt.Spec.Reference = "master"
+		})
+
+		It("reports the reference if the revision has not been rolled back", func() {
+			Expect(checkoutMessage(gt, newerRevision, newerRevision)).To(Equal("Successfully checked out 'https://github.com/pusher/faros' at 'master'"))
+		})
+
+		It("reports the last healthy revision if the revision was rolled back", func() {
+			message := checkoutMessage(gt, unhealthyRevision, healthyRevision)
+			Expect(message).To(ContainSubstring(healthyRevision))
+			Expect(message).NotTo(ContainSubstring("'master'"))
+		})
+	})
+
+	Context("handleRollback", func() {
+		BeforeEach(func() {
+			opts.revisionsFrom(gt.Status)
+		})
+
+		Context("when the children are healthy", func() {
+			BeforeEach(func() {
+				gt.Status.AppliedRevision = unhealthyRevision
+				setRevisions(gt, opts, unhealthyRevision, unhealthyRevision)
+			})
+
+			It("records the last healthy revision", func() {
+				r.handleRollback(gt, opts, true)
+				Expect(opts.lastHealthyRevision).To(Equal(unhealthyRevision))
+				Expect(opts.rolledBack).To(BeFalse())
+				Expect(opts.rollbackReason).To(Equal(gittrackutils.RollbackNotRequired))
+			})
+		})
+
+		Context("when the children are healthy as the revision is applied", func() {
+			BeforeEach(func() {
+				setRevisions(gt, opts, unhealthyRevision, unhealthyRevision)
+			})
+
+			It("doesn't record the revision as healthy", func() {
+				r.handleRollback(gt, opts, true)
+				Expect(opts.lastHealthyRevision).To(Equal(healthyRevision))
+				Expect(opts.rolledBack).To(BeFalse())
+			})
+		})
+
+		Context("when the children are unhealthy within the deadline", func() {
+			BeforeEach(func() {
+				setRevisions(gt, opts, unhealthyRevision, unhealthyRevision)
+			})
+
+			It("requeues once the deadline has passed", func() {
+				result := r.handleRollback(gt, opts, false)
+				Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+				Expect(result.RequeueAfter).To(BeNumerically("<=", time.Minute))
+				Expect(opts.rolledBack).To(BeFalse())
+				Expect(opts.rolledBackRevision).To(BeEmpty())
+			})
+		})
+
+		Context("when the children are unhealthy after the deadline", func() {
+			BeforeEach(func() {
+				appliedTime := metav1.NewTime(time.Now().Add(-2 * time.Minute))
+				gt.Status.AppliedRevision = unhealthyRevision
+				gt.Status.AppliedTime = &appliedTime
+				opts.revisionsFrom(gt.Status)
+				setRevisions(gt, opts, unhealthyRevision, unhealthyRevision)
+			})
+
+			It("rolls back to the last healthy revision", func() {
+				result := r.handleRollback(gt, opts, false)
+				Expect(result.Requeue).To(BeTrue())
+				Expect(opts.rolledBackRevision).To(Equal(unhealthyRevision))
+				Expect(opts.lastHealthyRevision).To(Equal(healthyRevision))
+				Expect(opts.rolledBack).To(BeTrue())
+				Expect(opts.rollbackReason).To(Equal(gittrackutils.ChildrenUnhealthy))
+				Expect(opts.rollbackMessage).To(ContainSubstring(unhealthyRevision))
+				Expect(opts.rollbackMessage).To(ContainSubstring(healthyRevision))
+			})
+
+			It("sends a RolledBack event", func() {
+				r.handleRollback(gt, opts, false)
+				Expect(recorder.Events).To(Receive(ContainSubstring("RolledBack")))
+			})
+
+			It("does nothing if rollback is disabled", func() {
+				gt.Spec.Rollback = nil
+				result := r.handleRollback(gt, opts, false)
+				Expect(result.Requeue).To(BeFalse())
+				Expect(opts.rolledBackRevision).To(BeEmpty())
+				Expect(opts.rollbackReason).To(BeEmpty())
+			})
+		})
+
+		Context("when the revision has been rolled back", func() {
+			BeforeEach(func() {
+				gt.Status.RolledBackRevision = unhealthyRevision
+				opts.revisionsFrom(gt.Status)
+				setRevisions(gt, opts, unhealthyRevision, targetRevision(gt, unhealthyRevision))
+			})
+
+			It("keeps the RolledBack condition", func() {
+				r.handleRollback(gt, opts, true)
+				Expect(opts.appliedRevision).To(Equal(healthyRevision))
+				Expect(opts.rolledBackRevision).To(Equal(unhealthyRevision))
+				Expect(opts.rolledBack).To(BeTrue())
+			})
+
+			It("clears the rolled back revision once a newer commit appears", func() {
+				setRevisions(gt, opts, newerRevision, targetRevision(gt, newerRevision))
+				r.handleRollback(gt, opts, false)
+				Expect(opts.appliedRevision).To(Equal(newerRevision))
+				Expect(opts.rolledBackRevision).To(BeEmpty())
+				Expect(opts.rolledBack).To(BeFalse())
+			})
+		})
+	})
+})
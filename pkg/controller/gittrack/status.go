@@ -24,6 +24,7 @@ import (
 	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
 	gittrackutils "github.com/pusher/faros/pkg/controller/gittrack/utils"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type statusOpts struct {
@@ -31,6 +32,7 @@ type statusOpts struct {
 	discovered     int64
 	ignored        int64
 	inSync         int64
+	healthy        int64
 	parseError     error
 	parseReason    gittrackutils.ConditionReason
 	gitError       error
@@ -40,6 +42,18 @@ type statusOpts struct {
 	upToDateError  error
 	upToDateReason gittrackutils.ConditionReason
 	ignoredFiles   map[string]string
+	keptObjects    map[string]string
+
+	revision            string
+	appliedRevision     string
+	appliedTime         *metav1.Time
+	lastHealthyRevision string
+	rolledBackRevision  string
+	rolledBack          bool
+	rollbackReason      gittrackutils.ConditionReason
+	rollbackMessage     string
+
+	lastHandledReconcileAt string
 }
 
 func newStatusOpts() *statusOpts {
@@ -51,6 +65,12 @@ func newStatusOpts() *statusOpts {
 	}
 }
 
+// failed returns whether fetching, parsing, updating or garbage collecting
+// the children of the GitTrack failed
+func (s *statusOpts) failed() bool {
+	return s.gitError != nil || s.parseError != nil || s.upToDateError != nil || s.gcError != nil
+}
+
 func updateGitTrackStatus(gt *farosv1alpha1.GitTrack, opts *statusOpts) (updated bool) {
 	if gt == nil {
 		return
@@ -62,11 +82,22 @@ func updateGitTrackStatus(gt *farosv1alpha1.GitTrack, opts *statusOpts) (updated
 	status.ObjectsDiscovered = opts.discovered
 	status.ObjectsIgnored = opts.ignored
 	status.ObjectsInSync = opts.inSync
+	status.ObjectsHealthy = opts.healthy
 	status.IgnoredFiles = opts.ignoredFiles
+	status.KeptObjects = opts.keptObjects
+	status.Revision = opts.revision
+	status.AppliedRevision = opts.appliedRevision
+	status.AppliedTime = opts.appliedTime
+	status.LastHealthyRevision = opts.lastHealthyRevision
+	status.RolledBackRevision = opts.rolledBackRevision
+	status.LastHandledReconcileAt = opts.lastHandledReconcileAt
 	setCondition(&status, farosv1alpha1.FilesParsedType, opts.parseError, opts.parseReason)
 	setCondition(&status, farosv1alpha1.FilesFetchedType, opts.gitError, opts.gitReason)
 	setCondition(&status, farosv1alpha1.ChildrenGarbageCollectedType, opts.gcError, opts.gcReason)
 	setCondition(&status, farosv1alpha1.ChildrenUpToDateType, opts.upToDateError, opts.upToDateReason)
+	if opts.rollbackReason != "" {
+		setRollbackCondition(&status, opts.rolledBack, opts.rollbackReason, opts.rollbackMessage)
+	}
 
 	if !reflect.DeepEqual(gt.Status, status) {
 		gt.Status = status
@@ -98,6 +129,22 @@ func setCondition(status *farosv1alpha1.GitTrackStatus, condType farosv1alpha1.G
 	gittrackutils.SetGitTrackCondition(status, *cond)
 }
 
+// setRollbackCondition sets the RolledBack condition, which is true when the
+// GitTrack has been rolled back
+func setRollbackCondition(status *farosv1alpha1.GitTrackStatus, rolledBack bool, reason gittrackutils.ConditionReason, message string) {
+	condStatus := v1.ConditionFalse
+	if rolledBack {
+		condStatus = v1.ConditionTrue
+	}
+	cond := gittrackutils.NewGitTrackCondition(
+		farosv1alpha1.RolledBackType,
+		condStatus,
+		reason,
+		message,
+	)
+	gittrackutils.SetGitTrackCondition(status, *cond)
+}
+
 // updateStatus calculates a new status for the GitTrack and then updates
 // the resource on the API if the status differs from before.
 func (r *ReconcileGitTrack) updateStatus(original *farosv1alpha1.GitTrack, opts *statusOpts) error {
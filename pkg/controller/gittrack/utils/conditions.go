@@ -50,6 +50,10 @@ const (
 	// updating the child objects
 	ChildrenUpdateSuccess ConditionReason = "ChildUpdateSuccess"
 
+	// ErrorConnectingToCluster represents the condition reason when an error
+	// occurs connecting to the remote cluster the children are deployed to
+	ErrorConnectingToCluster ConditionReason = "ErrorConnectingToCluster"
+
 	// ErrorDeletingChildren represents the condition reason when an error occurs
 	// removing orphaned children
 	ErrorDeletingChildren ConditionReason = "ErrorDeletingChildren"
@@ -57,6 +61,18 @@ const (
 	// GCSuccess represents the condition reason when no error occurs
 	// removing orphaned children
 	GCSuccess ConditionReason = "GCSuccess"
+
+	// PruneThresholdExceeded represents the condition reason when removing
+	// orphaned children is refused because too many children would be removed
+	PruneThresholdExceeded ConditionReason = "PruneThresholdExceeded"
+
+	// RollbackNotRequired represents the condition reason when the applied
+	// revision has not been rolled back
+	RollbackNotRequired ConditionReason = "RollbackNotRequired"
+
+	// ChildrenUnhealthy represents the condition reason when the revision
+	// has been rolled back because its children did not become healthy
+	ChildrenUnhealthy ConditionReason = "ChildrenUnhealthy"
 )
 
 // ConditionReason represents a valid condition reason
@@ -0,0 +1,31 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReleasableAnnotation is the annotation used to mark a child as releasable
+// so that another GitTrack may adopt it
+const ReleasableAnnotation = "faros.pusher.com/releasable"
+
+// Releasable returns whether the `faros.pusher.com/releasable` annotation on
+// the object marks it as releasable
+func Releasable(obj metav1.Object) bool {
+	return obj.GetAnnotations()[ReleasableAnnotation] == "true"
+}
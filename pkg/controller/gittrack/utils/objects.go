@@ -0,0 +1,170 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	"github.com/pusher/faros/pkg/utils"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// FilesGlob returns the glob matching the files loaded from the given subpath
+// of a repository
+func FilesGlob(subPath string) string {
+	if !strings.HasSuffix(subPath, "/") {
+		subPath += "/"
+	}
+	return strings.TrimPrefix(subPath, "/") + "{**/*,*}.{yaml,yml,json}"
+}
+
+// ObjectsFrom iterates through the contents of the files given by path and
+// attempts to create Unstructured objects, annotated with the file and document
+// they were parsed from, returning the reason each file that couldn't be parsed
+// was ignored
+func ObjectsFrom(files map[string]string) ([]*unstructured.Unstructured, map[string]string) {
+	// Sort the paths so that the objects are always returned in the same order
+	paths := []string{}
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	objects := []*unstructured.Unstructured{}
+	fileErrors := make(map[string]string)
+	for _, path := range paths {
+		// TODO (@JoelSpeed): What happens if there are multiple resources in one file,
+		// but one of them is invalid? Can we still get the rest?
+		us, err := utils.YAMLToUnstructuredSlice([]byte(files[path]))
+		if err != nil {
+			fileErrors[path] = fmt.Sprintf("unable to parse '%s': %v\n", path, err)
+			continue
+		}
+		for i, u := range us {
+			utils.SetSource(u, path, i+1)
+		}
+		objects = append(objects, us...)
+	}
+	return objects, fileErrors
+}
+
+// ObjectName constructs the name of the GitTrackObject for an Unstructured
+// object
+func ObjectName(u *unstructured.Unstructured) string {
+	return strings.ToLower(fmt.Sprintf("%s-%s", u.GetKind(), strings.Replace(u.GetName(), ":", "-", -1)))
+}
+
+// ObjectNamespacedName constructs the namespaced name of the GitTrackObject
+// for an Unstructured object
+func ObjectNamespacedName(u *unstructured.Unstructured) string {
+	return strings.TrimLeft(fmt.Sprintf("%s/%s", u.GetNamespace(), ObjectName(u)), "/")
+}
+
+// NewGitTrackObject creates the GitTrackObject, or the ClusterGitTrackObject
+// if the object isn't namespaced, tracking an Unstructured object. The
+// annotations recording where the object was produced from are copied to it.
+func NewGitTrackObject(u *unstructured.Unstructured, namespaced bool) (farosv1alpha1.GitTrackObjectInterface, error) {
+	var instance farosv1alpha1.GitTrackObjectInterface
+	if namespaced {
+		instance = &farosv1alpha1.GitTrackObject{
+			TypeMeta: farosv1alpha1.GitTrackObjectTypeMeta,
+		}
+	} else {
+		instance = &farosv1alpha1.ClusterGitTrackObject{
+			TypeMeta: farosv1alpha1.ClusterGitTrackObjectTypeMeta,
+		}
+	}
+	instance.SetName(ObjectName(u))
+	instance.SetNamespace(u.GetNamespace())
+	utils.CopyProvenance(u, instance)
+
+	data, err := u.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling JSON: %v", err)
+	}
+
+	instance.SetSpec(farosv1alpha1.GitTrackObjectSpec{
+		Name: u.GetName(),
+		Kind: u.GetKind(),
+		Data: data,
+	})
+	return instance, nil
+}
+
+// RecordSourceCommit records the commit the GitTrackObject was produced from
+// in its annotations. If the existing GitTrackObject, which may be nil, has the
+// same data, its commit is kept so that new commits which don't change the
+// object don't update it or its child.
+func RecordSourceCommit(gto, existing farosv1alpha1.GitTrackObjectInterface, commit string) {
+	if existing != nil && bytes.Equal(existing.GetSpec().Data, gto.GetSpec().Data) {
+		if existingCommit := existing.GetAnnotations()[utils.SourceCommitAnnotation]; existingCommit != "" {
+			commit = existingCommit
+		}
+	}
+	utils.SetSourceCommit(gto, commit)
+}
+
+// IgnoreObject checks whether an object of the given resource should be
+// ignored by a Faros managing the given namespace and ignoring the given
+// resources, returning the reason it is ignored
+func IgnoreObject(u *unstructured.Unstructured, gvr schema.GroupVersionResource, namespaced bool, namespace string, ignoredGVRs map[schema.GroupVersionResource]interface{}) (bool, string) {
+	// Ignore namespaced objects not in the namespace managed by the controller
+	if namespaced && namespace != "" && namespace != u.GetNamespace() {
+		return true, fmt.Sprintf("namespace `%s` is not managed by this Faros", u.GetNamespace())
+	}
+	// Ignore GVKs in the ignoredGVKs set
+	if _, ok := ignoredGVRs[gvr]; ok {
+		return true, fmt.Sprintf("resource `%s.%s/%s` ignored globally by flag", gvr.Resource, gvr.Group, gvr.Version)
+	}
+	return false, ""
+}
+
+// IgnoredObjectKey returns the key under which an ignored object is recorded
+// in the ignored files of a GitTrack: the file, and the object within the
+// file, that it was produced from, or the namespaced name of its
+// GitTrackObject if that isn't recorded
+func IgnoredObjectKey(u *unstructured.Unstructured) string {
+	if file := utils.SourceFile(u); file != "" {
+		return file
+	}
+	return ObjectNamespacedName(u)
+}
+
+// NameCollisions finds the objects whose GitTrackObject would have the same
+// namespaced name as an object earlier in the slice, returning the reason
+// each of them is ignored
+func NameCollisions(objects []*unstructured.Unstructured) map[*unstructured.Unstructured]string {
+	collisions := make(map[*unstructured.Unstructured]string)
+	seen := make(map[string]*unstructured.Unstructured)
+	for _, u := range objects {
+		name := ObjectNamespacedName(u)
+		first, ok := seen[name]
+		if !ok {
+			seen[name] = u
+			continue
+		}
+		collisions[u] = fmt.Sprintf("%s `%s` in %s has the same name as %s `%s` in %s, which is tracked instead",
+			u.GroupVersionKind().GroupKind().String(), u.GetName(), utils.DescribeSource(u),
+			first.GroupVersionKind().GroupKind().String(), first.GetName(), utils.DescribeSource(first))
+	}
+	return collisions
+}
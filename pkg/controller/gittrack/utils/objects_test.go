@@ -0,0 +1,236 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	. "github.com/pusher/faros/pkg/controller/gittrack/utils"
+	"github.com/pusher/faros/pkg/utils"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: example
+  namespace: default
+`
+
+var clusterRole = `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: system:example
+`
+
+var _ = Describe("Objects", func() {
+	Context("FilesGlob", func() {
+		It("matches manifests under the root of the repository", func() {
+			Expect(FilesGlob("")).To(Equal("{**/*,*}.{yaml,yml,json}"))
+			Expect(FilesGlob("/")).To(Equal("{**/*,*}.{yaml,yml,json}"))
+		})
+
+		It("matches manifests under the subpath", func() {
+			Expect(FilesGlob("/foo")).To(Equal("foo/{**/*,*}.{yaml,yml,json}"))
+			Expect(FilesGlob("foo/")).To(Equal("foo/{**/*,*}.{yaml,yml,json}"))
+		})
+	})
+
+	Context("ObjectsFrom", func() {
+		It("parses the objects of every file in order of path", func() {
+			objects, fileErrors := ObjectsFrom(map[string]string{
+				"b/role.yaml":       clusterRole,
+				"a/deployment.yaml": deployment,
+			})
+			Expect(fileErrors).To(BeEmpty())
+			Expect(objects).To(HaveLen(2))
+			Expect(objects[0].GetKind()).To(Equal("Deployment"))
+			Expect(objects[1].GetKind()).To(Equal("ClusterRole"))
+		})
+
+		It("annotates objects with the file and document they were parsed from", func() {
+			objects, _ := ObjectsFrom(map[string]string{
+				"config/all.yaml": deployment + "---\n" + clusterRole,
+			})
+			Expect(objects).To(HaveLen(2))
+			Expect(objects[0].GetAnnotations()).To(HaveKeyWithValue(utils.SourcePathAnnotation, "config/all.yaml"))
+			Expect(objects[0].GetAnnotations()).To(HaveKeyWithValue(utils.SourceDocumentAnnotation, "1"))
+			Expect(objects[1].GetAnnotations()).To(HaveKeyWithValue(utils.SourcePathAnnotation, "config/all.yaml"))
+			Expect(objects[1].GetAnnotations()).To(HaveKeyWithValue(utils.SourceDocumentAnnotation, "2"))
+		})
+
+		It("returns the reason unparseable files are ignored", func() {
+			objects, fileErrors := ObjectsFrom(map[string]string{
+				"deployment.yaml": deployment,
+				"invalid.yaml":    "not: [valid",
+			})
+			Expect(objects).To(HaveLen(1))
+			Expect(fileErrors).To(HaveKeyWithValue("invalid.yaml", ContainSubstring("unable to parse 'invalid.yaml'")))
+		})
+	})
+
+	Context("naming", func() {
+		var u *unstructured.Unstructured
+
+		BeforeEach(func() {
+			objects, _ := ObjectsFrom(map[string]string{"role.yaml": clusterRole})
+			u = objects[0]
+		})
+
+		It("names children after the kind and name of the object", func() {
+			Expect(ObjectName(u)).To(Equal("clusterrole-system-example"))
+		})
+
+		It("omits the namespace of cluster scoped objects", func() {
+			Expect(ObjectNamespacedName(u)).To(Equal("clusterrole-system-example"))
+		})
+	})
+
+	Context("NewGitTrackObject", func() {
+		var u *unstructured.Unstructured
+
+		BeforeEach(func() {
+			objects, _ := ObjectsFrom(map[string]string{"deployment.yaml": deployment})
+			u = objects[0]
+		})
+
+		It("creates a GitTrackObject for namespaced objects", func() {
+			gto, err := NewGitTrackObject(u, true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gto).To(BeAssignableToTypeOf(&farosv1alpha1.GitTrackObject{}))
+			Expect(gto.GetNamespacedName()).To(Equal("default/deployment-example"))
+			Expect(gto.GetSpec().Name).To(Equal("example"))
+			Expect(gto.GetSpec().Kind).To(Equal("Deployment"))
+		})
+
+		It("copies the provenance of the object", func() {
+			utils.SetGitTrack(u, "default/example")
+			gto, err := NewGitTrackObject(u, true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gto.GetAnnotations()).To(Equal(map[string]string{
+				utils.SourcePathAnnotation:     "deployment.yaml",
+				utils.SourceDocumentAnnotation: "1",
+				utils.GitTrackAnnotation:       "default/example",
+			}))
+		})
+
+		It("creates a ClusterGitTrackObject for cluster scoped objects", func() {
+			gto, err := NewGitTrackObject(u, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gto).To(BeAssignableToTypeOf(&farosv1alpha1.ClusterGitTrackObject{}))
+		})
+	})
+
+	Context("RecordSourceCommit", func() {
+		const oldCommit = "a14443638218c782b84cae56a14f1090ee9e5c9c"
+		const newCommit = "448b39a21d285fcb5aa4b718b27a3e13ffc649b3"
+		var gto, existing farosv1alpha1.GitTrackObjectInterface
+
+		BeforeEach(func() {
+			objects, _ := ObjectsFrom(map[string]string{"deployment.yaml": deployment})
+			Expect(objects).To(HaveLen(1))
+			var err error
+			gto, err = NewGitTrackObject(objects[0], true)
+			Expect(err).ToNot(HaveOccurred())
+			existing = gto.DeepCopyInterface()
+			utils.SetSourceCommit(existing, oldCommit)
+		})
+
+		It("records the commit of a new GitTrackObject", func() {
+			RecordSourceCommit(gto, nil, newCommit)
+			Expect(gto.GetAnnotations()).To(HaveKeyWithValue(utils.SourceCommitAnnotation, newCommit))
+		})
+
+		It("keeps the commit of an unchanged GitTrackObject", func() {
+			RecordSourceCommit(gto, existing, newCommit)
+			Expect(gto.GetAnnotations()).To(HaveKeyWithValue(utils.SourceCommitAnnotation, oldCommit))
+		})
+
+		It("records the commit of a changed GitTrackObject", func() {
+			spec := existing.GetSpec()
+			spec.Data = []byte("{}")
+			existing.SetSpec(spec)
+			RecordSourceCommit(gto, existing, newCommit)
+			Expect(gto.GetAnnotations()).To(HaveKeyWithValue(utils.SourceCommitAnnotation, newCommit))
+		})
+	})
+
+	Context("IgnoreObject", func() {
+		var u *unstructured.Unstructured
+		var gvr = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+		BeforeEach(func() {
+			objects, _ := ObjectsFrom(map[string]string{"deployment.yaml": deployment})
+			u = objects[0]
+		})
+
+		It("keeps objects in the managed namespace", func() {
+			ignored, _ := IgnoreObject(u, gvr, true, "default", nil)
+			Expect(ignored).To(BeFalse())
+		})
+
+		It("ignores objects outside of the managed namespace", func() {
+			ignored, reason := IgnoreObject(u, gvr, true, "other", nil)
+			Expect(ignored).To(BeTrue())
+			Expect(reason).To(Equal("namespace `default` is not managed by this Faros"))
+		})
+
+		It("ignores resources ignored by flag", func() {
+			ignored, reason := IgnoreObject(u, gvr, true, "", map[schema.GroupVersionResource]interface{}{gvr: nil})
+			Expect(ignored).To(BeTrue())
+			Expect(reason).To(Equal("resource `deployments.apps/v1` ignored globally by flag"))
+		})
+	})
+
+	Context("NameCollisions", func() {
+		It("doesn't report objects with distinct names", func() {
+			objects, _ := ObjectsFrom(map[string]string{"deployment.yaml": deployment, "role.yaml": clusterRole})
+			Expect(NameCollisions(objects)).To(BeEmpty())
+		})
+
+		It("reports objects taking the name of an earlier object", func() {
+			objects, _ := ObjectsFrom(map[string]string{
+				"a.yaml": deployment,
+				"b.yaml": `apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: example
+  namespace: default
+`,
+			})
+			collisions := NameCollisions(objects)
+			Expect(collisions).To(HaveLen(1))
+			Expect(collisions).To(HaveKeyWithValue(objects[1], "Deployment.extensions `example` in b.yaml has the same name as Deployment.apps `example` in a.yaml, which is tracked instead"))
+		})
+	})
+
+	Context("IgnoredObjectKey", func() {
+		It("returns the file and document the object was parsed from", func() {
+			objects, _ := ObjectsFrom(map[string]string{"deployment.yaml": deployment + "---\n" + deployment})
+			Expect(IgnoredObjectKey(objects[0])).To(Equal("deployment.yaml"))
+			Expect(IgnoredObjectKey(objects[1])).To(Equal("deployment.yaml (document 2)"))
+		})
+
+		It("returns the namespaced name of the GitTrackObject without a recorded file", func() {
+			u := &unstructured.Unstructured{}
+			Expect(u.UnmarshalJSON([]byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"example","namespace":"default"}}`))).To(Succeed())
+			Expect(IgnoredObjectKey(u)).To(Equal("default/deployment-example"))
+		})
+	})
+})
@@ -0,0 +1,40 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PruneAnnotation is the annotation used to control whether a child is pruned
+// when it is removed from the repository
+const PruneAnnotation = "faros.pusher.com/prune"
+
+// PruneDisabledValue is the value of the PruneAnnotation which prevents a child
+// from being deleted when it is removed from the repository
+const PruneDisabledValue = "disabled"
+
+// PruneAcknowledgedAnnotation is the annotation used on a GitTrack to
+// acknowledge pruning children above the prune threshold. Its value must be the
+// revision whose pruning is acknowledged.
+const PruneAcknowledgedAnnotation = "faros.pusher.com/prune-acknowledged"
+
+// PruneDisabled returns whether the `faros.pusher.com/prune` annotation on the
+// object disables pruning
+func PruneDisabled(obj metav1.Object) bool {
+	return obj.GetAnnotations()[PruneAnnotation] == PruneDisabledValue
+}
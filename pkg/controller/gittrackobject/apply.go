@@ -0,0 +1,60 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrackobject
+
+import (
+	"fmt"
+
+	gittrackobjectutils "github.com/pusher/faros/pkg/controller/gittrackobject/utils"
+	farosflags "github.com/pusher/faros/pkg/flags"
+	"github.com/pusher/faros/pkg/utils"
+	farosclient "github.com/pusher/faros/pkg/utils/client"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// applyOptions returns the options used to apply the child, according to its
+// apply mode and deletion propagation
+func applyOptions(child *unstructured.Unstructured) (farosclient.ApplyOptions, error) {
+	mode, err := gittrackobjectutils.GetApplyMode(child, gittrackobjectutils.ApplyMode(farosflags.ApplyMode))
+	if err != nil {
+		return farosclient.ApplyOptions{}, fmt.Errorf("unable to get apply mode: %v", err)
+	}
+	propagation, err := utils.GetDeletionPropagation(child)
+	if err != nil {
+		return farosclient.ApplyOptions{}, fmt.Errorf("unable to get deletion propagation: %v", err)
+	}
+
+	serverSideApply := mode == gittrackobjectutils.ServerSideApplyMode
+	forceConflicts := farosflags.ForceConflicts
+	return farosclient.ApplyOptions{
+		ServerSideApply:     &serverSideApply,
+		ForceConflicts:      &forceConflicts,
+		DeletionPropagation: propagation,
+	}, nil
+}
+
+// verifyApplyMode returns an error if the options apply the child server-side
+// and the API server doesn't support server-side apply
+func (r *ReconcileGitTrackObject) verifyApplyMode(opts farosclient.ApplyOptions) error {
+	if opts.ServerSideApply == nil || !*opts.ServerSideApply {
+		return nil
+	}
+	if err := r.serverSideApplyVerifier.HasSupport(); err != nil {
+		return fmt.Errorf("unable to apply child server-side, use the client apply mode instead: %v", err)
+	}
+	return nil
+}
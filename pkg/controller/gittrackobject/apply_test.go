@@ -0,0 +1,67 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrackobject
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	farosflags "github.com/pusher/faros/pkg/flags"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("applyOptions", func() {
+	var child *unstructured.Unstructured
+	var defaultApplyMode string
+
+	BeforeEach(func() {
+		defaultApplyMode = farosflags.ApplyMode
+		child = &unstructured.Unstructured{}
+		child.SetName("example")
+	})
+
+	AfterEach(func() {
+		farosflags.ApplyMode = defaultApplyMode
+	})
+
+	It("applies client-side by default", func() {
+		farosflags.ApplyMode = ""
+		opts, err := applyOptions(child)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*opts.ServerSideApply).To(BeFalse())
+	})
+
+	It("uses the apply mode flag", func() {
+		farosflags.ApplyMode = "server"
+		opts, err := applyOptions(child)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*opts.ServerSideApply).To(BeTrue())
+	})
+
+	It("prefers the apply mode annotation of the child", func() {
+		farosflags.ApplyMode = "server"
+		child.SetAnnotations(map[string]string{"faros.pusher.com/apply-mode": "client"})
+		opts, err := applyOptions(child)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*opts.ServerSideApply).To(BeFalse())
+	})
+
+	It("returns an error for an invalid apply mode", func() {
+		child.SetAnnotations(map[string]string{"faros.pusher.com/apply-mode": "invalid"})
+		_, err := applyOptions(child)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,202 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrackobject
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	farosflags "github.com/pusher/faros/pkg/flags"
+	"github.com/pusher/faros/pkg/utils"
+	farosclient "github.com/pusher/faros/pkg/utils/client"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	rlogr "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+// clusterAnnotation records the kubeconfig Secret of the remote cluster the
+// child of a (Cluster)GitTrackObject was deployed to, so that the child can be
+// deleted after the owning GitTrack is gone.
+// The value is formatted as <namespace>/<secret name>/<key>.
+const clusterAnnotation = "faros.pusher.com/cluster"
+
+// remoteCluster holds the clients used to manage children in a remote cluster
+type remoteCluster struct {
+	key                     string
+	kubeconfig              []byte
+	client                  client.Client
+	cache                   cache.Cache
+	informers               map[string]cache.Informer
+	applier                 farosclient.Client
+	dryRunVerifier          *utils.DryRunVerifier
+	serverSideApplyVerifier *utils.ServerSideApplyVerifier
+	impersonators           *impersonators
+	stop                    chan struct{}
+}
+
+// remoteClusters creates and stores the remote clusters targeted by GitTracks,
+// keyed by the kubeconfig Secret they are configured by
+type remoteClusters struct {
+	client   client.Client
+	scheme   *runtime.Scheme
+	stop     chan struct{}
+	mutex    sync.Mutex
+	clusters map[string]*remoteCluster
+}
+
+// newRemoteClusters constructs a remoteClusters reading kubeconfig Secrets with
+// the client. The informers of the remote clusters are stopped when stop is
+// closed.
+func newRemoteClusters(c client.Client, scheme *runtime.Scheme, stop chan struct{}) *remoteClusters {
+	return &remoteClusters{
+		client:   c,
+		scheme:   scheme,
+		stop:     stop,
+		clusters: make(map[string]*remoteCluster),
+	}
+}
+
+// get returns the remote cluster configured by the kubeconfig Secret in the
+// namespace, creating new clients whenever the kubeconfig changes
+func (rc *remoteClusters) get(namespace string, ref *farosv1alpha1.GitTrackCluster) (*remoteCluster, error) {
+	kubeconfig, err := utils.GetKubeconfig(rc.client, namespace, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	key := utils.ClusterKey(namespace, ref)
+	if cluster := rc.lookup(key, kubeconfig); cluster != nil {
+		return cluster, nil
+	}
+
+	// Creating the clients runs discovery against the remote cluster, so it is
+	// done without holding the lock to avoid blocking other clusters
+	cluster, err := newRemoteCluster(kubeconfig, rc.scheme, rc.stop)
+	if err != nil {
+		return nil, err
+	}
+	cluster.key = key
+
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	if existing, ok := rc.clusters[key]; ok {
+		if bytes.Equal(existing.kubeconfig, kubeconfig) {
+			// Another reconcile created the cluster first, discard this one
+			close(cluster.stop)
+			return existing, nil
+		}
+		// The kubeconfig has changed, stop the informers using the old one
+		close(existing.stop)
+	}
+	rc.clusters[key] = cluster
+	return cluster, nil
+}
+
+// lookup returns the stored remote cluster for the key if it was created with
+// the kubeconfig, or nil otherwise
+func (rc *remoteClusters) lookup(key string, kubeconfig []byte) *remoteCluster {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	if cluster, ok := rc.clusters[key]; ok && bytes.Equal(cluster.kubeconfig, kubeconfig) {
+		return cluster
+	}
+	return nil
+}
+
+// newRemoteCluster constructs the clients for the cluster configured by the
+// kubeconfig and starts its informer cache
+func newRemoteCluster(kubeconfig []byte, scheme *runtime.Scheme, stop chan struct{}) (*remoteCluster, error) {
+	config, err := utils.RESTConfigFromKubeconfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	mapper, err := utils.NewRestMapper(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create rest mapper: %v", err)
+	}
+
+	c, err := client.New(config, client.Options{Scheme: scheme, Mapper: mapper})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create client: %v", err)
+	}
+
+	informerCache, err := cache.New(config, cache.Options{Scheme: scheme, Mapper: mapper, Namespace: farosflags.Namespace})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create cache: %v", err)
+	}
+
+	applier, err := farosclient.NewApplier(config, farosclient.Options{Scheme: scheme, Mapper: mapper})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create applier: %v", err)
+	}
+
+	dryRunVerifier, err := utils.NewDryRunVerifier(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create dry run verifier: %v", err)
+	}
+
+	serverSideApplyVerifier, err := utils.NewServerSideApplyVerifier(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create server-side apply verifier: %v", err)
+	}
+
+	cluster := &remoteCluster{
+		kubeconfig:              kubeconfig,
+		client:                  c,
+		cache:                   informerCache,
+		informers:               make(map[string]cache.Informer),
+		applier:                 applier,
+		dryRunVerifier:          dryRunVerifier,
+		serverSideApplyVerifier: serverSideApplyVerifier,
+		impersonators:           newImpersonators(config, scheme, mapper),
+		stop:                    make(chan struct{}),
+	}
+
+	// Stop the informers when either the controller stops or the cluster is
+	// replaced
+	informerStop := make(chan struct{})
+	go func() {
+		select {
+		case <-stop:
+		case <-cluster.stop:
+		}
+		close(informerStop)
+	}()
+	go func() {
+		if err := informerCache.Start(informerStop); err != nil {
+			rlogr.Log.WithName("gittrackobject-controller").Error(err, "unable to start remote cluster cache")
+		}
+	}()
+	return cluster, nil
+}
+
+// parseClusterKey parses the value of the `faros.pusher.com/cluster` annotation
+// into the namespace and GitTrackCluster it was formatted from
+func parseClusterKey(value string) (string, *farosv1alpha1.GitTrackCluster, error) {
+	parts := strings.SplitN(value, "/", 3)
+	if len(parts) != 3 || parts[1] == "" {
+		return "", nil, fmt.Errorf("invalid cluster: %s", value)
+	}
+	return parts[0], &farosv1alpha1.GitTrackCluster{SecretName: parts[1], Key: parts[2]}, nil
+}
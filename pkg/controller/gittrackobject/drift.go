@@ -0,0 +1,151 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrackobject
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	gittrackobjectutils "github.com/pusher/faros/pkg/controller/gittrackobject/utils"
+	farosclient "github.com/pusher/faros/pkg/utils/client"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// maxDriftFields is the maximum number of drifted fields listed in the
+// ObjectDrifted condition
+const maxDriftFields = 10
+
+// driftResult is the result of comparing a child to its desired state
+type driftResult struct {
+	drifted bool
+	// detected is true when the drift differs from the drift last recorded
+	// in the status
+	detected bool
+	reason   gittrackobjectutils.ConditionReason
+	message  string
+	// patch is the formatted patch that would revert the drift
+	patch string
+}
+
+// getDriftPolicy returns the drift policy of the child, defaulting to the
+// drift policy of the GitTrack owning the GitTrackObjectInterface
+func getDriftPolicy(owner *farosv1alpha1.GitTrack, child *unstructured.Unstructured) (farosv1alpha1.GitTrackDriftPolicy, error) {
+	var defaultPolicy farosv1alpha1.GitTrackDriftPolicy
+	if owner != nil {
+		defaultPolicy = owner.Spec.DriftPolicy
+	}
+	return gittrackobjectutils.GetDriftPolicy(child, defaultPolicy)
+}
+
+// handleDrift compares the existing child to its desired state, apart from the
+// ignored fields, and reports any difference without updating the child
+func (r *ReconcileGitTrackObject) handleDrift(gto farosv1alpha1.GitTrackObjectInterface, child *unstructured.Unstructured, opts farosclient.ApplyOptions) driftResult {
+	patch, err := r.applier.Diff(context.TODO(), &opts, child.DeepCopy())
+	if err != nil {
+		return driftResult{
+			reason:  gittrackobjectutils.ErrorCheckingDrift,
+			message: fmt.Sprintf("unable to compare child to desired state: %v", err),
+		}
+	}
+
+	fields, err := driftedFields(patch)
+	if err != nil {
+		return driftResult{
+			reason:  gittrackobjectutils.ErrorCheckingDrift,
+			message: fmt.Sprintf("unable to summarize difference: %v", err),
+		}
+	}
+	if len(fields) == 0 {
+		return driftResult{reason: gittrackobjectutils.ChildNotDrifted}
+	}
+
+	formatted, err := formatPatch(child.GroupVersionKind().GroupKind(), patch)
+	if err != nil {
+		return driftResult{
+			reason:  gittrackobjectutils.ErrorCheckingDrift,
+			message: fmt.Sprintf("unable to format difference: %v", err),
+		}
+	}
+
+	result := driftResult{
+		drifted: true,
+		reason:  gittrackobjectutils.ChildDrifted,
+		message: fmt.Sprintf("child differs from desired state in fields: %s", summarizeFields(fields)),
+		patch:   formatted,
+	}
+	previous := gittrackobjectutils.GetGitTrackObjectCondition(gto.GetStatus(), farosv1alpha1.ObjectDriftedType)
+	if previous == nil || previous.Status != corev1.ConditionTrue || previous.Message != result.message {
+		result.detected = true
+		r.log.V(0).Info("Child drifted", "fields", fields)
+		r.sendEvent(gto, corev1.EventTypeWarning, "ChildDrifted", "Child %s %s/%s has drifted from desired state", child.GetKind(), child.GetNamespace(), child.GetName())
+	}
+	return result
+}
+
+// driftedFields returns the sorted paths of the fields changed by the patch,
+// ignoring the last applied annotation and patch directives
+func driftedFields(patch []byte) ([]string, error) {
+	diff := map[string]interface{}{}
+	if err := json.Unmarshal(patch, &diff); err != nil {
+		return nil, err
+	}
+	if metadata, ok := diff["metadata"].(map[string]interface{}); ok {
+		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+			delete(annotations, farosclient.LastAppliedAnnotation)
+		}
+	}
+
+	fields := collectFields("", diff)
+	sort.Strings(fields)
+	return fields, nil
+}
+
+// collectFields walks the patch and returns the path of each changed field.
+// Lists are reported as a single field.
+func collectFields(prefix string, value interface{}) []string {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return []string{prefix}
+	}
+
+	fields := []string{}
+	for key, v := range obj {
+		// Strategic merge patch directives start with '$'
+		if strings.HasPrefix(key, "$") {
+			continue
+		}
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		fields = append(fields, collectFields(path, v)...)
+	}
+	return fields
+}
+
+// summarizeFields joins the fields, truncating the list at maxDriftFields
+func summarizeFields(fields []string) string {
+	if len(fields) <= maxDriftFields {
+		return strings.Join(fields, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(fields[:maxDriftFields], ", "), len(fields)-maxDriftFields)
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrackobject
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	farosclient "github.com/pusher/faros/pkg/utils/client"
+)
+
+var _ = Describe("Drift", func() {
+	Context("driftedFields", func() {
+		It("returns the paths of the changed fields", func() {
+			patch := []byte(`{"spec":{"replicas":3,"template":{"spec":{"$setElementOrder/containers":[{"name":"nginx"}],"containers":[{"image":"nginx","name":"nginx"}]}}}}`)
+			Expect(driftedFields(patch)).To(Equal([]string{"spec.replicas", "spec.template.spec.containers"}))
+		})
+
+		It("ignores the last applied annotation", func() {
+			patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:"{}"}}}`, farosclient.LastAppliedAnnotation))
+			Expect(driftedFields(patch)).To(BeEmpty())
+		})
+
+		It("returns nothing for an empty patch", func() {
+			Expect(driftedFields([]byte(`{}`))).To(BeEmpty())
+		})
+	})
+
+	Context("summarizeFields", func() {
+		It("lists all fields below the limit", func() {
+			Expect(summarizeFields([]string{"a", "b"})).To(Equal("a, b"))
+		})
+
+		It("truncates the fields above the limit", func() {
+			fields := []string{}
+			for i := 0; i < maxDriftFields+2; i++ {
+				fields = append(fields, fmt.Sprintf("f%d", i))
+			}
+			Expect(summarizeFields(fields)).To(HaveSuffix("f9 and 2 more"))
+		})
+	})
+})
@@ -0,0 +1,300 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrackobject
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	gittrackobjectutils "github.com/pusher/faros/pkg/controller/gittrackobject/utils"
+	farosclient "github.com/pusher/faros/pkg/utils/client"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// flapWindow is the window within which repeated reverts of a child are
+	// considered flapping
+	flapWindow = 5 * time.Minute
+	// flapThreshold is the number of reverts within the flapWindow after which
+	// a child is considered flapping
+	flapThreshold = 3
+	// minFlapBackoff is the initial delay between updates of a flapping child
+	minFlapBackoff = 30 * time.Second
+	// maxFlapBackoff is the maximum delay between updates of a flapping child
+	maxFlapBackoff = 10 * time.Minute
+)
+
+// flapResult describes whether a child is flapping
+type flapResult struct {
+	flapping bool
+	// detected is true when the child started flapping during this reconcile
+	detected bool
+	message  string
+	// wait is how long updates to the child should be delayed for
+	wait time.Duration
+}
+
+// flapState records the recent reverts of a child
+type flapState struct {
+	// desired is a hash of the desired state of the child, reverts made to
+	// apply a new desired state are not counted
+	desired    string
+	reverts    []time.Time
+	count      int
+	backoff    time.Duration
+	nextUpdate time.Time
+	fields     []string
+}
+
+// flapDetector tracks reverts of children to detect children that are being
+// fought over with other controllers
+type flapDetector struct {
+	clock  clockwork.Clock
+	mutex  sync.Mutex
+	states map[string]*flapState
+}
+
+// newFlapDetector constructs a new flapDetector
+func newFlapDetector(clock clockwork.Clock) *flapDetector {
+	return &flapDetector{
+		clock:  clock,
+		states: make(map[string]*flapState),
+	}
+}
+
+// check returns whether the child of the GitTrackObjectInterface is flapping
+// and how long updates to it should be delayed for
+func (f *flapDetector) check(gto farosv1alpha1.GitTrackObjectInterface) flapResult {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	key := flapKey(gto)
+	desired := gittrackobjectutils.DataHash(gto)
+	now := f.clock.Now()
+	state, ok := f.states[key]
+	switch {
+	case ok && state.desired != desired:
+		// The next update applies a new desired state and isn't a revert
+		delete(f.states, key)
+		return flapResult{}
+	case !ok, f.expired(state, now):
+		// Start tracking the child so that the next update is counted
+		f.states[key] = &flapState{desired: desired}
+		return flapResult{}
+	case state.backoff == 0:
+		return flapResult{}
+	}
+
+	result := flapResult{flapping: true, message: state.message()}
+	if now.Before(state.nextUpdate) {
+		result.wait = state.nextUpdate.Sub(now)
+	}
+	return result
+}
+
+// record records a revert of the given fields of the child of the
+// GitTrackObjectInterface and returns whether it is flapping
+func (f *flapDetector) record(gto farosv1alpha1.GitTrackObjectInterface, fields []string) flapResult {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	key := flapKey(gto)
+	desired := gittrackobjectutils.DataHash(gto)
+	now := f.clock.Now()
+	state, ok := f.states[key]
+	if !ok || state.desired != desired {
+		// Updates made to apply a new desired state are not reverts
+		f.states[key] = &flapState{desired: desired}
+		return flapResult{}
+	}
+
+	// Only keep reverts within the window
+	reverts := []time.Time{}
+	for _, t := range state.reverts {
+		if now.Sub(t) < flapWindow {
+			reverts = append(reverts, t)
+		}
+	}
+	state.reverts = append(reverts, now)
+	state.count++
+	state.fields = fields
+
+	result := flapResult{}
+	switch {
+	case state.backoff > 0:
+		state.backoff *= 2
+		if state.backoff > maxFlapBackoff {
+			state.backoff = maxFlapBackoff
+		}
+	case len(state.reverts) >= flapThreshold:
+		state.backoff = minFlapBackoff
+		state.count = len(state.reverts)
+		result.detected = true
+	default:
+		return result
+	}
+	state.nextUpdate = now.Add(state.backoff)
+	result.flapping = true
+	result.message = state.message()
+	return result
+}
+
+// forget stops tracking the child of the GitTrackObjectInterface with the
+// given key
+func (f *flapDetector) forget(key string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	delete(f.states, key)
+}
+
+// expired returns whether the child hasn't been reverted for long enough that
+// its previous reverts should be forgotten
+func (f *flapDetector) expired(state *flapState, now time.Time) bool {
+	if len(state.reverts) == 0 {
+		return false
+	}
+	last := state.reverts[len(state.reverts)-1]
+	return now.Sub(last) > state.backoff+flapWindow
+}
+
+// message describes the flapping child for the ObjectFlapping condition
+func (s *flapState) message() string {
+	msg := fmt.Sprintf("child reverted %d times, delaying updates by %s", s.count, s.backoff)
+	if len(s.fields) > 0 {
+		msg = fmt.Sprintf("%s; fields: %s", msg, summarizeFields(s.fields))
+	}
+	return msg
+}
+
+// flapKey returns the key of the GitTrackObjectInterface in the flapDetector
+func flapKey(gto farosv1alpha1.GitTrackObjectInterface) string {
+	return fmt.Sprintf("%s/%s", gto.GetNamespace(), gto.GetName())
+}
+
+// handleFlapping records the update of the child, made with the given
+// formatted patch, and returns whether the child is flapping. The fields
+// involved are taken from the managed fields of the child before it was
+// updated.
+func (r *ReconcileGitTrackObject) handleFlapping(gto farosv1alpha1.GitTrackObjectInterface, found *unstructured.Unstructured, patch string) flapResult {
+	// Truncated patches can't be parsed, the fights are still recorded
+	fields, err := driftedFields([]byte(patch))
+	if err != nil {
+		fields = nil
+	}
+
+	result := r.flaps.record(gto, flappingFields(found, fields))
+	if result.detected {
+		r.log.V(0).Info("Child flapping", "message", result.message)
+		r.sendEvent(gto, corev1.EventTypeWarning, "ChildFlapping", "Child %s %s/%s is repeatedly being reverted: %s", found.GetKind(), found.GetNamespace(), found.GetName(), result.message)
+	}
+	return result
+}
+
+// flappingFields returns the changed fields annotated with the field managers,
+// other than Faros, that last set them according to the managed fields of the
+// child
+func flappingFields(found *unstructured.Unstructured, changed []string) []string {
+	managedFields, _, _ := unstructured.NestedSlice(found.Object, "metadata", "managedFields")
+
+	seen := make(map[string]bool)
+	fields := []string{}
+	for _, entry := range managedFields {
+		e, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		manager, _ := e["manager"].(string)
+		if manager == farosclient.FieldManager {
+			continue
+		}
+		// The field set was renamed from fields to fieldsV1 in Kubernetes 1.17
+		set, ok := e["fieldsV1"].(map[string]interface{})
+		if !ok {
+			set, _ = e["fields"].(map[string]interface{})
+		}
+		for _, path := range managedPaths("", set) {
+			if !overlaps(path, changed) {
+				continue
+			}
+			field := fmt.Sprintf("%s (%s)", path, manager)
+			if !seen[field] {
+				seen[field] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	// Fall back to the changed fields if no other manager owns them
+	if len(fields) == 0 {
+		return changed
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// managedPaths returns the paths of the fields in a managed fields set. Lists
+// are reported as a single field.
+func managedPaths(prefix string, set map[string]interface{}) []string {
+	paths := []string{}
+	for key, value := range set {
+		if !strings.HasPrefix(key, "f:") {
+			// Keys of list elements (k:, v: and i:) and the field itself (.)
+			if prefix != "" && key != "." && !contains(paths, prefix) {
+				paths = append(paths, prefix)
+			}
+			continue
+		}
+		path := strings.TrimPrefix(key, "f:")
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		children, _ := value.(map[string]interface{})
+		if sub := managedPaths(path, children); len(sub) > 0 {
+			paths = append(paths, sub...)
+		} else {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// overlaps returns whether the path is, or is within, one of the fields, or
+// contains one of them
+func overlaps(path string, fields []string) bool {
+	for _, field := range fields {
+		if path == field || strings.HasPrefix(path, field+".") || strings.HasPrefix(field, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// contains returns whether the slice contains the string
+func contains(slice []string, s string) bool {
+	for _, item := range slice {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
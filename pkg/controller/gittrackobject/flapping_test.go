@@ -0,0 +1,158 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrackobject
+
+import (
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Flapping", func() {
+	Context("flapDetector", func() {
+		var clock clockwork.FakeClock
+		var f *flapDetector
+		var gto *farosv1alpha1.GitTrackObject
+
+		// revert simulates a reconcile in which the child is reverted
+		revert := func() flapResult {
+			if result := f.check(gto); result.wait > 0 {
+				return result
+			}
+			return f.record(gto, []string{"spec.replicas"})
+		}
+
+		BeforeEach(func() {
+			clock = clockwork.NewFakeClock()
+			f = newFlapDetector(clock)
+			gto = &farosv1alpha1.GitTrackObject{
+				ObjectMeta: metav1.ObjectMeta{Name: "deployment-example", Namespace: "default"},
+				Spec:       farosv1alpha1.GitTrackObjectSpec{Data: []byte(`{"kind":"Deployment"}`)},
+			}
+			Expect(f.check(gto).flapping).To(BeFalse())
+		})
+
+		It("does not consider occasional reverts flapping", func() {
+			for i := 0; i < flapThreshold; i++ {
+				Expect(revert().flapping).To(BeFalse())
+				clock.Advance(flapWindow)
+			}
+		})
+
+		It("detects repeated reverts within the window", func() {
+			for i := 0; i < flapThreshold-1; i++ {
+				Expect(revert().flapping).To(BeFalse())
+			}
+			result := revert()
+			Expect(result.flapping).To(BeTrue())
+			Expect(result.detected).To(BeTrue())
+			Expect(result.message).To(ContainSubstring("spec.replicas"))
+		})
+
+		It("does not count updates applying a new desired state", func() {
+			for i := 0; i < flapThreshold-1; i++ {
+				Expect(revert().flapping).To(BeFalse())
+			}
+			gto.Spec.Data = []byte(`{"kind":"Deployment","spec":{}}`)
+			Expect(revert().flapping).To(BeFalse())
+		})
+
+		Context("when the child is flapping", func() {
+			BeforeEach(func() {
+				for i := 0; i < flapThreshold; i++ {
+					revert()
+				}
+			})
+
+			It("delays updates", func() {
+				result := f.check(gto)
+				Expect(result.flapping).To(BeTrue())
+				Expect(result.wait).To(Equal(minFlapBackoff))
+			})
+
+			It("doubles the delay after each revert", func() {
+				clock.Advance(minFlapBackoff)
+				Expect(revert().detected).To(BeFalse())
+				Expect(f.check(gto).wait).To(Equal(2 * minFlapBackoff))
+			})
+
+			It("limits the delay", func() {
+				for i := 0; i < 10; i++ {
+					clock.Advance(f.check(gto).wait)
+					revert()
+				}
+				Expect(f.check(gto).wait).To(Equal(maxFlapBackoff))
+			})
+
+			It("stops flapping when the child is no longer reverted", func() {
+				clock.Advance(minFlapBackoff + flapWindow + time.Second)
+				Expect(f.check(gto).flapping).To(BeFalse())
+			})
+		})
+	})
+
+	Context("flappingFields", func() {
+		var found *unstructured.Unstructured
+
+		BeforeEach(func() {
+			found = &unstructured.Unstructured{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"managedFields": []interface{}{
+						map[string]interface{}{
+							"manager": "faros",
+							"fieldsV1": map[string]interface{}{
+								"f:spec": map[string]interface{}{"f:replicas": map[string]interface{}{}},
+							},
+						},
+						map[string]interface{}{
+							"manager": "kube-controller-manager",
+							"fieldsV1": map[string]interface{}{
+								"f:spec": map[string]interface{}{
+									"f:replicas": map[string]interface{}{},
+									"f:template": map[string]interface{}{
+										"f:spec": map[string]interface{}{
+											"f:containers": map[string]interface{}{
+												`k:{"name":"nginx"}`: map[string]interface{}{".": map[string]interface{}{}},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}}
+		})
+
+		It("names the other managers of the changed fields", func() {
+			Expect(flappingFields(found, []string{"spec.replicas"})).To(Equal([]string{"spec.replicas (kube-controller-manager)"}))
+		})
+
+		It("reports lists as a single field", func() {
+			Expect(flappingFields(found, []string{"spec.template.spec.containers"})).To(Equal([]string{"spec.template.spec.containers (kube-controller-manager)"}))
+		})
+
+		It("falls back to the changed fields", func() {
+			Expect(flappingFields(found, []string{"metadata.labels"})).To(Equal([]string{"metadata.labels"}))
+		})
+	})
+})
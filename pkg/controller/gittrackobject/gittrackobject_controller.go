@@ -28,10 +28,14 @@ import (
 	gittrackobjectutils "github.com/pusher/faros/pkg/controller/gittrackobject/utils"
 
 	"github.com/go-logr/logr"
+	"github.com/jonboulle/clockwork"
+	farosflags "github.com/pusher/faros/pkg/flags"
+	"github.com/pusher/faros/pkg/health"
 	"github.com/pusher/faros/pkg/utils"
 	farosclient "github.com/pusher/faros/pkg/utils/client"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
@@ -73,18 +77,47 @@ func newReconciler(mgr manager.Manager) reconcile.Reconciler {
 		panic(fmt.Errorf("unable to create dry run verifier: %v", err))
 	}
 
+	serverSideApplyVerifier, err := utils.NewServerSideApplyVerifier(mgr.GetConfig())
+	if err != nil {
+		panic(fmt.Errorf("unable to create server-side apply verifier: %v", err))
+	}
+
+	healthChecker, err := newHealthChecker()
+	if err != nil {
+		panic(fmt.Errorf("unable to create health checker: %v", err))
+	}
+
+	ignoredFields, err := farosflags.ParseIgnoredFields()
+	if err != nil {
+		panic(fmt.Errorf("unable to parse ignored fields: %v", err))
+	}
+
+	protectedKinds, err := farosflags.ParseProtectedKinds()
+	if err != nil {
+		panic(fmt.Errorf("unable to parse protected kinds: %v", err))
+	}
+
 	return &ReconcileGitTrackObject{
-		Client:         mgr.GetClient(),
-		scheme:         mgr.GetScheme(),
-		eventStream:    make(chan event.GenericEvent),
-		cache:          mgr.GetCache(),
-		informers:      make(map[string]cache.Informer),
-		config:         mgr.GetConfig(),
-		stop:           stop,
-		recorder:       mgr.GetEventRecorderFor("gittrackobject-controller"),
-		applier:        applier,
-		dryRunVerifier: dryRunVerifier,
-		log:            rlogr.Log.WithName("gittrackobject-controller"),
+		Client:                  mgr.GetClient(),
+		scheme:                  mgr.GetScheme(),
+		eventStream:             make(chan event.GenericEvent),
+		remoteEventStream:       make(chan event.GenericEvent),
+		cache:                   mgr.GetCache(),
+		informers:               make(map[string]cache.Informer),
+		config:                  mgr.GetConfig(),
+		stop:                    stop,
+		recorder:                mgr.GetEventRecorderFor("gittrackobject-controller"),
+		children:                mgr.GetClient(),
+		clusters:                newRemoteClusters(mgr.GetClient(), mgr.GetScheme(), stop),
+		impersonators:           newImpersonators(mgr.GetConfig(), mgr.GetScheme(), mgr.GetRESTMapper()),
+		applier:                 applier,
+		dryRunVerifier:          dryRunVerifier,
+		serverSideApplyVerifier: serverSideApplyVerifier,
+		healthChecker:           healthChecker,
+		ignoredFields:           ignoredFields,
+		protectedKinds:          protectedKinds,
+		flaps:                   newFlapDetector(clockwork.NewRealClock()),
+		log:                     rlogr.Log.WithName("gittrackobject-controller"),
 	}
 }
 
@@ -112,6 +145,17 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Reconcile the children of a GitTrack when it is resumed, as they aren't
+	// managed while it is suspended
+	err = c.Watch(
+		&source.Kind{Type: &farosv1alpha1.GitTrack{}},
+		enqueueRequestsForControlled(mgr.GetClient(), rlogr.Log.WithName("gittrackobject-controller/enqueue-requests-for-controlled")),
+		resumedPredicate{},
+	)
+	if err != nil {
+		return err
+	}
+
 	// Watch for events on the reconciler's eventStream channel
 	if gtoReconciler, ok := r.(Reconciler); ok {
 		src := &source.Channel{
@@ -138,6 +182,15 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 			log.Printf(msg)
 			return fmt.Errorf(msg)
 		}
+
+		// Children in remote clusters can't have owner references, so queue
+		// the owner recorded in their annotations instead
+		err = c.Watch(&source.Channel{Source: gtoReconciler.RemoteEventStream()}, gittrackobjectutils.EnqueueRequestForRemoteOwner)
+		if err != nil {
+			msg := fmt.Sprintf("unable to watch channel: %v", err)
+			log.Printf(msg)
+			return fmt.Errorf(msg)
+		}
 	}
 
 	return nil
@@ -147,6 +200,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 // for setting up the watch streams.
 type Reconciler interface {
 	EventStream() chan event.GenericEvent
+	RemoteEventStream() chan event.GenericEvent
 	StopChan() chan struct{}
 }
 
@@ -155,17 +209,33 @@ var _ reconcile.Reconciler = &ReconcileGitTrackObject{}
 // ReconcileGitTrackObject reconciles a GitTrackObject object
 type ReconcileGitTrackObject struct {
 	client.Client
-	scheme      *runtime.Scheme
-	eventStream chan event.GenericEvent
-	cache       cache.Cache
-	informers   map[string]cache.Informer
-	config      *rest.Config
-	stop        chan struct{}
-	recorder    record.EventRecorder
-	log         logr.Logger
-
-	applier        farosclient.Client
-	dryRunVerifier *utils.DryRunVerifier
+	scheme            *runtime.Scheme
+	eventStream       chan event.GenericEvent
+	remoteEventStream chan event.GenericEvent
+	cache             cache.Cache
+	informers         map[string]cache.Informer
+	config            *rest.Config
+	stop              chan struct{}
+	recorder          record.EventRecorder
+	log               logr.Logger
+
+	// children is the client used to manage children, either in the local
+	// cluster or in the remote cluster targeted by the owning GitTrack
+	children client.Client
+	clusters *remoteClusters
+	cluster  *remoteCluster
+
+	// impersonators creates the clients used to manage children of GitTracks
+	// that set a ServiceAccount in the local cluster
+	impersonators *impersonators
+
+	applier                 farosclient.Client
+	dryRunVerifier          *utils.DryRunVerifier
+	serverSideApplyVerifier *utils.ServerSideApplyVerifier
+	healthChecker           *health.Checker
+	ignoredFields           map[schema.GroupKind][]string
+	protectedKinds          map[schema.GroupKind]bool
+	flaps                   *flapDetector
 }
 
 // EventStream returns a stream of generic event to trigger reconciles
@@ -173,6 +243,12 @@ func (r *ReconcileGitTrackObject) EventStream() chan event.GenericEvent {
 	return r.eventStream
 }
 
+// RemoteEventStream returns a stream of generic event for children in remote
+// clusters to trigger reconciles
+func (r *ReconcileGitTrackObject) RemoteEventStream() chan event.GenericEvent {
+	return r.remoteEventStream
+}
+
 // StopChan returns the object stop channel
 func (r *ReconcileGitTrackObject) StopChan() chan struct{} {
 	return r.stop
@@ -189,6 +265,7 @@ func (r *ReconcileGitTrackObject) withValues(keysAndValues ...interface{}) *Reco
 // Automatically generate RBAC rules to allow the Controller to read and write Deployments
 // +kubebuilder:rbac:groups=*,resources=*,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=faros.pusher.com,resources=gittrackobjects,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=,resources=serviceaccounts,verbs=impersonate
 func (r *ReconcileGitTrackObject) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 	// Fetch the GTO requested
 	instance, err := r.getInstance(request)
@@ -196,6 +273,7 @@ func (r *ReconcileGitTrackObject) Reconcile(request reconcile.Request) (reconcil
 		if errors.IsNotFound(err) {
 			// Object not found, return.  Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
+			r.flaps.forget(request.NamespacedName.String())
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -211,14 +289,58 @@ func (r *ReconcileGitTrackObject) Reconcile(request reconcile.Request) (reconcil
 
 	reconciler.log.V(1).Info("Reconcile started")
 
+	// Don't manage the child while the (Cluster)GitTrackObject is being deleted
+	// so that orphaning the child isn't undone. Children in remote clusters
+	// aren't garbage collected so must be deleted before the
+	// (Cluster)GitTrackObject is removed.
+	if instance.GetDeletionTimestamp() != nil {
+		if hasFinalizer(instance, utils.RemoteChildFinalizer) {
+			reconciler.log.V(1).Info("GitTrackObject is being deleted, deleting remote child")
+			return reconcile.Result{}, reconciler.handleRemoteDeletion(instance)
+		}
+		reconciler.log.V(1).Info("GitTrackObject is being deleted, skipping")
+		return reconcile.Result{}, nil
+	}
+
+	// Leave the child as it is while the owning GitTrack is suspended, so that
+	// changes made to it in the meantime aren't reverted. Errors getting the
+	// owner are reported when managing the child for it below.
+	if owner, err := reconciler.getOwner(instance); err == nil && owner != nil && owner.Spec.Suspend {
+		reconciler.log.V(1).Info("GitTrack is suspended, skipping")
+		return reconcile.Result{}, nil
+	}
+
+	// A requested reconcile updates the child straight away, even if it is
+	// flapping, and is recorded as handled once this reconcile finishes without
+	// errors. Otherwise the request is retried along with the reconcile.
+	requested := utils.ReconcileRequest(instance, instance.GetStatus().LastHandledReconcileAt)
+	if requested != "" {
+		reconciler.log.V(0).Info("Reconcile requested", "requested at", requested)
+		reconciler.flaps.forget(flapKey(instance))
+	}
+
+	// Manage the child in the cluster targeted by the owning GitTrack, as its
+	// ServiceAccount
+	reconciler, err = reconciler.forOwner(instance)
+	if err != nil {
+		reconciler.updateStatus(instance, &statusOpts{inSyncError: err, inSyncReason: gittrackobjectutils.ErrorConnectingToCluster})
+		reconciler.updateMetrics(instance, &metricsOpts{})
+		return reconcile.Result{}, err
+	}
+
 	// Create new opts structs for updating status and metrics
 	result := reconciler.handleGitTrackObject(instance)
-	reconciler.updateStatus(instance, &statusOpts{inSyncError: result.inSyncError, inSyncReason: result.inSyncReason})
+	healthRes := reconciler.handleHealth(instance)
+	if result.inSyncError != nil {
+		requested = ""
+	}
+	reconciler.updateStatus(instance, &statusOpts{inSyncError: result.inSyncError, inSyncReason: result.inSyncReason, health: healthRes, drift: result.drift, flap: result.flap, appliedPatch: result.appliedPatch, applied: result.applied, handledReconcileAt: requested})
 	inSync := result.inSyncError == nil
-	reconciler.updateMetrics(instance, &metricsOpts{inSync: inSync})
+	healthy := healthRes.status == health.StatusHealthy
+	reconciler.updateMetrics(instance, &metricsOpts{inSync: inSync, healthy: healthy, driftDetected: result.drift.detected, flapping: result.flap.flapping})
 
 	reconciler.log.V(1).Info("Reconcile finished")
-	return reconcile.Result{}, result.inSyncError
+	return reconcile.Result{RequeueAfter: result.flap.wait}, result.inSyncError
 }
 
 // getInstance fetches the requested (Cluster)GitTrackObject from the API server
@@ -43,12 +43,17 @@ import (
 
 var cfg *rest.Config
 
+// remoteCfg is the config of a second API server standing in for a remote
+// cluster
+var remoteCfg *rest.Config
+
 func TestGitTrackObjectController(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecsWithDefaultAndCustomReporters(t, "GitTrackObject Suite", reporters.Reporters())
 }
 
 var t *envtest.Environment
+var remote *envtest.Environment
 
 var _ = BeforeSuite(func() {
 	logr.SetLogger(klogr.New())
@@ -68,10 +73,16 @@ var _ = BeforeSuite(func() {
 	if cfg, err = t.Start(); err != nil {
 		log.Fatal(err)
 	}
+
+	remote = &envtest.Environment{}
+	if remoteCfg, err = remote.Start(); err != nil {
+		log.Fatal(err)
+	}
 })
 
 var _ = AfterSuite(func() {
 	t.Stop()
+	remote.Stop()
 })
 
 type testReconciler struct {
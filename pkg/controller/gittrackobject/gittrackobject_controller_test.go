@@ -28,6 +28,7 @@ import (
 	"github.com/pusher/faros/pkg/controller/gittrackobject/metrics"
 	gittrackobjectutils "github.com/pusher/faros/pkg/controller/gittrackobject/utils"
 	farosflags "github.com/pusher/faros/pkg/flags"
+	"github.com/pusher/faros/pkg/utils"
 	farosclient "github.com/pusher/faros/pkg/utils/client"
 	testutils "github.com/pusher/faros/test/utils"
 	appsv1 "k8s.io/api/apps/v1"
@@ -152,6 +153,84 @@ var _ = Describe("GitTrackObject Suite", func() {
 				Expect(testutils.SetGitTrackObjectInterfaceSpec(gto, child)).To(Succeed())
 			})
 
+			Context("when the GitTrack is suspended", func() {
+				BeforeEach(func() {
+					gitTrack.Spec.Suspend = true
+					m.Update(gitTrack, timeout).Should(Succeed())
+					// Wait for the cache to see the GitTrack suspended
+					Eventually(func() (bool, error) {
+						err := m.Client.Get(context.TODO(), types.NamespacedName{Namespace: gitTrack.Namespace, Name: gitTrack.Name}, gitTrack)
+						return gitTrack.Spec.Suspend, err
+					}, timeout).Should(BeTrue())
+
+					truth := true
+					gto.OwnerReferences[0].Controller = &truth
+					m.Create(gto).Should(Succeed())
+					Eventually(requests, timeout).Should(Receive(Equal(expectedRequest)))
+				})
+
+				It("should not create the child resource", func() {
+					Consistently(func() error {
+						return c.Get(context.TODO(), types.NamespacedName{Namespace: child.Namespace, Name: child.Name}, child)
+					}, consistentlyTimeout).ShouldNot(Succeed())
+				})
+
+				Context("and is resumed", func() {
+					BeforeEach(func() {
+						gitTrack.Spec.Suspend = false
+						m.Update(gitTrack, timeout).Should(Succeed())
+						// Resuming the GitTrack alone should reconcile its children
+						Eventually(requests, timeout).Should(Receive(Equal(expectedRequest)))
+					})
+
+					It("should create the child resource", func() {
+						m.Get(child, timeout).Should(Succeed())
+					})
+				})
+			})
+
+			Context("when the GitTrack is suspended after creating the child", func() {
+				// setSuspend suspends or resumes the GitTrack and waits for the
+				// cache to see it
+				setSuspend := func(suspend bool) {
+					m.Get(gitTrack, timeout).Should(Succeed())
+					gitTrack.Spec.Suspend = suspend
+					m.Update(gitTrack, timeout).Should(Succeed())
+					Eventually(func() (bool, error) {
+						err := m.Client.Get(context.TODO(), types.NamespacedName{Namespace: gitTrack.Namespace, Name: gitTrack.Name}, gitTrack)
+						return gitTrack.Spec.Suspend, err
+					}, timeout).Should(Equal(suspend))
+				}
+
+				BeforeEach(func() {
+					truth := true
+					gto.OwnerReferences[0].Controller = &truth
+					m.Create(gto).Should(Succeed())
+					// Wait twice for the extra reconcile for status updates
+					Eventually(requests, timeout).Should(Receive(Equal(expectedRequest)))
+					Eventually(requests, timeout).Should(Receive(Equal(expectedRequest)))
+					m.Get(child, timeout).Should(Succeed())
+
+					setSuspend(true)
+					child.Spec.Template.Spec.Containers[0].Image = "nginx:latest"
+					m.Update(child, timeout).Should(Succeed())
+				})
+
+				It("should not revert changes to the child", func() {
+					m.Consistently(child, consistentlyTimeout).Should(testutils.WithContainers(ContainElement(testutils.WithImage(Equal("nginx:latest")))))
+				})
+
+				Context("and is resumed", func() {
+					BeforeEach(func() {
+						setSuspend(false)
+					})
+
+					It("should revert changes made to the child while suspended", func() {
+						m.Eventually(child, timeout).Should(testutils.WithContainers(ContainElement(testutils.WithImage(Equal("nginx")))))
+					})
+				})
+			})
+
 			Context("with valid data", func() {
 				BeforeEach(func() {
 					// Create and fetch the instance to make sure caches are synced
@@ -179,6 +258,141 @@ var _ = Describe("GitTrackObject Suite", func() {
 						Should(testutils.WithAnnotations(HaveKey(farosclient.LastAppliedAnnotation)))
 				})
 
+				Context("when the GitTrackObject is handed over to another GitTrack", func() {
+					var newGitTrack *farosv1alpha1.GitTrack
+					var originalUID types.UID
+
+					BeforeEach(func() {
+						originalUID = child.GetUID()
+
+						newGitTrack = &farosv1alpha1.GitTrack{
+							ObjectMeta: metav1.ObjectMeta{
+								Name:      "newgittrack",
+								Namespace: "default",
+							},
+							Spec: farosv1alpha1.GitTrackSpec{
+								Reference:  "foo",
+								Repository: "baz",
+							},
+						}
+						m.Create(newGitTrack).Should(Succeed())
+						m.Get(newGitTrack, timeout).Should(Succeed())
+
+						// Hand over the GitTrackObject as the GitTrack controller does,
+						// moving its controller reference and recording the new
+						// GitTrack in its data
+						truth := true
+						gto.SetOwnerReferences([]metav1.OwnerReference{
+							{
+								APIVersion:         "faros.pusher.com/v1alpha1",
+								Kind:               "GitTrack",
+								UID:                newGitTrack.UID,
+								Name:               newGitTrack.Name,
+								Controller:         &truth,
+								BlockOwnerDeletion: &truth,
+							},
+						})
+						specData := testutils.ExampleDeployment.DeepCopy()
+						utils.SetGitTrack(specData, "default/newgittrack")
+						Expect(testutils.SetGitTrackObjectInterfaceSpec(gto, specData)).To(Succeed())
+						utils.SetGitTrack(gto, "default/newgittrack")
+						m.Update(gto, timeout).Should(Succeed())
+						Eventually(requests, timeout).Should(Receive(Equal(expectedRequest)))
+					})
+
+					It("should keep the child", func() {
+						m.Consistently(child, consistentlyTimeout).Should(testutils.WithUID(Equal(originalUID)))
+					})
+
+					It("should record the new GitTrack on the child", func() {
+						m.Eventually(child, timeout).
+							Should(testutils.WithAnnotations(HaveKeyWithValue(utils.GitTrackAnnotation, "default/newgittrack")))
+					})
+				})
+
+				Context("when previewing the child", func() {
+					var desired *appsv1.Deployment
+
+					// preview previews the child of a copy of the GitTrackObject
+					// with the desired child as its data
+					preview := func(owner *farosv1alpha1.GitTrack) (ChildPreview, error) {
+						previewed := gto.DeepCopy()
+						Expect(testutils.SetGitTrackObjectInterfaceSpec(previewed, desired)).To(Succeed())
+						return PreviewChild(c, r.applier, r.scheme, owner, previewed, nil, false)
+					}
+
+					BeforeEach(func() {
+						desired = testutils.ExampleDeployment.DeepCopy()
+					})
+
+					It("should leave the child created by the controller unchanged", func() {
+						p, err := preview(gitTrack)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(p.Action).To(Equal(PreviewUnchanged))
+					})
+
+					It("should list the fields changed by new data", func() {
+						desired.Spec.Template.Spec.Containers[0].Image = "nginx:1.17"
+						p, err := preview(gitTrack)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(p.Action).To(Equal(PreviewUpdate))
+						Expect(p.Fields).To(ConsistOf("spec.template.spec.containers"))
+					})
+
+					It("should leave children with the create-only update strategy unchanged", func() {
+						desired.SetAnnotations(map[string]string{"faros.pusher.com/update-strategy": string(gittrackobjectutils.CreateOnlyUpdateStrategy)})
+						desired.Spec.Template.Spec.Containers[0].Image = "nginx:1.17"
+						p, err := preview(gitTrack)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(p.Action).To(Equal(PreviewUnchanged))
+					})
+
+					It("should leave children with the never update strategy unchanged", func() {
+						desired.SetAnnotations(map[string]string{"faros.pusher.com/update-strategy": string(gittrackobjectutils.NeverUpdateStrategy)})
+						desired.Spec.Template.Spec.Containers[0].Image = "nginx:1.17"
+						p, err := preview(gitTrack)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(p.Action).To(Equal(PreviewUnchanged))
+					})
+
+					Context("with the Report drift policy", func() {
+						var owner *farosv1alpha1.GitTrack
+
+						BeforeEach(func() {
+							owner = gitTrack.DeepCopy()
+							owner.Spec.DriftPolicy = farosv1alpha1.DriftPolicyReport
+							desired.Spec.Template.Spec.Containers[0].Image = "nginx:1.17"
+						})
+
+						It("should apply new data rather than report it", func() {
+							p, err := preview(owner)
+							Expect(err).NotTo(HaveOccurred())
+							Expect(p.Action).To(Equal(PreviewUpdate))
+							Expect(p.Reported).To(BeFalse())
+						})
+
+						It("should report changes to a child the data was applied to", func() {
+							previewed := gto.DeepCopy()
+							Expect(testutils.SetGitTrackObjectInterfaceSpec(previewed, desired)).To(Succeed())
+							previewed.Status.AppliedDataHash = gittrackobjectutils.DataHash(previewed)
+							p, err := PreviewChild(c, r.applier, r.scheme, owner, previewed, nil, false)
+							Expect(err).NotTo(HaveOccurred())
+							Expect(p.Action).To(Equal(PreviewUpdate))
+							Expect(p.Reported).To(BeTrue())
+						})
+					})
+
+					It("should refuse to adopt children controlled by another GitTrackObject", func() {
+						owner := gitTrack.DeepCopy()
+						owner.Spec.Adoption = farosv1alpha1.AdoptionNever
+						other := gto.DeepCopy()
+						other.SetUID(types.UID("other"))
+						Expect(testutils.SetGitTrackObjectInterfaceSpec(other, desired)).To(Succeed())
+						_, err := PreviewChild(c, r.applier, r.scheme, owner, other, nil, false)
+						Expect(err).To(MatchError(ContainSubstring("adoption policy is Never")))
+					})
+				})
+
 				Context("when the child has the update strategy", func() {
 					var originalVersion string
 					var originalUID types.UID
@@ -200,6 +414,7 @@ var _ = Describe("GitTrackObject Suite", func() {
 							annotations := map[string]string{"faros.pusher.com/update-strategy": string(gittrackobjectutils.DefaultUpdateStrategy)}
 							specData.SetAnnotations(annotations)
 							Expect(testutils.SetGitTrackObjectInterfaceSpec(gto, specData)).To(Succeed())
+							utils.SetSourceCommit(gto, "448b39a21d285fcb5aa4b718b27a3e13ffc649b3")
 
 							m.Update(gto, timeout).Should(Succeed())
 							// Wait for the update reconcile
@@ -210,6 +425,11 @@ var _ = Describe("GitTrackObject Suite", func() {
 							m.Eventually(child, timeout).Should(testutils.WithResourceVersion(Not(Equal(originalVersion))))
 						})
 
+						It("should record the commit of the GitTrackObject on the child", func() {
+							m.Eventually(child, timeout).
+								Should(testutils.WithAnnotations(HaveKeyWithValue(utils.SourceCommitAnnotation, "448b39a21d285fcb5aa4b718b27a3e13ffc649b3")))
+						})
+
 						It("should not replace the child", func() {
 							m.Consistently(child, consistentlyTimeout).Should(testutils.WithUID(Equal(originalUID)))
 						})
@@ -436,6 +656,7 @@ var _ = Describe("GitTrackObject Suite", func() {
 				BeforeEach(func() {
 					// Break the JSON data
 					gto.Spec.Data = gto.Spec.Data[10:]
+					gto.SetAnnotations(map[string]string{utils.ReconcileRequestedAnnotation: "2019-01-01T00:00:00Z"})
 
 					// Create and fetch the instance to make sure caches are synced
 					m.Create(gto).Should(Succeed())
@@ -446,6 +667,10 @@ var _ = Describe("GitTrackObject Suite", func() {
 				})
 
 				Context("should update the status", func() {
+					It("without recording the requested reconcile as handled", func() {
+						m.Consistently(gto, consistentlyTimeout).Should(testutils.WithGitTrackObjectLastHandledReconcileAt(BeEmpty()))
+					})
+
 					It("to represent the failure", func() {
 						m.Eventually(gto, timeout).Should(
 							testutils.WithGitTrackObjectStatusConditions(
@@ -482,6 +707,99 @@ var _ = Describe("GitTrackObject Suite", func() {
 				})
 			})
 
+			Context("with an existing child controlled by another owner", func() {
+				var existing *appsv1.Deployment
+				truth := true
+
+				BeforeEach(func() {
+					existing = testutils.ExampleDeployment.DeepCopy()
+					existing.SetOwnerReferences([]metav1.OwnerReference{
+						{
+							APIVersion: "apps/v1",
+							Kind:       "ReplicaSet",
+							Name:       "other-owner",
+							UID:        "12345",
+							Controller: &truth,
+						},
+					})
+					m.Create(existing).Should(Succeed())
+					m.Get(existing, timeout).Should(Succeed())
+				})
+
+				Context("and the IfUnowned adoption policy", func() {
+					BeforeEach(func() {
+						specData := testutils.ExampleDeployment.DeepCopy()
+						specData.SetAnnotations(map[string]string{"faros.pusher.com/adoption": string(farosv1alpha1.AdoptionIfUnowned)})
+						Expect(testutils.SetGitTrackObjectInterfaceSpec(gto, specData)).To(Succeed())
+
+						m.Create(gto).Should(Succeed())
+						// Wait twice for the extra reconcile for status updates
+						Eventually(requests, timeout).Should(Receive(Equal(expectedRequest)))
+						Eventually(requests, timeout).Should(Receive(Equal(expectedRequest)))
+					})
+
+					It("should not take over the child", func() {
+						m.Consistently(existing, consistentlyTimeout).Should(testutils.WithOwnerReferences(Equal(existing.GetOwnerReferences())))
+					})
+
+					It("should report the foreign owner in the status", func() {
+						m.Eventually(gto, timeout).Should(
+							testutils.WithGitTrackObjectStatusConditions(
+								ContainElement(
+									SatisfyAll(
+										testutils.WithGitTrackObjectConditionType(Equal(farosv1alpha1.ObjectInSyncType)),
+										testutils.WithGitTrackObjectConditionStatus(Equal(corev1.ConditionFalse)),
+										testutils.WithGitTrackObjectConditionReason(Equal(string(gittrackobjectutils.AdoptionRefused))),
+										testutils.WithGitTrackObjectConditionMessage(ContainSubstring("ReplicaSet 'other-owner'")),
+									),
+								),
+							),
+						)
+					})
+
+					Context("and the GitTrackObject is reconciled again", func() {
+						BeforeEach(func() {
+							m.Eventually(&corev1.EventList{}, timeout).Should(testutils.WithItems(ContainElement(
+								testutils.WithReason(Equal("AdoptionRefused")),
+							)))
+							m.Get(gto, timeout).Should(Succeed())
+							gto.SetLabels(map[string]string{"reconcile": "again"})
+							m.Update(gto, timeout).Should(Succeed())
+							Eventually(requests, timeout).Should(Receive(Equal(expectedRequest)))
+						})
+
+						It("should only send the AdoptionRefused event once", func() {
+							m.Consistently(&corev1.EventList{}, consistentlyTimeout).Should(testutils.WithItems(ContainElement(
+								SatisfyAll(
+									testutils.WithReason(Equal("AdoptionRefused")),
+									testutils.WithInvolvedObjectName(Equal(gto.GetName())),
+									testutils.WithEventCount(Equal(int32(1))),
+								),
+							)))
+						})
+					})
+				})
+
+				Context("and the Always adoption policy", func() {
+					BeforeEach(func() {
+						specData := testutils.ExampleDeployment.DeepCopy()
+						specData.SetAnnotations(map[string]string{"faros.pusher.com/adoption": string(farosv1alpha1.AdoptionAlways)})
+						Expect(testutils.SetGitTrackObjectInterfaceSpec(gto, specData)).To(Succeed())
+
+						m.Create(gto).Should(Succeed())
+						// Wait twice for the extra reconcile for status updates
+						Eventually(requests, timeout).Should(Receive(Equal(expectedRequest)))
+						Eventually(requests, timeout).Should(Receive(Equal(expectedRequest)))
+						m.Get(gto, timeout).Should(Succeed())
+					})
+
+					It("should take over the child", func() {
+						m.Eventually(existing, timeout).
+							Should(testutils.WithOwnerReferences(ContainElement(testutils.GetGitTrackObjectOwnerRef(gto))))
+					})
+				})
+			})
+
 			Context("in a different namespace", func() {
 				var ns *corev1.Namespace
 
@@ -39,6 +39,13 @@ import (
 type handlerResult struct {
 	inSyncError  error
 	inSyncReason gittrackobjectutils.ConditionReason
+	appliedPatch string
+	drift        driftResult
+	flap         flapResult
+
+	// applied is true when the data of the (Cluster)GitTrackObject was
+	// applied to the child
+	applied bool
 }
 
 // handleGitTrackObject handles the management of the child of the GitTrackObjectInterface
@@ -67,12 +74,31 @@ func (r *ReconcileGitTrackObject) handleGitTrackObject(gto farosv1alpha1.GitTrac
 		}
 	}
 
-	// Add an owner reference to the child object
-	err = controllerutil.SetControllerReference(gto, child, r.scheme)
-	if err != nil {
-		return handlerResult{
-			inSyncReason: gittrackobjectutils.ErrorAddingOwnerReference,
-			inSyncError:  fmt.Errorf("unable to add owner reference to child %s %s: %v", gto.GetSpec().Kind, gto.GetSpec().Name, err),
+	// Children with the create-only update strategy are never updated or
+	// deleted, so they aren't owned by the (Cluster)GitTrackObject. Invalid
+	// update strategies are reported when updating the child.
+	updateStrategy, _ := gittrackobjectutils.GetUpdateStrategy(child)
+	createOnly := updateStrategy == gittrackobjectutils.CreateOnlyUpdateStrategy
+
+	// Add an owner reference to the child object. Children in remote clusters
+	// record their owner in an annotation instead and are deleted through a
+	// finalizer as they aren't garbage collected.
+	if !createOnly && r.cluster != nil {
+		gittrackobjectutils.SetRemoteOwner(gto, child)
+		err = r.ensureRemoteFinalizer(gto)
+		if err != nil {
+			return handlerResult{
+				inSyncReason: gittrackobjectutils.ErrorAddingOwnerReference,
+				inSyncError:  fmt.Errorf("unable to add finalizer for child %s %s: %v", gto.GetSpec().Kind, gto.GetSpec().Name, err),
+			}
+		}
+	} else if !createOnly {
+		err = controllerutil.SetControllerReference(gto, child, r.scheme)
+		if err != nil {
+			return handlerResult{
+				inSyncReason: gittrackobjectutils.ErrorAddingOwnerReference,
+				inSyncError:  fmt.Errorf("unable to add owner reference to child %s %s: %v", gto.GetSpec().Kind, gto.GetSpec().Name, err),
+			}
 		}
 	}
 
@@ -81,7 +107,7 @@ func (r *ReconcileGitTrackObject) handleGitTrackObject(gto farosv1alpha1.GitTrac
 	found.SetKind(child.GetKind())
 	found.SetAPIVersion(child.GetAPIVersion())
 
-	err = r.Get(context.TODO(), types.NamespacedName{Name: child.GetName(), Namespace: child.GetNamespace()}, found)
+	err = r.children.Get(context.TODO(), types.NamespacedName{Name: child.GetName(), Namespace: child.GetNamespace()}, found)
 	if err != nil && errors.IsNotFound(err) {
 		reason, err = r.handleCreate(gto, child)
 		if err != nil {
@@ -92,7 +118,12 @@ func (r *ReconcileGitTrackObject) handleGitTrackObject(gto farosv1alpha1.GitTrac
 		}
 
 		// Successfully created child
-		return handlerResult{}
+		return handlerResult{applied: true}
+	} else if err != nil && errors.IsForbidden(err) {
+		return handlerResult{
+			inSyncReason: gittrackobjectutils.PermissionDenied,
+			inSyncError:  fmt.Errorf("unable to get child %s %s: %v", gto.GetSpec().Kind, gto.GetSpec().Name, err),
+		}
 	} else if err != nil {
 		return handlerResult{
 			inSyncReason: gittrackobjectutils.ErrorGettingChild,
@@ -100,23 +131,107 @@ func (r *ReconcileGitTrackObject) handleGitTrackObject(gto farosv1alpha1.GitTrac
 		}
 	}
 
-	reason, err = r.handleUpdate(gto, found, child)
+	if createOnly {
+		r.log.V(1).Info("Child has `create-only` update strategy")
+		return handlerResult{}
+	}
+
+	owner, err := r.getOwner(gto)
+	if err != nil {
+		return handlerResult{
+			inSyncReason: gittrackobjectutils.ErrorGettingOwner,
+			inSyncError:  fmt.Errorf("unable to get owner of child %s %s: %v", gto.GetSpec().Kind, gto.GetSpec().Name, err),
+		}
+	}
+
+	// Don't take over children that already exist unless the adoption policy
+	// allows it
+	reason, err = r.checkAdoption(gto, owner, child, found)
+	if err != nil {
+		return handlerResult{
+			inSyncReason: reason,
+			inSyncError:  err,
+		}
+	}
+
+	opts, err := applyOptions(child)
+	if err != nil {
+		return handlerResult{
+			inSyncReason: gittrackobjectutils.ErrorUpdatingChild,
+			inSyncError:  fmt.Errorf("unable to get apply options for child %s %s: %v", gto.GetSpec().Kind, gto.GetSpec().Name, err),
+		}
+	}
+	if err = r.verifyApplyMode(opts); err != nil {
+		return handlerResult{
+			inSyncReason: gittrackobjectutils.ServerSideApplyUnsupported,
+			inSyncError:  fmt.Errorf("error updating child %s %s: %v", gto.GetSpec().Kind, gto.GetSpec().Name, err),
+		}
+	}
+	opts.IgnoreFields, err = r.getIgnoredFields(owner, child)
+	if err != nil {
+		return handlerResult{
+			inSyncReason: gittrackobjectutils.ErrorUpdatingChild,
+			inSyncError:  fmt.Errorf("unable to get ignored fields for child %s %s: %v", gto.GetSpec().Kind, gto.GetSpec().Name, err),
+		}
+	}
+
+	// Report changes made outside of Git rather than reverting them if the
+	// drift policy requires it. Changes to the data are still applied, after
+	// which only changes to the child since are reported.
+	driftPolicy, err := getDriftPolicy(owner, child)
+	if err != nil {
+		return handlerResult{
+			inSyncReason: gittrackobjectutils.ErrorUpdatingChild,
+			inSyncError:  fmt.Errorf("unable to get drift policy for child %s %s: %v", gto.GetSpec().Kind, gto.GetSpec().Name, err),
+		}
+	}
+	reportDrift := driftPolicy == farosv1alpha1.DriftPolicyReport
+	if reportDrift && gittrackobjectutils.DataApplied(gto) {
+		return handlerResult{drift: r.handleDrift(gto, child, opts)}
+	}
+
+	// Back off from updating children that other controllers keep changing
+	flap := r.flaps.check(gto)
+	if flap.wait > 0 {
+		r.log.V(1).Info("Delaying update of flapping child", "wait", flap.wait.String())
+		return handlerResult{flap: flap}
+	}
+
+	patch, reason, err := r.handleUpdate(gto, found, child, opts)
 	if err != nil {
 		return handlerResult{
 			inSyncReason: reason,
 			inSyncError:  fmt.Errorf("error updating child %s %s: %v", gto.GetSpec().Kind, gto.GetSpec().Name, err),
+			flap:         flap,
 		}
 	}
+	if patch != "" {
+		flap = r.handleFlapping(gto, found, patch)
+	}
 
-	return handlerResult{}
+	result := handlerResult{appliedPatch: patch, flap: flap, applied: true}
+	if reportDrift {
+		// The child was just brought in line with the data
+		result.drift = driftResult{reason: gittrackobjectutils.ChildNotDrifted}
+	}
+	return result
 }
 
 // getChildFromGitTrackObject reads the Data from a GitTrackObjectSpec and
 // converts it into and unstructured.unstructured runtime object
 func (r *ReconcileGitTrackObject) getChildFromGitTrackObject(gto farosv1alpha1.GitTrackObjectInterface) (*unstructured.Unstructured, gittrackobjectutils.ConditionReason, error) {
+	child, reason, err := childFromGitTrackObject(gto)
+	if reason == gittrackobjectutils.ErrorUnmarshallingData {
+		r.sendEvent(gto, corev1.EventTypeWarning, "UnmarshalFailed", "Couldn't unmarshal object from JSON/YAML")
+	}
+	return child, reason, err
+}
+
+// childFromGitTrackObject reads the child from the Data of a
+// GitTrackObjectSpec
+func childFromGitTrackObject(gto farosv1alpha1.GitTrackObjectInterface) (*unstructured.Unstructured, gittrackobjectutils.ConditionReason, error) {
 	child, err := utils.YAMLToUnstructured(gto.GetSpec().Data)
 	if err != nil {
-		r.sendEvent(gto, corev1.EventTypeWarning, "UnmarshalFailed", "Couldn't unmarshal object from JSON/YAML")
 		return nil, gittrackobjectutils.ErrorUnmarshallingData, fmt.Errorf("unable to unmarshal data: %v", err)
 	}
 
@@ -125,6 +240,11 @@ func (r *ReconcileGitTrackObject) getChildFromGitTrackObject(gto farosv1alpha1.G
 		return nil, gittrackobjectutils.ErrorGettingChild, fmt.Errorf("unable to get child: name cannot be empty")
 	}
 
+	// The commit the child was produced from is only recorded on the
+	// (Cluster)GitTrackObject, where it changes along with the data, so that
+	// the child is only stamped with a new commit when it is changed
+	utils.CopyProvenance(gto, &child)
+
 	return &child, "", nil
 }
 
@@ -133,8 +253,19 @@ func (r *ReconcileGitTrackObject) handleCreate(gto farosv1alpha1.GitTrackObjectI
 	// Log and send event that we are attempting to create the child resource
 	r.sendEvent(gto, corev1.EventTypeNormal, "CreateStarted", "Creating child %s %s/%s", child.GetKind(), child.GetNamespace(), child.GetName())
 
-	err := r.applier.Apply(context.TODO(), &farosclient.ApplyOptions{}, child)
+	opts, err := applyOptions(child)
 	if err != nil {
+		return gittrackobjectutils.ErrorCreatingChild, err
+	}
+	if err = r.verifyApplyMode(opts); err != nil {
+		return gittrackobjectutils.ServerSideApplyUnsupported, err
+	}
+
+	err = r.applier.Apply(context.TODO(), &opts, child)
+	if farosclient.IsPermissionDenied(err) {
+		r.sendEvent(gto, corev1.EventTypeWarning, "PermissionDenied", "Not permitted to create child %s %s/%s: %v", child.GetKind(), child.GetNamespace(), child.GetName(), err)
+		return gittrackobjectutils.PermissionDenied, fmt.Errorf("unable to create child: %v", err)
+	} else if err != nil {
 		r.sendEvent(gto, corev1.EventTypeWarning, "CreateFailed", "Failed to create child %s %s/%s", child.GetKind(), child.GetNamespace(), child.GetName())
 		return gittrackobjectutils.ErrorCreatingChild, fmt.Errorf("unable to create child: %v", err)
 	}
@@ -146,131 +277,261 @@ func (r *ReconcileGitTrackObject) handleCreate(gto farosv1alpha1.GitTrackObjectI
 	return "", nil
 }
 
-func (r *ReconcileGitTrackObject) handleUpdate(gto farosv1alpha1.GitTrackObjectInterface, found, child *unstructured.Unstructured) (gittrackobjectutils.ConditionReason, error) {
+// handleUpdate updates the child according to its update strategy, using the
+// given apply options, and returns the formatted patch applied to it, if any
+func (r *ReconcileGitTrackObject) handleUpdate(gto farosv1alpha1.GitTrackObjectInterface, found, child *unstructured.Unstructured, opts farosclient.ApplyOptions) (string, gittrackobjectutils.ConditionReason, error) {
 	updateStrategy, err := gittrackobjectutils.GetUpdateStrategy(child)
 	if err != nil {
-		return gittrackobjectutils.ErrorUpdatingChild, fmt.Errorf("unable to get update strategy: %v", err)
+		return "", gittrackobjectutils.ErrorUpdatingChild, fmt.Errorf("unable to get update strategy: %v", err)
 	}
 
 	switch updateStrategy {
 	case gittrackobjectutils.RecreateUpdateStrategy:
-		return r.handleRecreateUpdateStrategy(gto, found, child)
+		return r.handleRecreateUpdateStrategy(gto, found, child, opts)
 	case gittrackobjectutils.NeverUpdateStrategy:
-		return r.handleNeverUpdateStrategy(gto, found)
+		return r.handleNeverUpdateStrategy(gto, found, opts)
+	case gittrackobjectutils.ReplaceUpdateStrategy:
+		return r.handleReplaceUpdateStrategy(gto, found, child, opts)
+	case gittrackobjectutils.UpdateOrRecreateUpdateStrategy:
+		return r.handleUpdateOrRecreateUpdateStrategy(gto, found, child, opts)
+	case gittrackobjectutils.CreateOnlyUpdateStrategy:
+		// Existing children with the create-only update strategy are never
+		// touched
+		return "", "", nil
 	default:
-		return r.handleDefaultUpdateStrategy(gto, found, child)
+		return r.handleDefaultUpdateStrategy(gto, found, child, opts)
 	}
 }
 
 // handleDefaultUpdateStrategy compares the existing and desired state of the
 // child resource and updates the object in-place if required
-func (r *ReconcileGitTrackObject) handleDefaultUpdateStrategy(gto farosv1alpha1.GitTrackObjectInterface, found, child *unstructured.Unstructured) (gittrackobjectutils.ConditionReason, error) {
-	childUpdated, err := r.updateChild(found, child)
+func (r *ReconcileGitTrackObject) handleDefaultUpdateStrategy(gto farosv1alpha1.GitTrackObjectInterface, found, child *unstructured.Unstructured, opts farosclient.ApplyOptions) (string, gittrackobjectutils.ConditionReason, error) {
+	childUpdated, patch, err := r.updateChild(found, child, opts)
 	if err != nil {
-		r.sendEvent(gto, corev1.EventTypeWarning, "UpdateFailed", "Unable to update child %s %s/%s", child.GetKind(), child.GetNamespace(), child.GetName())
-		return gittrackobjectutils.ErrorUpdatingChild, fmt.Errorf("unable to update child: %v", err)
+		return "", r.handleUpdateFailure(gto, child, err), fmt.Errorf("unable to update child: %v", err)
 	}
 	if !childUpdated {
-		return "", nil
+		return "", "", nil
 	}
 
 	// Update was successful
-	r.sendEvent(gto, corev1.EventTypeNormal, "UpdateSuccessful", "Successfully updated child %s %s/%s", child.GetKind(), child.GetNamespace(), child.GetName())
-	r.log.V(0).Info("Child updated")
-	return "", nil
+	return r.handleUpdateSuccess(gto, child, patch), "", nil
 }
 
 // handleNeverUpdateStrategy compares the existing object to the existing object
-// with the correct owner references applied and updates if necessary
-func (r *ReconcileGitTrackObject) handleNeverUpdateStrategy(gto farosv1alpha1.GitTrackObjectInterface, found *unstructured.Unstructured) (gittrackobjectutils.ConditionReason, error) {
+// with the correct owner references applied and updates if necessary. Children
+// in remote clusters record their owner in an annotation instead.
+func (r *ReconcileGitTrackObject) handleNeverUpdateStrategy(gto farosv1alpha1.GitTrackObjectInterface, found *unstructured.Unstructured, opts farosclient.ApplyOptions) (string, gittrackobjectutils.ConditionReason, error) {
 	r.log.V(1).Info("Child has `never` update strategy")
 	child := found.DeepCopy()
-	err := controllerutil.SetControllerReference(gto, child, r.scheme)
-	if err != nil {
-		return gittrackobjectutils.ErrorAddingOwnerReference, fmt.Errorf("unable to add owner reference: %v", err)
+	if r.cluster != nil {
+		gittrackobjectutils.SetRemoteOwner(gto, child)
+	} else {
+		err := controllerutil.SetControllerReference(gto, child, r.scheme)
+		if err != nil {
+			return "", gittrackobjectutils.ErrorAddingOwnerReference, fmt.Errorf("unable to add owner reference: %v", err)
+		}
 	}
-	return r.handleDefaultUpdateStrategy(gto, found, child)
+	// The existing object is applied as it is, so no fields are ignored
+	opts.IgnoreFields = nil
+	return r.handleDefaultUpdateStrategy(gto, found, child, opts)
 }
 
 // handleRecreateUpdateStrategy compares the existing and desired state of the
 // resources and then deletes and recreates the child object if an update is
 // required
-func (r *ReconcileGitTrackObject) handleRecreateUpdateStrategy(gto farosv1alpha1.GitTrackObjectInterface, found, child *unstructured.Unstructured) (gittrackobjectutils.ConditionReason, error) {
+func (r *ReconcileGitTrackObject) handleRecreateUpdateStrategy(gto farosv1alpha1.GitTrackObjectInterface, found, child *unstructured.Unstructured, opts farosclient.ApplyOptions) (string, gittrackobjectutils.ConditionReason, error) {
 	r.log.V(1).Info("Child has `recreate` update strategy")
-	childUpdated, err := r.recreateChild(found, child)
+	protected, err := utils.DeleteProtected(child, r.protectedKinds)
+	if err != nil {
+		return "", gittrackobjectutils.ErrorUpdatingChild, fmt.Errorf("unable to get delete protection: %v", err)
+	}
+	if protected {
+		// Protected children are only ever updated in-place
+		r.log.V(1).Info("Child is protected from deletion, updating in-place")
+		return r.handleDefaultUpdateStrategy(gto, found, child, opts)
+	}
+	childUpdated, patch, err := r.recreateChild(found, child, opts)
+	if err != nil {
+		return "", r.handleUpdateFailure(gto, child, err), fmt.Errorf("unable to update child: %v", err)
+	}
+	if !childUpdated {
+		return "", "", nil
+	}
+
+	// Update was successful
+	return r.handleUpdateSuccess(gto, child, patch), "", nil
+}
+
+// handleReplaceUpdateStrategy replaces the child resource with its desired
+// state in full, rather than patching it
+func (r *ReconcileGitTrackObject) handleReplaceUpdateStrategy(gto farosv1alpha1.GitTrackObjectInterface, found, child *unstructured.Unstructured, opts farosclient.ApplyOptions) (string, gittrackobjectutils.ConditionReason, error) {
+	r.log.V(1).Info("Child has `replace` update strategy")
+	childUpdated, patch, err := r.replaceChild(found, child, opts)
+	if err != nil {
+		return "", r.handleUpdateFailure(gto, child, err), fmt.Errorf("unable to update child: %v", err)
+	}
+	if !childUpdated {
+		return "", "", nil
+	}
+
+	// Update was successful
+	return r.handleUpdateSuccess(gto, child, patch), "", nil
+}
+
+// handleUpdateOrRecreateUpdateStrategy updates the child resource in-place
+// and only deletes and recreates it if the update changes immutable fields
+func (r *ReconcileGitTrackObject) handleUpdateOrRecreateUpdateStrategy(gto farosv1alpha1.GitTrackObjectInterface, found, child *unstructured.Unstructured, opts farosclient.ApplyOptions) (string, gittrackobjectutils.ConditionReason, error) {
+	r.log.V(1).Info("Child has `update-or-recreate` update strategy")
+	protected, err := utils.DeleteProtected(child, r.protectedKinds)
 	if err != nil {
-		r.sendEvent(gto, corev1.EventTypeWarning, "UpdateFailed", "Unable to update child %s %s/%s", child.GetKind(), child.GetNamespace(), child.GetName())
-		return gittrackobjectutils.ErrorUpdatingChild, fmt.Errorf("unable to update child: %v", err)
+		return "", gittrackobjectutils.ErrorUpdatingChild, fmt.Errorf("unable to get delete protection: %v", err)
+	}
+	// Protected children are only ever updated in-place
+	recreateOnImmutable := !protected
+	opts.RecreateOnImmutable = &recreateOnImmutable
+	// As with recreateChild, dry run is not attempted as the child may need to
+	// be recreated
+	childUpdated, patch, err := r.applyChild(found, child, false, opts)
+	if err != nil {
+		return "", r.handleUpdateFailure(gto, child, err), fmt.Errorf("unable to update child: %v", err)
 	}
 	if !childUpdated {
-		return "", nil
+		return "", "", nil
 	}
 
 	// Update was successful
-	r.sendEvent(gto, corev1.EventTypeNormal, "UpdateSuccessful", "Successfully updated child %s %s/%s", child.GetKind(), child.GetNamespace(), child.GetName())
+	return r.handleUpdateSuccess(gto, child, patch), "", nil
+}
+
+// handleUpdateFailure sends an event for the failed update of the child and
+// returns the reason for the failure
+func (r *ReconcileGitTrackObject) handleUpdateFailure(gto farosv1alpha1.GitTrackObjectInterface, child *unstructured.Unstructured, err error) gittrackobjectutils.ConditionReason {
+	if farosclient.IsApplyConflict(err) {
+		r.sendEvent(gto, corev1.EventTypeWarning, "ApplyConflict", "Unable to update child %s %s/%s: %v", child.GetKind(), child.GetNamespace(), child.GetName(), err)
+		return gittrackobjectutils.ApplyConflict
+	}
+	if farosclient.IsPermissionDenied(err) {
+		r.sendEvent(gto, corev1.EventTypeWarning, "PermissionDenied", "Not permitted to update child %s %s/%s: %v", child.GetKind(), child.GetNamespace(), child.GetName(), err)
+		return gittrackobjectutils.PermissionDenied
+	}
+	r.sendEvent(gto, corev1.EventTypeWarning, "UpdateFailed", "Unable to update child %s %s/%s", child.GetKind(), child.GetNamespace(), child.GetName())
+	return gittrackobjectutils.ErrorUpdatingChild
+}
+
+// handleUpdateSuccess sends an event describing the patch applied to the
+// child and returns the formatted patch
+func (r *ReconcileGitTrackObject) handleUpdateSuccess(gto farosv1alpha1.GitTrackObjectInterface, child *unstructured.Unstructured, patch []byte) string {
 	r.log.V(0).Info("Child updated")
-	return "", nil
+	formatted, err := formatPatch(child.GroupVersionKind().GroupKind(), patch)
+	if err != nil {
+		r.log.Error(err, "unable to format patch")
+	}
+	if formatted == "" {
+		r.sendEvent(gto, corev1.EventTypeNormal, "UpdateSuccessful", "Successfully updated child %s %s/%s", child.GetKind(), child.GetNamespace(), child.GetName())
+		return ""
+	}
+	r.sendEvent(gto, corev1.EventTypeNormal, "UpdateSuccessful", "Successfully updated child %s %s/%s: %s", child.GetKind(), child.GetNamespace(), child.GetName(), formatted)
+	return formatted
 }
 
 // recreateChild first deletes and then creates a child resource for a (Cluster)GitTrackObject
-func (r *ReconcileGitTrackObject) recreateChild(found, child *unstructured.Unstructured) (bool, error) {
+func (r *ReconcileGitTrackObject) recreateChild(found, child *unstructured.Unstructured, opts farosclient.ApplyOptions) (bool, []byte, error) {
 	// Recreating the child does not make sense with dry run (dry run delete does
 	// not mean we can dry run create) and so do not attempt dry run here.
-	return r.applyChild(found, child, true)
+	return r.applyChild(found, child, true, opts)
+}
+
+// replaceChild replaces the child resource of a (Cluster)GitTrackObject with
+// its desired state
+func (r *ReconcileGitTrackObject) replaceChild(found, child *unstructured.Unstructured, opts farosclient.ApplyOptions) (bool, []byte, error) {
+	originalResourceVersion := found.GetResourceVersion()
+	var patch []byte
+	opts.Patch = &patch
+	err := r.applier.Replace(context.TODO(), &opts, child)
+	if farosclient.IsPermissionDenied(err) {
+		return false, nil, err
+	} else if err != nil {
+		return false, nil, fmt.Errorf("unable to replace child resource: %v", err)
+	}
+
+	// Not updated if the resource version hasn't changed
+	if originalResourceVersion == child.GetResourceVersion() {
+		return false, nil, nil
+	}
+	return true, patch, nil
 }
 
 // updateChild updates the given child resource of a (Cluster)GitTrackObject
-func (r *ReconcileGitTrackObject) updateChild(found, child *unstructured.Unstructured) (bool, error) {
+func (r *ReconcileGitTrackObject) updateChild(found, child *unstructured.Unstructured, opts farosclient.ApplyOptions) (bool, []byte, error) {
+	// Server-side apply only updates the child if it differs from the desired
+	// state, so there is nothing to gain from a dry run first
+	serverSideApply := opts.ServerSideApply != nil && *opts.ServerSideApply
 	// HasSupport returns an error if dry run not supported
-	if farosflags.ServerDryRun {
+	if farosflags.ServerDryRun && !serverSideApply {
 		if err := r.dryRunVerifier.HasSupport(child.GroupVersionKind()); err == nil {
 			r.log.V(2).Info("Updating child with dry-run support")
-			return r.applyChildWithDryRun(found, child, false)
+			return r.applyChildWithDryRun(found, child, false, opts)
 		}
 	}
 	// Dry run not supported so apply without DryRun
 	r.log.V(2).Info("Updating child without dry-run support")
-	return r.applyChild(found, child, false)
+	return r.applyChild(found, child, false, opts)
 }
 
 // applyChildWithDryRun first applies the child with DryRun and then updates the resource if there is change to persist
-func (r *ReconcileGitTrackObject) applyChildWithDryRun(found, child *unstructured.Unstructured, force bool) (bool, error) {
+func (r *ReconcileGitTrackObject) applyChildWithDryRun(found, child *unstructured.Unstructured, force bool, opts farosclient.ApplyOptions) (bool, []byte, error) {
 	// Take a copy of the original child so that if the dry run shows a diff,
 	// we Apply the original state of the child object
 	originalChild := child.DeepCopy()
 
+	dryRunOpts := opts
 	dryRunTrue := true
-	err := r.applier.Apply(context.TODO(), &farosclient.ApplyOptions{ForceDeletion: &force, ServerDryRun: &dryRunTrue}, child)
-	if err != nil {
-		return false, fmt.Errorf("unable to update child resource: %v", err)
+	dryRunOpts.ForceDeletion = &force
+	dryRunOpts.ServerDryRun = &dryRunTrue
+	err := r.applier.Apply(context.TODO(), &dryRunOpts, child)
+	if farosclient.IsPermissionDenied(err) {
+		return false, nil, err
+	} else if err != nil {
+		return false, nil, fmt.Errorf("unable to update child resource: %v", err)
 	}
 
 	// Not updated if the child now equals the server version
 	if reflect.DeepEqual(child, found) {
-		return false, nil
+		return false, nil, nil
 	}
 
 	// The DryRun showed a change is required so now update without DryRun
-	err = r.applier.Apply(context.TODO(), &farosclient.ApplyOptions{ForceDeletion: &force}, originalChild)
-	if err != nil {
-		return false, fmt.Errorf("unable to update child resource: %v", err)
+	var patch []byte
+	opts.ForceDeletion = &force
+	opts.Patch = &patch
+	err = r.applier.Apply(context.TODO(), &opts, originalChild)
+	if farosclient.IsApplyConflict(err) || farosclient.IsPermissionDenied(err) {
+		return false, nil, err
+	} else if err != nil {
+		return false, nil, fmt.Errorf("unable to update child resource: %v", err)
 	}
-	return true, nil
+	return true, patch, nil
 }
 
 // applyChild uses the applier to update the child
-func (r *ReconcileGitTrackObject) applyChild(found, child *unstructured.Unstructured, force bool) (bool, error) {
+func (r *ReconcileGitTrackObject) applyChild(found, child *unstructured.Unstructured, force bool, opts farosclient.ApplyOptions) (bool, []byte, error) {
 	originalResourceVersion := found.GetResourceVersion()
-	err := r.applier.Apply(context.TODO(), &farosclient.ApplyOptions{ForceDeletion: &force}, child)
-	if err != nil {
-		return false, fmt.Errorf("unable to update child resource: %v", err)
+	var patch []byte
+	opts.ForceDeletion = &force
+	opts.Patch = &patch
+	err := r.applier.Apply(context.TODO(), &opts, child)
+	if farosclient.IsApplyConflict(err) || farosclient.IsPermissionDenied(err) {
+		return false, nil, err
+	} else if err != nil {
+		return false, nil, fmt.Errorf("unable to update child resource: %v", err)
 	}
 
 	// Not updated if the resource version hasn't changed
 	if originalResourceVersion == child.GetResourceVersion() {
-		return false, nil
+		return false, nil, nil
 	}
-	return true, nil
+	return true, patch, nil
 }
 
 // sendEvent wraps event recording to make sure the namespace is set correctly
@@ -280,6 +541,11 @@ func (r *ReconcileGitTrackObject) sendEvent(gto farosv1alpha1.GitTrackObjectInte
 	if instance.GetNamespace() == "" {
 		instance.SetNamespace(farosflags.Namespace)
 	}
+	// Point at the file and commit that the child was produced from
+	if source := utils.DescribeSource(instance); source != "" {
+		messageFmt += " (from %s)"
+		args = append(args, source)
+	}
 
 	r.recorder.Eventf(instance, eventType, reason, messageFmt, args...)
 }
@@ -28,6 +28,7 @@ import (
 	farosclient "github.com/pusher/faros/pkg/utils/client"
 	testutils "github.com/pusher/faros/test/utils"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -84,6 +85,7 @@ var _ = Describe("Handler Suite", func() {
 			&farosv1alpha1.GitTrackObjectList{},
 			&farosv1alpha1.ClusterGitTrackObjectList{},
 			&appsv1.DeploymentList{},
+			&batchv1.JobList{},
 			&rbacv1.ClusterRoleBindingList{},
 			&corev1.EventList{},
 		)
@@ -155,6 +157,106 @@ var _ = Describe("Handler Suite", func() {
 				})
 			})
 
+			Context("when the child has ignored fields", func() {
+				BeforeEach(func() {
+					specData := testutils.ExampleDeployment.DeepCopy()
+					specData.SetAnnotations(map[string]string{"faros.pusher.com/ignore-fields": "/spec/template/spec/containers/*/image"})
+					Expect(testutils.SetGitTrackObjectInterfaceSpec(gto, specData)).To(Succeed())
+					m.Update(gto, timeout).Should(Succeed())
+
+					result = r.handleGitTrackObject(gto)
+					Expect(result.inSyncError).To(BeNil())
+					m.Get(child, timeout).Should(Succeed())
+
+					Expect(child.Spec.Template.Spec.Containers).To(HaveLen(1))
+					child.Spec.Template.Spec.Containers[0].Image = "nginx:latest"
+					m.Update(child).Should(Succeed())
+
+					result = r.handleGitTrackObject(gto)
+					Expect(result.inSyncError).To(BeNil())
+				})
+
+				It("should not revert the ignored fields", func() {
+					m.Consistently(child, consistentlyTimeout).Should(testutils.WithContainers(ContainElement(testutils.WithImage(Equal("nginx:latest")))))
+				})
+			})
+
+			Context("when the child has the Report drift policy", func() {
+				BeforeEach(func() {
+					specData := testutils.ExampleDeployment.DeepCopy()
+					specData.SetAnnotations(map[string]string{"faros.pusher.com/drift-policy": string(farosv1alpha1.DriftPolicyReport)})
+					Expect(testutils.SetGitTrackObjectInterfaceSpec(gto, specData)).To(Succeed())
+					m.Update(gto, timeout).Should(Succeed())
+
+					result = r.handleGitTrackObject(gto)
+					Expect(result.inSyncError).To(BeNil())
+					m.Get(child, timeout).Should(Succeed())
+
+					// Record the applied data as the controller does
+					updateGitTrackObjectStatus(gto, &statusOpts{applied: result.applied})
+				})
+
+				It("should record that the data was applied", func() {
+					Expect(result.applied).To(BeTrue())
+					Expect(gittrackobjectutils.DataApplied(gto)).To(BeTrue())
+				})
+
+				It("should not report drift for an unmodified child", func() {
+					result = r.handleGitTrackObject(gto)
+					Expect(result.drift.drifted).To(BeFalse())
+					Expect(result.drift.reason).To(Equal(gittrackobjectutils.ChildNotDrifted))
+				})
+
+				Context("and the child is modified", func() {
+					var modifiedVersion string
+
+					BeforeEach(func() {
+						Expect(child.Spec.Template.Spec.Containers).To(HaveLen(1))
+						child.Spec.Template.Spec.Containers[0].Image = "nginx:latest"
+						m.Update(child).Should(Succeed())
+						m.Get(child, timeout).Should(Succeed())
+						modifiedVersion = child.GetResourceVersion()
+
+						result = r.handleGitTrackObject(gto)
+						Expect(result.inSyncError).To(BeNil())
+					})
+
+					It("should report the drifted fields", func() {
+						Expect(result.drift.drifted).To(BeTrue())
+						Expect(result.drift.detected).To(BeTrue())
+						Expect(result.drift.reason).To(Equal(gittrackobjectutils.ChildDrifted))
+						Expect(result.drift.message).To(ContainSubstring("spec.template.spec.containers"))
+					})
+
+					It("should not revert the child", func() {
+						m.Consistently(child, consistentlyTimeout).Should(testutils.WithResourceVersion(Equal(modifiedVersion)))
+					})
+
+					Context("and the data is changed", func() {
+						BeforeEach(func() {
+							specData := testutils.ExampleDeployment.DeepCopy()
+							specData.SetAnnotations(map[string]string{"faros.pusher.com/drift-policy": string(farosv1alpha1.DriftPolicyReport)})
+							specData.Spec.Template.Spec.Containers[0].Image = "nginx:1.17"
+							Expect(testutils.SetGitTrackObjectInterfaceSpec(gto, specData)).To(Succeed())
+							m.Update(gto, timeout).Should(Succeed())
+
+							result = r.handleGitTrackObject(gto)
+							Expect(result.inSyncError).To(BeNil())
+						})
+
+						It("should apply the changed data", func() {
+							Expect(result.applied).To(BeTrue())
+							m.Eventually(child, timeout).Should(testutils.WithContainers(ContainElement(testutils.WithImage(Equal("nginx:1.17")))))
+						})
+
+						It("should not report drift", func() {
+							Expect(result.drift.drifted).To(BeFalse())
+							Expect(result.drift.reason).To(Equal(gittrackobjectutils.ChildNotDrifted))
+						})
+					})
+				})
+			})
+
 			Context("when the child has the update strategy", func() {
 				var originalVersion string
 				var originalUID types.UID
@@ -185,6 +287,11 @@ var _ = Describe("Handler Suite", func() {
 						m.Eventually(child, timeout).Should(testutils.WithResourceVersion(Not(Equal(originalVersion))))
 					})
 
+					It("should return the applied patch", func() {
+						Expect(result.appliedPatch).To(ContainSubstring("faros.pusher.com/update-strategy"))
+						Expect(result.appliedPatch).NotTo(ContainSubstring(farosclient.LastAppliedAnnotation))
+					})
+
 					It("should not replace the child", func() {
 						m.Consistently(child, consistentlyTimeout).Should(testutils.WithUID(Equal(originalUID)))
 					})
@@ -233,6 +340,141 @@ var _ = Describe("Handler Suite", func() {
 						})
 					})
 				})
+
+				Context("replace", func() {
+					BeforeEach(func() {
+						specData := testutils.ExampleDeployment.DeepCopy()
+						annotations := map[string]string{"faros.pusher.com/update-strategy": string(gittrackobjectutils.ReplaceUpdateStrategy)}
+						specData.SetAnnotations(annotations)
+						Expect(testutils.SetGitTrackObjectInterfaceSpec(gto, specData)).To(Succeed())
+
+						m.Update(gto, timeout).Should(Succeed())
+						result = r.handleGitTrackObject(gto)
+						Expect(result.inSyncError).To(BeNil())
+					})
+
+					It("should update the child", func() {
+						m.Eventually(child, timeout).Should(testutils.WithPodTemplateAnnotations(Not(HaveKey("updated"))))
+					})
+
+					It("should return the replaced fields", func() {
+						Expect(result.appliedPatch).To(ContainSubstring("faros.pusher.com/update-strategy"))
+					})
+
+					It("should not recreate the child", func() {
+						m.Consistently(child, consistentlyTimeout).Should(testutils.WithUID(Equal(originalUID)))
+					})
+				})
+
+				Context("create-only", func() {
+					BeforeEach(func() {
+						specData := testutils.ExampleDeployment.DeepCopy()
+						annotations := map[string]string{"faros.pusher.com/update-strategy": string(gittrackobjectutils.CreateOnlyUpdateStrategy)}
+						specData.SetAnnotations(annotations)
+						Expect(testutils.SetGitTrackObjectInterfaceSpec(gto, specData)).To(Succeed())
+
+						m.Update(gto, timeout).Should(Succeed())
+						result = r.handleGitTrackObject(gto)
+						Expect(result.inSyncError).To(BeNil())
+					})
+
+					It("should not update the child", func() {
+						m.Consistently(child, consistentlyTimeout).Should(testutils.WithResourceVersion(Equal(originalVersion)))
+					})
+				})
+
+				Context("update-or-recreate", func() {
+					BeforeEach(func() {
+						specData := testutils.ExampleDeployment.DeepCopy()
+						annotations := map[string]string{"faros.pusher.com/update-strategy": string(gittrackobjectutils.UpdateOrRecreateUpdateStrategy)}
+						specData.SetAnnotations(annotations)
+						Expect(testutils.SetGitTrackObjectInterfaceSpec(gto, specData)).To(Succeed())
+					})
+
+					Context("without immutable changes", func() {
+						BeforeEach(func() {
+							m.Update(gto, timeout).Should(Succeed())
+							result = r.handleGitTrackObject(gto)
+							Expect(result.inSyncError).To(BeNil())
+						})
+
+						It("should update the child", func() {
+							m.Eventually(child, timeout).Should(testutils.WithResourceVersion(Not(Equal(originalVersion))))
+						})
+
+						It("should not replace the child", func() {
+							m.Consistently(child, consistentlyTimeout).Should(testutils.WithUID(Equal(originalUID)))
+						})
+					})
+				})
+			})
+
+			Context("when the child is a Job with the update-or-recreate update strategy", func() {
+				var job *batchv1.Job
+				var originalUID types.UID
+
+				// setJob sets the Job as the data of the GitTrackObject, deleted in
+				// the background as there is no garbage collector to remove the
+				// foreground deletion finalizer
+				setJob := func(image string) {
+					specData := testutils.ExampleJob.DeepCopy()
+					specData.SetAnnotations(map[string]string{
+						"faros.pusher.com/update-strategy":      string(gittrackobjectutils.UpdateOrRecreateUpdateStrategy),
+						"faros.pusher.com/deletion-propagation": string(metav1.DeletePropagationBackground),
+					})
+					specData.Spec.Template.Spec.Containers[0].Image = image
+					Expect(testutils.SetGitTrackObjectInterfaceSpec(gto, specData)).To(Succeed())
+					m.Update(gto, timeout).Should(Succeed())
+				}
+
+				BeforeEach(func() {
+					setJob("nginx")
+					result = r.handleGitTrackObject(gto)
+					Expect(result.inSyncError).To(BeNil())
+
+					job = testutils.ExampleJob.DeepCopy()
+					m.Get(job, timeout).Should(Succeed())
+					originalUID = job.GetUID()
+				})
+
+				Context("and its template is changed", func() {
+					BeforeEach(func() {
+						setJob("nginx:latest")
+						result = r.handleGitTrackObject(gto)
+						Expect(result.inSyncError).To(BeNil())
+					})
+
+					It("should recreate the child", func() {
+						m.Eventually(job, timeout).Should(testutils.WithUID(Not(Equal(originalUID))))
+					})
+
+					It("should update the template", func() {
+						m.Eventually(job, timeout).Should(WithTransform(func(j *batchv1.Job) string {
+							return j.Spec.Template.Spec.Containers[0].Image
+						}, Equal("nginx:latest")))
+					})
+				})
+			})
+
+			Context("when the child has the create-only update strategy and does not exist", func() {
+				BeforeEach(func() {
+					specData := testutils.ExampleDeployment.DeepCopy()
+					specData.SetAnnotations(map[string]string{"faros.pusher.com/update-strategy": string(gittrackobjectutils.CreateOnlyUpdateStrategy)})
+					Expect(testutils.SetGitTrackObjectInterfaceSpec(gto, specData)).To(Succeed())
+					m.Update(gto, timeout).Should(Succeed())
+
+					result = r.handleGitTrackObject(gto)
+					Expect(result.inSyncError).To(BeNil())
+				})
+
+				It("should create the child resource", func() {
+					m.Get(child, timeout).Should(Succeed())
+				})
+
+				It("should not add an owner reference to the child", func() {
+					m.Get(child, timeout).Should(Succeed())
+					Expect(child.GetOwnerReferences()).To(BeEmpty())
+				})
 			})
 		})
 
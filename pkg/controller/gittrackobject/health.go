@@ -0,0 +1,109 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrackobject
+
+import (
+	"context"
+	"fmt"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	gittrackobjectutils "github.com/pusher/faros/pkg/controller/gittrackobject/utils"
+	farosflags "github.com/pusher/faros/pkg/flags"
+	"github.com/pusher/faros/pkg/health"
+	"github.com/pusher/faros/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// healthResult contains all information required to set the ObjectHealthy
+// condition of a (Cluster)GitTrackObject
+type healthResult struct {
+	// status is empty when the health of the child could not be determined
+	status  health.Status
+	reason  gittrackobjectutils.ConditionReason
+	message string
+}
+
+// handleHealth assesses the health of the child of the GitTrackObjectInterface
+// from the live copy of the child on the API
+func (r *ReconcileGitTrackObject) handleHealth(gto farosv1alpha1.GitTrackObjectInterface) healthResult {
+	child, err := utils.YAMLToUnstructured(gto.GetSpec().Data)
+	if err != nil {
+		return healthResult{
+			reason:  gittrackobjectutils.ErrorCheckingHealth,
+			message: fmt.Sprintf("unable to unmarshal data: %v", err),
+		}
+	}
+
+	found := &unstructured.Unstructured{}
+	found.SetKind(child.GetKind())
+	found.SetAPIVersion(child.GetAPIVersion())
+	err = r.children.Get(context.TODO(), types.NamespacedName{Name: child.GetName(), Namespace: child.GetNamespace()}, found)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			err = fmt.Errorf("child does not exist")
+		}
+		return healthResult{
+			reason:  gittrackobjectutils.ErrorCheckingHealth,
+			message: fmt.Sprintf("unable to get child %s %s: %v", gto.GetSpec().Kind, gto.GetSpec().Name, err),
+		}
+	}
+
+	res, err := r.healthChecker.Assess(found)
+	if err != nil {
+		return healthResult{
+			reason:  gittrackobjectutils.ErrorCheckingHealth,
+			message: fmt.Sprintf("unable to assess health of child %s %s: %v", gto.GetSpec().Kind, gto.GetSpec().Name, err),
+		}
+	}
+
+	result := healthResult{status: res.Status, message: res.Message}
+	switch res.Status {
+	case health.StatusHealthy:
+		result.reason = gittrackobjectutils.ChildHealthy
+	case health.StatusProgressing:
+		result.reason = gittrackobjectutils.ChildProgressing
+	case health.StatusDegraded:
+		result.reason = gittrackobjectutils.ChildDegraded
+		if !childDegraded(gto) {
+			r.sendEvent(gto, corev1.EventTypeWarning, "ChildDegraded", "Child %s %s/%s is degraded: %s", found.GetKind(), found.GetNamespace(), found.GetName(), res.Message)
+		}
+	}
+	return result
+}
+
+// newHealthChecker constructs a health Checker including any custom health
+// checks configured by the health-checks flag
+func newHealthChecker() (*health.Checker, error) {
+	if farosflags.HealthChecksFile == "" {
+		return health.NewChecker(nil)
+	}
+	checks, err := health.LoadChecks(farosflags.HealthChecksFile)
+	if err != nil {
+		return nil, err
+	}
+	return health.NewChecker(checks)
+}
+
+// childDegraded returns whether the ObjectHealthy condition of the
+// GitTrackObjectInterface already reports the child as degraded
+func childDegraded(gto farosv1alpha1.GitTrackObjectInterface) bool {
+	cond := gittrackobjectutils.GetGitTrackObjectCondition(gto.GetStatus(), farosv1alpha1.ObjectHealthyType)
+	return cond != nil && cond.Reason == string(gittrackobjectutils.ChildDegraded)
+}
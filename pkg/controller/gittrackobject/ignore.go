@@ -0,0 +1,58 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrackobject
+
+import (
+	"fmt"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	gittrackobjectutils "github.com/pusher/faros/pkg/controller/gittrackobject/utils"
+	farosclient "github.com/pusher/faros/pkg/utils/client"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// getIgnoredFields returns the paths of the fields of the child that are
+// never updated. These are configured on the controller, on the GitTrack owning
+// the GitTrackObjectInterface and on the child itself.
+func (r *ReconcileGitTrackObject) getIgnoredFields(owner *farosv1alpha1.GitTrack, child *unstructured.Unstructured) ([]string, error) {
+	return ignoredFieldsFor(r.ignoredFields, owner, child)
+}
+
+// ignoredFieldsFor returns the paths of the fields of the child that are never
+// updated, given the fields ignored by flag
+func ignoredFieldsFor(ignoredFields map[schema.GroupKind][]string, owner *farosv1alpha1.GitTrack, child *unstructured.Unstructured) ([]string, error) {
+	gk := child.GroupVersionKind().GroupKind()
+
+	fields := []string{}
+	fields = append(fields, ignoredFields[gk]...)
+	if owner != nil {
+		for _, rule := range owner.Spec.IgnoreFields {
+			if (schema.GroupKind{Group: rule.Group, Kind: rule.Kind}) == gk {
+				fields = append(fields, rule.Fields...)
+			}
+		}
+	}
+	fields = append(fields, gittrackobjectutils.GetIgnoredFields(child)...)
+
+	for _, field := range fields {
+		if _, err := farosclient.ParseFieldPath(field); err != nil {
+			return nil, fmt.Errorf("invalid ignored field: %v", err)
+		}
+	}
+	return fields, nil
+}
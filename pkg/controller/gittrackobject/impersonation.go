@@ -0,0 +1,84 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrackobject
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pusher/faros/pkg/utils"
+	farosclient "github.com/pusher/faros/pkg/utils/client"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// impersonator holds the clients used to manage children as a ServiceAccount
+type impersonator struct {
+	client  client.Client
+	applier farosclient.Client
+}
+
+// impersonators creates and stores the clients impersonating the
+// ServiceAccounts of GitTracks in a cluster, keyed by username
+type impersonators struct {
+	config  *rest.Config
+	scheme  *runtime.Scheme
+	mapper  meta.RESTMapper
+	mutex   sync.Mutex
+	clients map[string]*impersonator
+}
+
+// newImpersonators constructs an impersonators for the cluster configured by
+// the rest.Config
+func newImpersonators(config *rest.Config, scheme *runtime.Scheme, mapper meta.RESTMapper) *impersonators {
+	return &impersonators{
+		config:  config,
+		scheme:  scheme,
+		mapper:  mapper,
+		clients: make(map[string]*impersonator),
+	}
+}
+
+// get returns the clients impersonating the ServiceAccount in the namespace
+func (i *impersonators) get(namespace, serviceAccountName string) (*impersonator, error) {
+	username := utils.ServiceAccountUsername(namespace, serviceAccountName)
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if imp, ok := i.clients[username]; ok {
+		return imp, nil
+	}
+
+	config := utils.ImpersonateServiceAccount(i.config, namespace, serviceAccountName)
+
+	c, err := client.New(config, client.Options{Scheme: i.scheme, Mapper: i.mapper})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create client: %v", err)
+	}
+
+	applier, err := farosclient.NewApplier(config, farosclient.Options{Scheme: i.scheme, Mapper: i.mapper})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create applier: %v", err)
+	}
+
+	imp := &impersonator{client: c, applier: applier}
+	i.clients[username] = imp
+	return imp, nil
+}
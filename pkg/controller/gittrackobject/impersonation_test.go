@@ -0,0 +1,205 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrackobject
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	gittrackobjectutils "github.com/pusher/faros/pkg/controller/gittrackobject/utils"
+	farosflags "github.com/pusher/faros/pkg/flags"
+	farosclient "github.com/pusher/faros/pkg/utils/client"
+	testutils "github.com/pusher/faros/test/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/flowcontrol"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// forbiddenApplier is a farosclient.Client that is denied every request, as
+// the API server does when the impersonated ServiceAccount lacks permissions.
+// The test API server doesn't authorize requests so can't be used instead.
+type forbiddenApplier struct{}
+
+func (forbiddenApplier) forbidden(obj runtime.Object) error {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	return errors.NewForbidden(schema.GroupResource{Group: gvk.Group, Resource: gvk.Kind}, "", fmt.Errorf("access denied"))
+}
+
+// Apply implements the farosclient.Client interface
+func (a forbiddenApplier) Apply(_ context.Context, _ *farosclient.ApplyOptions, obj runtime.Object) error {
+	return a.forbidden(obj)
+}
+
+// Diff implements the farosclient.Client interface
+func (a forbiddenApplier) Diff(_ context.Context, _ *farosclient.ApplyOptions, obj runtime.Object) ([]byte, error) {
+	return nil, a.forbidden(obj)
+}
+
+// Replace implements the farosclient.Client interface
+func (a forbiddenApplier) Replace(_ context.Context, _ *farosclient.ApplyOptions, obj runtime.Object) error {
+	return a.forbidden(obj)
+}
+
+var _ = Describe("Impersonation Suite", func() {
+	var m testutils.Matcher
+	var r *ReconcileGitTrackObject
+	var mgr manager.Manager
+
+	var stop chan struct{}
+	var stopInformers chan struct{}
+
+	var gitTrack *farosv1alpha1.GitTrack
+	var gto *farosv1alpha1.GitTrackObject
+	var child *appsv1.Deployment
+	var reconciler *ReconcileGitTrackObject
+
+	const timeout = time.Second * 5
+
+	BeforeEach(func() {
+		var err error
+		cfg.RateLimiter = flowcontrol.NewFakeAlwaysRateLimiter()
+		mgr, err = manager.New(cfg, manager.Options{
+			Namespace:          farosflags.Namespace,
+			MetricsBindAddress: "0", // Disable serving metrics while testing
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		c, err := client.New(mgr.GetConfig(), client.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		m = testutils.Matcher{Client: c}
+
+		recFn := newReconciler(mgr)
+		r = recFn.(*ReconcileGitTrackObject)
+
+		stopInformers = r.StopChan()
+		stop = StartTestManager(mgr)
+
+		gitTrack = &farosv1alpha1.GitTrack{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "testgittrack",
+				Namespace: "default",
+			},
+			Spec: farosv1alpha1.GitTrackSpec{
+				Reference:          "foo",
+				Repository:         "bar",
+				ServiceAccountName: "deployer",
+			},
+		}
+		m.Create(gitTrack).Should(Succeed())
+
+		gto = testutils.ExampleGitTrackObject.DeepCopy()
+		child = testutils.ExampleDeployment.DeepCopy()
+		Expect(testutils.SetGitTrackObjectInterfaceSpec(gto, child)).To(Succeed())
+		Expect(controllerutil.SetControllerReference(gitTrack, gto, scheme.Scheme)).To(Succeed())
+		m.Create(gto).Should(Succeed())
+		m.Get(gto, timeout).Should(Succeed())
+
+		// Wait for the cache to sync before the owner can be found
+		Eventually(func() client.Client {
+			reconciler, err = r.forOwner(gto)
+			Expect(err).NotTo(HaveOccurred())
+			return reconciler.children
+		}, timeout).ShouldNot(BeIdenticalTo(r.children))
+	})
+
+	AfterEach(func() {
+		// Stop Controller and informers before cleaning up
+		close(stop)
+		close(stopInformers)
+		// Clean up all resources as GC is disabled in the control plane
+		testutils.DeleteAll(cfg, timeout,
+			&farosv1alpha1.GitTrackList{},
+			&farosv1alpha1.GitTrackObjectList{},
+			&appsv1.DeploymentList{},
+			&corev1.EventList{},
+		)
+	})
+
+	Context("when the GitTrack sets a ServiceAccount", func() {
+		It("impersonates the ServiceAccount", func() {
+			Expect(r.impersonators.clients).To(HaveKey("system:serviceaccount:default:deployer"))
+			imp := r.impersonators.clients["system:serviceaccount:default:deployer"]
+			Expect(reconciler.children).To(BeIdenticalTo(imp.client))
+			Expect(reconciler.applier).To(BeIdenticalTo(imp.applier))
+		})
+
+		It("reuses the impersonated clients", func() {
+			again, err := r.forOwner(gto)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(again.applier).To(BeIdenticalTo(reconciler.applier))
+		})
+
+		It("keeps watching children with Faros' own identity", func() {
+			Expect(reconciler.cache).To(BeIdenticalTo(r.cache))
+		})
+
+		It("creates the child", func() {
+			result := reconciler.handleGitTrackObject(gto)
+			Expect(result.inSyncError).NotTo(HaveOccurred())
+			m.Get(child, timeout).Should(Succeed())
+		})
+	})
+
+	Context("when the ServiceAccount isn't permitted to manage the child", func() {
+		var result handlerResult
+
+		BeforeEach(func() {
+			reconciler.applier = forbiddenApplier{}
+		})
+
+		Context("and the child does not exist", func() {
+			BeforeEach(func() {
+				result = reconciler.handleGitTrackObject(gto)
+			})
+
+			It("returns the PermissionDenied reason", func() {
+				Expect(result.inSyncError).To(HaveOccurred())
+				Expect(result.inSyncReason).To(Equal(gittrackobjectutils.PermissionDenied))
+			})
+
+			It("sends a PermissionDenied event", func() {
+				events := &corev1.EventList{}
+				m.Eventually(events, timeout).Should(testutils.WithItems(ContainElement(testutils.WithReason(Equal("PermissionDenied")))))
+			})
+		})
+
+		Context("and the child exists", func() {
+			BeforeEach(func() {
+				m.Create(child).Should(Succeed())
+				m.Get(child, timeout).Should(Succeed())
+				result = reconciler.handleGitTrackObject(gto)
+			})
+
+			It("returns the PermissionDenied reason", func() {
+				Expect(result.inSyncError).To(HaveOccurred())
+				Expect(result.inSyncReason).To(Equal(gittrackobjectutils.PermissionDenied))
+			})
+		})
+	})
+})
@@ -24,7 +24,10 @@ import (
 )
 
 type metricsOpts struct {
-	inSync bool
+	inSync        bool
+	healthy       bool
+	driftDetected bool
+	flapping      bool
 }
 
 func (r *ReconcileGitTrackObject) updateMetrics(gto farosv1alpha1.GitTrackObjectInterface, opts *metricsOpts) error {
@@ -42,5 +45,33 @@ func (r *ReconcileGitTrackObject) updateMetrics(gto farosv1alpha1.GitTrackObject
 	} else {
 		inSync.Set(0.0)
 	}
+
+	healthy, err := metrics.Healthy.GetMetricWith(labels)
+	if err != nil {
+		return fmt.Errorf("unable to update healthy metric: %v", err)
+	}
+	if opts.healthy {
+		healthy.Set(1.0)
+	} else {
+		healthy.Set(0.0)
+	}
+
+	flapping, err := metrics.Flapping.GetMetricWith(labels)
+	if err != nil {
+		return fmt.Errorf("unable to update flapping metric: %v", err)
+	}
+	if opts.flapping {
+		flapping.Set(1.0)
+	} else {
+		flapping.Set(0.0)
+	}
+
+	if opts.driftDetected {
+		drifts, err := metrics.DriftDetected.GetMetricWith(labels)
+		if err != nil {
+			return fmt.Errorf("unable to update drift metric: %v", err)
+		}
+		drifts.Inc()
+	}
 	return nil
 }
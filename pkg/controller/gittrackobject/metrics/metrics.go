@@ -30,8 +30,38 @@ var (
 		Name: "faros_gittrackobject_in_sync",
 		Help: "Shows whether a (Cluster)GitTrackObject is In Sync (boolean)",
 	}, []string{"kind", "name", "namespace"})
+
+	// Healthy is a prometheus gauge for whether the children of
+	// (Cluster)GitTrackObjects have reached their desired state or not
+	//
+	// Value should be 0 if not healthy and 1 if healthy
+	Healthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "faros_gittrackobject_healthy",
+		Help: "Shows whether the child of a (Cluster)GitTrackObject is Healthy (boolean)",
+	}, []string{"kind", "name", "namespace"})
+
+	// DriftDetected is a prometheus counter for the number of times the
+	// children of (Cluster)GitTrackObjects have been found to differ from their
+	// desired state under the Report drift policy
+	DriftDetected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "faros_gittrackobject_drift_detected_total",
+		Help: "Counts the number of times a (Cluster)GitTrackObject's child was found to have drifted",
+	}, []string{"kind", "name", "namespace"})
+
+	// Flapping is a prometheus gauge for whether the children of
+	// (Cluster)GitTrackObjects are repeatedly being changed by other
+	// controllers and reverted
+	//
+	// Value should be 0 if not flapping and 1 if flapping
+	Flapping = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "faros_gittrackobject_flapping",
+		Help: "Shows whether the child of a (Cluster)GitTrackObject is Flapping (boolean)",
+	}, []string{"kind", "name", "namespace"})
 )
 
 func init() {
 	ctrlmetrics.Registry.MustRegister(InSync)
+	ctrlmetrics.Registry.MustRegister(Healthy)
+	ctrlmetrics.Registry.MustRegister(DriftDetected)
+	ctrlmetrics.Registry.MustRegister(Flapping)
 }
@@ -49,6 +49,8 @@ var _ = Describe("Metrics Suite", func() {
 
 		// Reset all metrics before each test
 		metrics.InSync.Reset()
+		metrics.Healthy.Reset()
+		metrics.DriftDetected.Reset()
 	})
 
 	Context("updateMetrics", func() {
@@ -96,6 +98,59 @@ var _ = Describe("Metrics Suite", func() {
 					Expect(gauge.GetValue()).To(Equal(0.0))
 				})
 			})
+
+			Context("with healthy true", func() {
+				BeforeEach(func() {
+					opts.healthy = true
+					Expect(r.updateMetrics(gto, opts)).To(Succeed())
+				})
+
+				It("sets the healthy metric to 1.0", func() {
+					gauge, err := GetGauge(metrics.Healthy, gto)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(gauge.GetValue()).To(Equal(1.0))
+				})
+			})
+
+			Context("with healthy false", func() {
+				BeforeEach(func() {
+					opts.healthy = false
+					Expect(r.updateMetrics(gto, opts)).To(Succeed())
+				})
+
+				It("sets the healthy metric to 0.0", func() {
+					gauge, err := GetGauge(metrics.Healthy, gto)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(gauge.GetValue()).To(Equal(0.0))
+				})
+			})
+
+			Context("with driftDetected true", func() {
+				BeforeEach(func() {
+					opts.driftDetected = true
+					Expect(r.updateMetrics(gto, opts)).To(Succeed())
+					Expect(r.updateMetrics(gto, opts)).To(Succeed())
+				})
+
+				It("increments the drift metric", func() {
+					counter, err := GetCounter(metrics.DriftDetected, gto)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(counter.GetValue()).To(Equal(2.0))
+				})
+			})
+
+			Context("with driftDetected false", func() {
+				BeforeEach(func() {
+					opts.driftDetected = false
+					Expect(r.updateMetrics(gto, opts)).To(Succeed())
+				})
+
+				It("does not increment the drift metric", func() {
+					counter, err := GetCounter(metrics.DriftDetected, gto)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(counter.GetValue()).To(Equal(0.0))
+				})
+			})
 		})
 
 		Context("with a ClusterGitTrackObject", func() {
@@ -158,3 +213,22 @@ func GetGauge(gv *prometheus.GaugeVec, obj farosv1alpha1.GitTrackObjectInterface
 
 	return metric.GetGauge(), nil
 }
+
+func GetCounter(cv *prometheus.CounterVec, obj farosv1alpha1.GitTrackObjectInterface) (*dto.Counter, error) {
+	counter, err := cv.GetMetricWith(map[string]string{
+		"kind":      obj.GetSpec().Kind,
+		"name":      obj.GetSpec().Name,
+		"namespace": obj.GetNamespace(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var metric dto.Metric
+	err = counter.Write(&metric)
+	if err != nil {
+		return nil, err
+	}
+
+	return metric.GetCounter(), nil
+}
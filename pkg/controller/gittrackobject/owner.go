@@ -0,0 +1,86 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrackobject
+
+import (
+	"fmt"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	gittrackobjectutils "github.com/pusher/faros/pkg/controller/gittrackobject/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// getOwner returns the GitTrack controlling the GitTrackObjectInterface, or
+// nil if it is not controlled by a GitTrack
+func (r *ReconcileGitTrackObject) getOwner(gto farosv1alpha1.GitTrackObjectInterface) (*farosv1alpha1.GitTrack, error) {
+	return gittrackobjectutils.GetOwner(r, gto)
+}
+
+// checkAdoption returns an error if the existing child is not controlled by
+// the GitTrackObjectInterface and the adoption policy doesn't allow taking it
+// over. The adoption policy defaults to that of the owning GitTrack, if any.
+// An event is only sent when the refusal isn't already reported.
+func (r *ReconcileGitTrackObject) checkAdoption(gto farosv1alpha1.GitTrackObjectInterface, owner *farosv1alpha1.GitTrack, child, found *unstructured.Unstructured) (gittrackobjectutils.ConditionReason, error) {
+	err := adoptionError(gto, owner, child, found, r.cluster != nil)
+	if err == nil {
+		return "", nil
+	}
+	refused := fmt.Errorf("unable to adopt child %s %s: %v", gto.GetSpec().Kind, gto.GetSpec().Name, err)
+	previous := gittrackobjectutils.GetGitTrackObjectCondition(gto.GetStatus(), farosv1alpha1.ObjectInSyncType)
+	if previous == nil || previous.Reason != string(gittrackobjectutils.AdoptionRefused) || previous.Message != refused.Error() {
+		r.sendEvent(gto, corev1.EventTypeWarning, "AdoptionRefused", "Refused to adopt child %s %s/%s: %v", found.GetKind(), found.GetNamespace(), found.GetName(), err)
+	}
+	return gittrackobjectutils.AdoptionRefused, refused
+}
+
+// adoptionError returns the reason the existing child may not be taken over
+// by the GitTrackObjectInterface, or nil if it may. Children in remote
+// clusters record their owner in an annotation.
+func adoptionError(gto farosv1alpha1.GitTrackObjectInterface, owner *farosv1alpha1.GitTrack, child, found *unstructured.Unstructured, remote bool) error {
+	if metav1.IsControlledBy(found, gto) {
+		return nil
+	}
+	if remote && gittrackobjectutils.IsRemotelyOwnedBy(found, gto) {
+		return nil
+	}
+
+	var defaultPolicy farosv1alpha1.GitTrackAdoptionPolicy
+	if owner != nil {
+		defaultPolicy = owner.Spec.Adoption
+	}
+	policy, err := gittrackobjectutils.GetAdoptionPolicy(child, defaultPolicy)
+	if err != nil {
+		return err
+	}
+
+	controller := metav1.GetControllerOf(found)
+	remoteOwner := gittrackobjectutils.GetRemoteOwner(found)
+	switch {
+	case policy == farosv1alpha1.AdoptionAlways:
+		return nil
+	case policy == farosv1alpha1.AdoptionIfUnowned && controller == nil && remoteOwner == "":
+		return nil
+	case controller != nil:
+		return fmt.Errorf("child is controlled by %s '%s', adoption policy is %s", controller.Kind, controller.Name, policy)
+	case remoteOwner != "":
+		return fmt.Errorf("child is owned by '%s', adoption policy is %s", remoteOwner, policy)
+	default:
+		return fmt.Errorf("child was not created by Faros, adoption policy is %s", policy)
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrackobject
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	gittrackobjectutils "github.com/pusher/faros/pkg/controller/gittrackobject/utils"
+	"github.com/pusher/faros/pkg/utils"
+	testutils "github.com/pusher/faros/test/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ = Describe("GetOwner", func() {
+	var c client.Client
+	var m testutils.Matcher
+	var gt *farosv1alpha1.GitTrack
+
+	const timeout = time.Second * 5
+
+	BeforeEach(func() {
+		var err error
+		c, err = client.New(cfg, client.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		m = testutils.Matcher{Client: c}
+
+		gt = &farosv1alpha1.GitTrack{
+			ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
+			Spec: farosv1alpha1.GitTrackSpec{
+				Repository: "git@github.com:example/example.git",
+				Reference:  "master",
+			},
+		}
+		m.Create(gt).Should(Succeed())
+		m.Get(gt, timeout).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		testutils.DeleteAll(cfg, timeout, &farosv1alpha1.GitTrackList{})
+	})
+
+	It("returns the GitTrack in the namespace of a GitTrackObject", func() {
+		gto := testutils.ExampleGitTrackObject.DeepCopy()
+		Expect(controllerutil.SetControllerReference(gt, gto, scheme.Scheme)).To(Succeed())
+
+		owner, err := gittrackobjectutils.GetOwner(c, gto)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(owner).NotTo(BeNil())
+		Expect(owner.UID).To(Equal(gt.UID))
+	})
+
+	It("returns the GitTrack named by the annotation of a ClusterGitTrackObject", func() {
+		gto := testutils.ExampleClusterGitTrackObject.DeepCopy()
+		Expect(controllerutil.SetControllerReference(gt, gto, scheme.Scheme)).To(Succeed())
+		utils.SetGitTrack(gto, "default/example")
+
+		owner, err := gittrackobjectutils.GetOwner(c, gto)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(owner).NotTo(BeNil())
+		Expect(owner.UID).To(Equal(gt.UID))
+	})
+
+	It("finds the GitTrack of a ClusterGitTrackObject without the annotation", func() {
+		gto := testutils.ExampleClusterGitTrackObject.DeepCopy()
+		Expect(controllerutil.SetControllerReference(gt, gto, scheme.Scheme)).To(Succeed())
+
+		owner, err := gittrackobjectutils.GetOwner(c, gto)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(owner).NotTo(BeNil())
+		Expect(owner.UID).To(Equal(gt.UID))
+	})
+
+	It("returns nil when the GitTrack has been replaced", func() {
+		gto := testutils.ExampleGitTrackObject.DeepCopy()
+		Expect(controllerutil.SetControllerReference(gt, gto, scheme.Scheme)).To(Succeed())
+		gto.OwnerReferences[0].UID = "replaced"
+
+		owner, err := gittrackobjectutils.GetOwner(c, gto)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(owner).To(BeNil())
+	})
+})
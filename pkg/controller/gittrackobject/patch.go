@@ -0,0 +1,149 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrackobject
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	farosclient "github.com/pusher/faros/pkg/utils/client"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// maxPatchLength is the maximum length of patches recorded in the status and
+// in events
+const maxPatchLength = 1024
+
+// redactedValue replaces the values of Secrets in patches
+const redactedValue = "<redacted>"
+
+// secretGroupKind is the GroupKind of objects whose values are redacted
+var secretGroupKind = schema.GroupKind{Group: "", Kind: "Secret"}
+
+// formatPatch makes a patch applied to a child of the given kind fit for
+// display by removing the last applied annotation, redacting the values of
+// Secrets and truncating it
+func formatPatch(gk schema.GroupKind, patch []byte) (string, error) {
+	redacted, err := redactPatch(gk, patch)
+	if err != nil {
+		return "", err
+	}
+	return truncate(redacted, maxPatchLength), nil
+}
+
+// redactPatch removes the last applied annotation from a patch applied to a
+// child of the given kind and redacts the values of Secrets
+func redactPatch(gk schema.GroupKind, patch []byte) (string, error) {
+	if len(patch) == 0 {
+		return "", nil
+	}
+	diff := map[string]interface{}{}
+	if err := json.Unmarshal(patch, &diff); err != nil {
+		return "", fmt.Errorf("unable to unmarshal patch: %v", err)
+	}
+
+	redactObject(diff, gk == secretGroupKind)
+
+	if len(diff) == 0 {
+		return "", nil
+	}
+	// The redacted value would otherwise be escaped as HTML
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(diff); err != nil {
+		return "", fmt.Errorf("unable to marshal patch: %v", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// redactObject removes the last applied annotation from the object and any
+// objects nested within it, and redacts the values and last applied
+// configuration of those that are Secrets
+func redactObject(obj map[string]interface{}, secret bool) {
+	if obj["apiVersion"] == "v1" && obj["kind"] == secretGroupKind.Kind {
+		secret = true
+	}
+
+	// The last applied annotation is always updated and would leak the full
+	// configuration of the child
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+			delete(annotations, farosclient.LastAppliedAnnotation)
+			if secret {
+				redactValues(annotations, corev1.LastAppliedConfigAnnotation)
+			}
+			if len(annotations) == 0 {
+				delete(metadata, "annotations")
+			}
+		}
+		if len(metadata) == 0 {
+			delete(obj, "metadata")
+		}
+	}
+
+	if secret {
+		for _, field := range []string{"data", "stringData"} {
+			if values, ok := obj[field].(map[string]interface{}); ok {
+				redactValues(values)
+			}
+		}
+	}
+
+	for _, value := range obj {
+		redactNested(value)
+	}
+}
+
+// redactNested redacts the objects nested within the value
+func redactNested(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		redactObject(v, false)
+	case []interface{}:
+		for _, item := range v {
+			redactNested(item)
+		}
+	}
+}
+
+// redactValues replaces the given values, or all values if no keys are
+// given, unless they are being removed
+func redactValues(values map[string]interface{}, keys ...string) {
+	if len(keys) == 0 {
+		for key := range values {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range keys {
+		if value, ok := values[key]; ok && value != nil {
+			values[key] = redactedValue
+		}
+	}
+}
+
+// truncate shortens the string to at most max bytes, marking it as truncated
+func truncate(s string, max int) string {
+	const suffix = "...(truncated)"
+	if len(s) <= max {
+		return s
+	}
+	return s[:max-len(suffix)] + suffix
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrackobject
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	farosclient "github.com/pusher/faros/pkg/utils/client"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("formatPatch", func() {
+	deploymentGK := schema.GroupKind{Group: "apps", Kind: "Deployment"}
+
+	It("removes the last applied annotation", func() {
+		patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:"{}"}},"spec":{"replicas":3}}`, farosclient.LastAppliedAnnotation))
+		Expect(formatPatch(deploymentGK, patch)).To(Equal(`{"spec":{"replicas":3}}`))
+	})
+
+	It("returns nothing if only the last applied annotation changed", func() {
+		patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:"{}"}}}`, farosclient.LastAppliedAnnotation))
+		Expect(formatPatch(deploymentGK, patch)).To(BeEmpty())
+	})
+
+	It("redacts the values of Secrets", func() {
+		patch := []byte(`{"data":{"password":"c2VjcmV0","removed":null},"stringData":{"token":"secret"}}`)
+		formatted, err := formatPatch(secretGroupKind, patch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(formatted).NotTo(ContainSubstring("c2VjcmV0"))
+		Expect(formatted).NotTo(ContainSubstring(`"secret"`))
+		Expect(formatted).To(ContainSubstring(`"password":"<redacted>"`))
+		Expect(formatted).To(ContainSubstring(`"removed":null`))
+	})
+
+	It("redacts the last applied configuration of Secrets", func() {
+		patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:"{\"data\":{\"password\":\"c2VjcmV0\"}}","owner":"team"}}}`, corev1.LastAppliedConfigAnnotation))
+		formatted, err := formatPatch(secretGroupKind, patch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(formatted).NotTo(ContainSubstring("c2VjcmV0"))
+		Expect(formatted).To(ContainSubstring(fmt.Sprintf(`%q:"<redacted>"`, corev1.LastAppliedConfigAnnotation)))
+		Expect(formatted).To(ContainSubstring(`"owner":"team"`))
+	})
+
+	It("redacts Secrets nested within the patch", func() {
+		patch := []byte(fmt.Sprintf(`{"items":[{"apiVersion":"v1","kind":"Secret","metadata":{"annotations":{%q:"{}"}},"data":{"password":"c2VjcmV0"}}]}`, farosclient.LastAppliedAnnotation))
+		formatted, err := formatPatch(schema.GroupKind{Kind: "List"}, patch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(formatted).To(Equal(`{"items":[{"apiVersion":"v1","data":{"password":"<redacted>"},"kind":"Secret"}]}`))
+	})
+
+	It("returns nothing for an empty patch", func() {
+		Expect(formatPatch(deploymentGK, nil)).To(BeEmpty())
+	})
+
+	It("does not redact the values of other kinds", func() {
+		patch := []byte(`{"data":{"key":"value"}}`)
+		Expect(formatPatch(schema.GroupKind{Kind: "ConfigMap"}, patch)).To(Equal(`{"data":{"key":"value"}}`))
+	})
+
+	It("truncates long patches", func() {
+		patch := []byte(fmt.Sprintf(`{"data":{"key":%q}}`, strings.Repeat("a", 2*maxPatchLength)))
+		formatted, err := formatPatch(schema.GroupKind{Kind: "ConfigMap"}, patch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(formatted).To(HaveLen(maxPatchLength))
+		Expect(formatted).To(HaveSuffix("...(truncated)"))
+	})
+})
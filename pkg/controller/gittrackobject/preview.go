@@ -0,0 +1,183 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrackobject
+
+import (
+	"context"
+	"fmt"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	gittrackobjectutils "github.com/pusher/faros/pkg/controller/gittrackobject/utils"
+	farosclient "github.com/pusher/faros/pkg/utils/client"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// PreviewAction is the change applying a child would make to it
+type PreviewAction string
+
+const (
+	// PreviewCreate is the PreviewAction of children that don't exist yet
+	PreviewCreate PreviewAction = "create"
+
+	// PreviewUpdate is the PreviewAction of children that differ from their
+	// desired state
+	PreviewUpdate PreviewAction = "update"
+
+	// PreviewUnchanged is the PreviewAction of children that match their
+	// desired state
+	PreviewUnchanged PreviewAction = "unchanged"
+)
+
+// ChildPreview describes the change the GitTrackObject controller would make
+// to a child
+type ChildPreview struct {
+	Action PreviewAction
+
+	// Fields are the sorted paths of the fields an update changes
+	Fields []string
+
+	// Patch is the patch an update sends, with the values of Secrets redacted
+	Patch string
+
+	// Reported is true when the drift policy of the child reports the update
+	// rather than applying it, as the data of the GitTrackObject was already
+	// applied and the child has since been changed outside of Git
+	Reported bool
+}
+
+// PreviewChild computes the change applying the child of the GitTrackObject
+// would make. The child is built the way the controller builds it, with the
+// GitTrackObject as its controller, and the update strategy, adoption policy,
+// apply options and ignored fields the controller uses. If dryRun is true, the
+// change is validated by applying it with a server dry run.
+func PreviewChild(c client.Reader, applier farosclient.Client, scheme *runtime.Scheme, owner *farosv1alpha1.GitTrack, gto farosv1alpha1.GitTrackObjectInterface, ignoredFields map[schema.GroupKind][]string, dryRun bool) (ChildPreview, error) {
+	child, _, err := childFromGitTrackObject(gto)
+	if err != nil {
+		return ChildPreview{}, err
+	}
+	updateStrategy, err := gittrackobjectutils.GetUpdateStrategy(child)
+	if err != nil {
+		return ChildPreview{}, fmt.Errorf("unable to get update strategy: %v", err)
+	}
+	createOnly := updateStrategy == gittrackobjectutils.CreateOnlyUpdateStrategy
+	if !createOnly {
+		if err = controllerutil.SetControllerReference(gto, child, scheme); err != nil {
+			return ChildPreview{}, fmt.Errorf("unable to add owner reference: %v", err)
+		}
+	}
+
+	opts, err := applyOptions(child)
+	if err != nil {
+		return ChildPreview{}, fmt.Errorf("unable to get apply options: %v", err)
+	}
+	opts.IgnoreFields, err = ignoredFieldsFor(ignoredFields, owner, child)
+	if err != nil {
+		return ChildPreview{}, fmt.Errorf("unable to get ignored fields: %v", err)
+	}
+
+	found := &unstructured.Unstructured{}
+	found.SetGroupVersionKind(child.GroupVersionKind())
+	err = c.Get(context.TODO(), types.NamespacedName{Name: child.GetName(), Namespace: child.GetNamespace()}, found)
+	if err != nil && errors.IsNotFound(err) {
+		return previewCreate(applier, opts, child, dryRun)
+	} else if err != nil {
+		return ChildPreview{}, fmt.Errorf("unable to get child: %v", err)
+	}
+
+	// Existing children with the create-only update strategy are never touched
+	if createOnly {
+		return ChildPreview{Action: PreviewUnchanged}, nil
+	}
+	if err = adoptionError(gto, owner, child, found, false); err != nil {
+		return ChildPreview{}, fmt.Errorf("unable to adopt child: %v", err)
+	}
+	// Children with the never update strategy only have the owner reference
+	// added
+	applied := child
+	if updateStrategy == gittrackobjectutils.NeverUpdateStrategy {
+		applied = found.DeepCopy()
+		if err = controllerutil.SetControllerReference(gto, applied, scheme); err != nil {
+			return ChildPreview{}, fmt.Errorf("unable to add owner reference: %v", err)
+		}
+		opts.IgnoreFields = nil
+	}
+
+	patch, err := applier.Diff(context.TODO(), &opts, applied.DeepCopy())
+	if err != nil {
+		return ChildPreview{}, fmt.Errorf("unable to compare child to desired state: %v", err)
+	}
+	preview, err := previewUpdate(owner, gto, child, patch)
+	if err != nil {
+		return ChildPreview{}, err
+	}
+
+	if !dryRun || preview.Action == PreviewUnchanged || preview.Reported {
+		return preview, nil
+	}
+	return preview, dryRunApply(applier, opts, applied)
+}
+
+// previewCreate describes the creation of a child that doesn't exist yet
+func previewCreate(applier farosclient.Client, opts farosclient.ApplyOptions, child *unstructured.Unstructured, dryRun bool) (ChildPreview, error) {
+	preview := ChildPreview{Action: PreviewCreate}
+	if !dryRun {
+		return preview, nil
+	}
+	return preview, dryRunApply(applier, opts, child)
+}
+
+// dryRunApply validates applying the child with a server dry run
+func dryRunApply(applier farosclient.Client, opts farosclient.ApplyOptions, child *unstructured.Unstructured) error {
+	dryRunTrue := true
+	opts.ServerDryRun = &dryRunTrue
+	if err := applier.Apply(context.TODO(), &opts, child.DeepCopy()); err != nil {
+		return fmt.Errorf("server dry run failed: %v", err)
+	}
+	return nil
+}
+
+// previewUpdate describes the update of an existing child by the patch
+func previewUpdate(owner *farosv1alpha1.GitTrack, gto farosv1alpha1.GitTrackObjectInterface, child *unstructured.Unstructured, patch []byte) (ChildPreview, error) {
+	fields, err := driftedFields(patch)
+	if err != nil {
+		return ChildPreview{}, fmt.Errorf("unable to summarize difference: %v", err)
+	}
+	if len(fields) == 0 {
+		return ChildPreview{Action: PreviewUnchanged}, nil
+	}
+
+	redacted, err := redactPatch(child.GroupVersionKind().GroupKind(), patch)
+	if err != nil {
+		return ChildPreview{}, fmt.Errorf("unable to format difference: %v", err)
+	}
+	driftPolicy, err := getDriftPolicy(owner, child)
+	if err != nil {
+		return ChildPreview{}, fmt.Errorf("unable to get drift policy: %v", err)
+	}
+	return ChildPreview{
+		Action:   PreviewUpdate,
+		Fields:   fields,
+		Patch:    redacted,
+		Reported: driftPolicy == farosv1alpha1.DriftPolicyReport && gittrackobjectutils.DataApplied(gto),
+	}, nil
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrackobject
+
+import (
+	"context"
+	"fmt"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	gittrackobjectutils "github.com/pusher/faros/pkg/controller/gittrackobject/utils"
+	"github.com/pusher/faros/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// forOwner returns a reconciler that manages the child of the
+// GitTrackObjectInterface in the remote cluster of its owning GitTrack, if the
+// GitTrack deploys to a remote cluster, and as the ServiceAccount of the
+// GitTrack, if the GitTrack sets one
+func (r *ReconcileGitTrackObject) forOwner(gto farosv1alpha1.GitTrackObjectInterface) (*ReconcileGitTrackObject, error) {
+	owner, err := r.getOwner(gto)
+	if err != nil {
+		return r, fmt.Errorf("unable to get owner: %v", err)
+	}
+	if owner == nil || (owner.Spec.Cluster == nil && owner.Spec.ServiceAccountName == "") {
+		return r, nil
+	}
+
+	reconciler := *r
+	impersonators := r.impersonators
+	if owner.Spec.Cluster != nil {
+		cluster, err := r.clusters.get(owner.GetNamespace(), owner.Spec.Cluster)
+		if err != nil {
+			return r, fmt.Errorf("unable to connect to cluster: %v", err)
+		}
+
+		reconciler.cluster = cluster
+		reconciler.children = cluster.client
+		reconciler.cache = cluster.cache
+		reconciler.informers = cluster.informers
+		reconciler.eventStream = r.remoteEventStream
+		reconciler.applier = cluster.applier
+		reconciler.dryRunVerifier = cluster.dryRunVerifier
+		reconciler.serverSideApplyVerifier = cluster.serverSideApplyVerifier
+		reconciler.log = reconciler.log.WithValues("cluster", cluster.key)
+		impersonators = cluster.impersonators
+	}
+
+	// Children are still watched with Faros' own identity so that a single
+	// informer serves every GitTrack
+	if owner.Spec.ServiceAccountName != "" {
+		imp, err := impersonators.get(owner.GetNamespace(), owner.Spec.ServiceAccountName)
+		if err != nil {
+			return r, fmt.Errorf("unable to impersonate ServiceAccount %s: %v", owner.Spec.ServiceAccountName, err)
+		}
+
+		reconciler.children = imp.client
+		reconciler.applier = imp.applier
+		reconciler.log = reconciler.log.WithValues("service account", owner.Spec.ServiceAccountName)
+	}
+	return &reconciler, nil
+}
+
+// ensureRemoteFinalizer adds the remote child finalizer and the cluster
+// annotation to a GitTrackObjectInterface managing a child in a remote cluster
+func (r *ReconcileGitTrackObject) ensureRemoteFinalizer(gto farosv1alpha1.GitTrackObjectInterface) error {
+	annotations := gto.GetAnnotations()
+	if hasFinalizer(gto, utils.RemoteChildFinalizer) && annotations[clusterAnnotation] == r.cluster.key {
+		return nil
+	}
+
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[clusterAnnotation] = r.cluster.key
+	gto.SetAnnotations(annotations)
+	if !hasFinalizer(gto, utils.RemoteChildFinalizer) {
+		gto.SetFinalizers(append(gto.GetFinalizers(), utils.RemoteChildFinalizer))
+	}
+	if err := r.Update(context.TODO(), gto); err != nil {
+		return fmt.Errorf("unable to update finalizers: %v", err)
+	}
+	return nil
+}
+
+// handleRemoteDeletion deletes the child of a GitTrackObjectInterface being
+// deleted from its remote cluster, unless the child is being orphaned, before
+// allowing the GitTrackObjectInterface to be deleted
+func (r *ReconcileGitTrackObject) handleRemoteDeletion(gto farosv1alpha1.GitTrackObjectInterface) error {
+	// The orphan finalizer is added when the GitTrackObjectInterface is deleted
+	// with the orphan propagation policy
+	if !hasFinalizer(gto, metav1.FinalizerOrphanDependents) {
+		if err := r.deleteRemoteChild(gto); err != nil {
+			return err
+		}
+	}
+
+	removeFinalizer(gto, utils.RemoteChildFinalizer)
+	if err := r.Update(context.TODO(), gto); err != nil {
+		return fmt.Errorf("unable to update finalizers: %v", err)
+	}
+	return nil
+}
+
+// deleteRemoteChild deletes the child of the GitTrackObjectInterface from the
+// remote cluster recorded in its cluster annotation, if the child is still
+// owned by the GitTrackObjectInterface and isn't protected from deletion
+func (r *ReconcileGitTrackObject) deleteRemoteChild(gto farosv1alpha1.GitTrackObjectInterface) error {
+	namespace, ref, err := parseClusterKey(gto.GetAnnotations()[clusterAnnotation])
+	if err != nil {
+		return err
+	}
+	cluster, err := r.clusters.get(namespace, ref)
+	if err != nil {
+		r.sendEvent(gto, corev1.EventTypeWarning, "DeleteFailed", "Failed to connect to cluster of child %s %s: %v", gto.GetSpec().Kind, gto.GetSpec().Name, err)
+		return fmt.Errorf("unable to connect to cluster: %v", err)
+	}
+
+	child, err := utils.YAMLToUnstructured(gto.GetSpec().Data)
+	if err != nil {
+		return fmt.Errorf("unable to unmarshal data: %v", err)
+	}
+	protected, err := utils.DeleteProtected(&child, r.protectedKinds)
+	if err != nil {
+		return fmt.Errorf("unable to check delete protection: %v", err)
+	}
+	if protected {
+		r.log.V(0).Info("Child is protected from deletion, orphaning child")
+		return nil
+	}
+
+	// Delete the child as the ServiceAccount of the owning GitTrack, if it
+	// still exists
+	children := cluster.client
+	owner, err := r.getOwner(gto)
+	if err != nil {
+		return fmt.Errorf("unable to get owner: %v", err)
+	}
+	if owner != nil && owner.Spec.ServiceAccountName != "" {
+		imp, err := cluster.impersonators.get(owner.GetNamespace(), owner.Spec.ServiceAccountName)
+		if err != nil {
+			return fmt.Errorf("unable to impersonate ServiceAccount %s: %v", owner.Spec.ServiceAccountName, err)
+		}
+		children = imp.client
+	}
+
+	found := &unstructured.Unstructured{}
+	found.SetGroupVersionKind(child.GroupVersionKind())
+	err = children.Get(context.TODO(), types.NamespacedName{Name: child.GetName(), Namespace: child.GetNamespace()}, found)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to get child: %v", err)
+	}
+	if !gittrackobjectutils.IsRemotelyOwnedBy(found, gto) {
+		r.log.V(1).Info("Child is not owned by GitTrackObject, skipping deletion")
+		return nil
+	}
+
+	opts := []client.DeleteOptionFunc{}
+	propagation, err := utils.GetDeletionPropagation(&child)
+	if err != nil {
+		return fmt.Errorf("unable to get deletion propagation: %v", err)
+	}
+	if propagation != nil {
+		opts = append(opts, client.PropagationPolicy(*propagation))
+	}
+	err = children.Delete(context.TODO(), found, opts...)
+	if errors.IsForbidden(err) {
+		r.sendEvent(gto, corev1.EventTypeWarning, "PermissionDenied", "Not permitted to delete child %s %s/%s: %v", found.GetKind(), found.GetNamespace(), found.GetName(), err)
+		return fmt.Errorf("unable to delete child: %v", err)
+	} else if err != nil && !errors.IsNotFound(err) {
+		r.sendEvent(gto, corev1.EventTypeWarning, "DeleteFailed", "Failed to delete child %s %s/%s", found.GetKind(), found.GetNamespace(), found.GetName())
+		return fmt.Errorf("unable to delete child: %v", err)
+	}
+
+	r.log.V(0).Info("Child deleted")
+	r.sendEvent(gto, corev1.EventTypeNormal, "DeleteSuccessful", "Successfully deleted child %s %s/%s", found.GetKind(), found.GetNamespace(), found.GetName())
+	return nil
+}
+
+// hasFinalizer returns whether the object has the given finalizer
+func hasFinalizer(obj metav1.Object, finalizer string) bool {
+	for _, f := range obj.GetFinalizers() {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// removeFinalizer removes the given finalizer from the object
+func removeFinalizer(obj metav1.Object, finalizer string) {
+	finalizers := []string{}
+	for _, f := range obj.GetFinalizers() {
+		if f != finalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	obj.SetFinalizers(finalizers)
+}
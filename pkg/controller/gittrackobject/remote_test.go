@@ -0,0 +1,234 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrackobject
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	gittrackobjectutils "github.com/pusher/faros/pkg/controller/gittrackobject/utils"
+	farosflags "github.com/pusher/faros/pkg/flags"
+	"github.com/pusher/faros/pkg/utils"
+	farosclient "github.com/pusher/faros/pkg/utils/client"
+	testutils "github.com/pusher/faros/test/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/util/flowcontrol"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var _ = Describe("Remote Cluster Suite", func() {
+	var m, remoteM testutils.Matcher
+	var r *ReconcileGitTrackObject
+	var mgr manager.Manager
+
+	var stop chan struct{}
+	var stopInformers chan struct{}
+
+	var gitTrack *farosv1alpha1.GitTrack
+	var gto *farosv1alpha1.GitTrackObject
+	var child *appsv1.Deployment
+
+	const timeout = time.Second * 5
+	const consistentlyTimeout = time.Second
+
+	BeforeEach(func() {
+		var err error
+		cfg.RateLimiter = flowcontrol.NewFakeAlwaysRateLimiter()
+		mgr, err = manager.New(cfg, manager.Options{
+			Namespace:          farosflags.Namespace,
+			MetricsBindAddress: "0", // Disable serving metrics while testing
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		c, err := client.New(mgr.GetConfig(), client.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		m = testutils.Matcher{Client: c}
+
+		remoteClient, err := client.New(remoteCfg, client.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		remoteApplier, err := farosclient.NewApplier(remoteCfg, farosclient.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		remoteM = testutils.Matcher{Client: remoteClient, FarosClient: remoteApplier}
+
+		recFn := newReconciler(mgr)
+		r = recFn.(*ReconcileGitTrackObject)
+
+		stopInformers = r.StopChan()
+		stop = StartTestManager(mgr)
+
+		// Store a kubeconfig for the remote API server
+		kubeconfig, err := clientcmd.Write(clientcmdapi.Config{
+			Clusters:       map[string]*clientcmdapi.Cluster{"remote": {Server: remoteCfg.Host}},
+			Contexts:       map[string]*clientcmdapi.Context{"remote": {Cluster: "remote"}},
+			CurrentContext: "remote",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "remote-cluster", Namespace: "default"},
+			Data:       map[string][]byte{utils.DefaultKubeconfigKey: kubeconfig},
+		}
+		m.Create(secret).Should(Succeed())
+
+		gitTrack = &farosv1alpha1.GitTrack{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "testgittrack",
+				Namespace: "default",
+			},
+			Spec: farosv1alpha1.GitTrackSpec{
+				Reference:  "foo",
+				Repository: "bar",
+				Cluster:    &farosv1alpha1.GitTrackCluster{SecretName: "remote-cluster"},
+			},
+		}
+		m.Create(gitTrack).Should(Succeed())
+
+		gto = testutils.ExampleGitTrackObject.DeepCopy()
+		child = testutils.ExampleDeployment.DeepCopy()
+		Expect(testutils.SetGitTrackObjectInterfaceSpec(gto, child)).To(Succeed())
+		Expect(controllerutil.SetControllerReference(gitTrack, gto, scheme.Scheme)).To(Succeed())
+		m.Create(gto).Should(Succeed())
+		m.Get(gto, timeout).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		// Stop Controller and informers before cleaning up
+		close(stop)
+		close(stopInformers)
+
+		// Remove the finalizers from the GitTrackObject so that it can be
+		// deleted
+		Eventually(func() error {
+			err := m.Client.Get(context.TODO(), types.NamespacedName{Name: gto.Name, Namespace: gto.Namespace}, gto)
+			if err != nil {
+				return err
+			}
+			gto.SetFinalizers(nil)
+			return m.Client.Update(context.TODO(), gto)
+		}, timeout).Should(Succeed())
+
+		// Clean up all resources as GC is disabled in the control plane
+		testutils.DeleteAll(cfg, timeout,
+			&farosv1alpha1.GitTrackList{},
+			&farosv1alpha1.GitTrackObjectList{},
+			&appsv1.DeploymentList{},
+			&corev1.SecretList{},
+			&corev1.EventList{},
+		)
+		testutils.DeleteAll(remoteCfg, timeout,
+			&appsv1.DeploymentList{},
+		)
+	})
+
+	Context("when the GitTrack deploys to a remote cluster", func() {
+		var reconciler *ReconcileGitTrackObject
+		var result handlerResult
+
+		BeforeEach(func() {
+			// Wait for the cache to sync before the owner can be found
+			Eventually(func() *remoteCluster {
+				reconciler, _ = r.forOwner(gto)
+				return reconciler.cluster
+			}, timeout).ShouldNot(BeNil())
+
+			result = reconciler.handleGitTrackObject(gto)
+			Expect(result.inSyncError).ToNot(HaveOccurred())
+		})
+
+		It("creates the child in the remote cluster", func() {
+			remoteM.Get(child, timeout).Should(Succeed())
+		})
+
+		It("doesn't create the child in the local cluster", func() {
+			local := child.DeepCopy()
+			Consistently(func() error {
+				return m.Client.Get(context.TODO(), types.NamespacedName{Name: local.Name, Namespace: local.Namespace}, local)
+			}, consistentlyTimeout).ShouldNot(Succeed())
+		})
+
+		It("records the owner of the child in an annotation", func() {
+			remoteM.Get(child, timeout).Should(Succeed())
+			Expect(child.GetOwnerReferences()).To(BeEmpty())
+			Expect(child.GetAnnotations()).To(HaveKeyWithValue(gittrackobjectutils.RemoteOwnerAnnotation, "default/example"))
+		})
+
+		It("adds the remote child finalizer to the GitTrackObject", func() {
+			m.Eventually(gto, timeout).Should(testutils.WithFinalizers(ContainElement(utils.RemoteChildFinalizer)))
+			m.Eventually(gto, timeout).Should(testutils.WithAnnotations(HaveKeyWithValue(clusterAnnotation, "default/remote-cluster/kubeconfig")))
+		})
+
+		It("reverts changes made to the child in the remote cluster", func() {
+			remoteM.Get(child, timeout).Should(Succeed())
+			child.Spec.Template.Spec.Containers[0].Image = "nginx:latest"
+			remoteM.Update(child, timeout).Should(Succeed())
+
+			result = reconciler.handleGitTrackObject(gto)
+			Expect(result.inSyncError).ToNot(HaveOccurred())
+			remoteM.Eventually(child, timeout).Should(testutils.WithContainers(ContainElement(testutils.WithImage(Equal("nginx")))))
+		})
+
+		Context("and the child has the never update strategy", func() {
+			BeforeEach(func() {
+				remoteM.Get(child, timeout).Should(Succeed())
+				m.Get(gto, timeout).Should(Succeed())
+				specData := testutils.ExampleDeployment.DeepCopy()
+				specData.SetAnnotations(map[string]string{"faros.pusher.com/update-strategy": string(gittrackobjectutils.NeverUpdateStrategy)})
+				Expect(testutils.SetGitTrackObjectInterfaceSpec(gto, specData)).To(Succeed())
+
+				result = reconciler.handleGitTrackObject(gto)
+				Expect(result.inSyncError).ToNot(HaveOccurred())
+			})
+
+			It("doesn't add an owner reference to the child", func() {
+				remoteM.Consistently(child, consistentlyTimeout).Should(testutils.WithOwnerReferences(BeEmpty()))
+				Expect(child.GetAnnotations()).To(HaveKeyWithValue(gittrackobjectutils.RemoteOwnerAnnotation, "default/example"))
+			})
+		})
+
+		Context("and the GitTrackObject is deleted", func() {
+			BeforeEach(func() {
+				m.Get(gto, timeout).Should(Succeed())
+				remoteM.Get(child, timeout).Should(Succeed())
+			})
+
+			It("deletes the child from the remote cluster", func() {
+				Expect(r.handleRemoteDeletion(gto)).To(Succeed())
+				Eventually(func() error {
+					return remoteM.Client.Get(context.TODO(), types.NamespacedName{Name: child.Name, Namespace: child.Namespace}, child)
+				}, timeout).ShouldNot(Succeed())
+				m.Eventually(gto, timeout).ShouldNot(testutils.WithFinalizers(ContainElement(utils.RemoteChildFinalizer)))
+			})
+
+			It("keeps the child when it is orphaned", func() {
+				gto.SetFinalizers(append(gto.GetFinalizers(), metav1.FinalizerOrphanDependents))
+				Expect(r.handleRemoteDeletion(gto)).To(Succeed())
+				remoteM.Consistently(child, consistentlyTimeout).Should(testutils.WithAnnotations(HaveKey(gittrackobjectutils.RemoteOwnerAnnotation)))
+				m.Eventually(gto, timeout).ShouldNot(testutils.WithFinalizers(ContainElement(utils.RemoteChildFinalizer)))
+			})
+		})
+	})
+})
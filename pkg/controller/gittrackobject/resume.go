@@ -0,0 +1,105 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gittrackobject
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// resumedPredicate filters events for GitTracks down to the updates resuming
+// a suspended GitTrack
+type resumedPredicate struct{}
+
+// Create returns false as a new GitTrack has no children to resume
+func (resumedPredicate) Create(event.CreateEvent) bool {
+	return false
+}
+
+// Update returns true if the update unsets the suspend field of the GitTrack
+func (resumedPredicate) Update(e event.UpdateEvent) bool {
+	old, ok := e.ObjectOld.(*farosv1alpha1.GitTrack)
+	if !ok {
+		return false
+	}
+	updated, ok := e.ObjectNew.(*farosv1alpha1.GitTrack)
+	if !ok {
+		return false
+	}
+	return old.Spec.Suspend && !updated.Spec.Suspend
+}
+
+// Delete returns false as a deleted GitTrack has no children to resume
+func (resumedPredicate) Delete(event.DeleteEvent) bool {
+	return false
+}
+
+// Generic returns false as generic events don't resume a GitTrack
+func (resumedPredicate) Generic(event.GenericEvent) bool {
+	return false
+}
+
+// enqueueRequestsForControlled returns an event handler queuing the
+// (Cluster)GitTrackObjects controlled by the GitTrack that was the source of
+// the event, so that a resumed GitTrack reverts changes made to its children
+// while it was suspended
+func enqueueRequestsForControlled(c client.Reader, log logr.Logger) handler.EventHandler {
+	return &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+			requests, err := controlledRequests(c, obj.Meta)
+			if err != nil {
+				log.Error(err, "unable to list children of GitTrack", "namespace", obj.Meta.GetNamespace(), "name", obj.Meta.GetName())
+			}
+			return requests
+		}),
+	}
+}
+
+// controlledRequests returns Requests for the (Cluster)GitTrackObjects
+// controlled by the owner
+func controlledRequests(c client.Reader, owner metav1.Object) ([]reconcile.Request, error) {
+	requests := []reconcile.Request{}
+
+	gtoList := &farosv1alpha1.GitTrackObjectList{}
+	if err := c.List(context.TODO(), gtoList, client.InNamespace(owner.GetNamespace())); err != nil {
+		return requests, err
+	}
+	for i := range gtoList.Items {
+		if metav1.IsControlledBy(&gtoList.Items[i], owner) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: gtoList.Items[i].Namespace, Name: gtoList.Items[i].Name}})
+		}
+	}
+
+	cgtoList := &farosv1alpha1.ClusterGitTrackObjectList{}
+	if err := c.List(context.TODO(), cgtoList); err != nil {
+		return requests, err
+	}
+	for i := range cgtoList.Items {
+		if metav1.IsControlledBy(&cgtoList.Items[i], owner) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: cgtoList.Items[i].Name}})
+		}
+	}
+	return requests, nil
+}
@@ -23,17 +23,30 @@ import (
 
 	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
 	gittrackobjectutils "github.com/pusher/faros/pkg/controller/gittrackobject/utils"
+	"github.com/pusher/faros/pkg/health"
 	v1 "k8s.io/api/core/v1"
 )
 
 type statusOpts struct {
 	inSyncError  error
 	inSyncReason gittrackobjectutils.ConditionReason
+	health       healthResult
+	drift        driftResult
+	flap         flapResult
+	appliedPatch string
+
+	// applied is true when the data of the (Cluster)GitTrackObject was
+	// applied to the child by this reconcile
+	applied bool
+
+	// handledReconcileAt is the token of the requested reconcile handled by
+	// this reconcile, if any
+	handledReconcileAt string
 }
 
-func (s *statusOpts) isEmpty() bool {
-	empty := &statusOpts{}
-	return *s == *empty
+// inSyncIsEmpty returns whether the inSync options have not been set
+func (s *statusOpts) inSyncIsEmpty() bool {
+	return s.inSyncError == nil && s.inSyncReason == ""
 }
 
 // updateGitTrackObjectStatus updates the GitTrackObject's status field if
@@ -41,6 +54,34 @@ func (s *statusOpts) isEmpty() bool {
 func updateGitTrackObjectStatus(gto farosv1alpha1.GitTrackObjectInterface, opts *statusOpts) bool {
 	status := gto.GetStatus()
 	setCondition(&status, farosv1alpha1.ObjectInSyncType, opts.inSyncError, opts.inSyncReason)
+	if opts.health.reason != "" {
+		setHealthCondition(&status, opts.health)
+		// Record the data the health of the child was assessed for, so that
+		// the GitTrack doesn't count the child as healthy for newer data
+		status.ObservedDataHash = gittrackobjectutils.DataHash(gto)
+	}
+	if opts.appliedPatch != "" {
+		status.LastAppliedPatch = opts.appliedPatch
+	}
+	if opts.applied {
+		// Record the data applied to the child, so that drift is only
+		// reported, when it is, against the data last applied
+		status.AppliedDataHash = gittrackobjectutils.DataHash(gto)
+	}
+	if opts.handledReconcileAt != "" {
+		status.LastHandledReconcileAt = opts.handledReconcileAt
+	}
+	status.DriftPatch = opts.drift.patch
+	if opts.drift.reason != "" {
+		setDriftCondition(&status, opts.drift)
+	} else {
+		gittrackobjectutils.RemoveGitTrackObjectCondition(&status, farosv1alpha1.ObjectDriftedType)
+	}
+	if opts.flap.flapping {
+		setFlappingCondition(&status, opts.flap)
+	} else {
+		gittrackobjectutils.RemoveGitTrackObjectCondition(&status, farosv1alpha1.ObjectFlappingType)
+	}
 
 	if !reflect.DeepEqual(gto.GetStatus(), status) {
 		gto.SetStatus(status)
@@ -72,11 +113,60 @@ func setCondition(status *farosv1alpha1.GitTrackObjectStatus, condType farosv1al
 	gittrackobjectutils.SetGitTrackObjectCondition(status, *cond)
 }
 
+// setHealthCondition sets the ObjectHealthy condition from the result of a
+// health assessment
+func setHealthCondition(status *farosv1alpha1.GitTrackObjectStatus, result healthResult) {
+	condStatus := v1.ConditionUnknown
+	switch result.status {
+	case health.StatusHealthy:
+		condStatus = v1.ConditionTrue
+	case health.StatusProgressing, health.StatusDegraded:
+		condStatus = v1.ConditionFalse
+	}
+	cond := gittrackobjectutils.NewGitTrackObjectCondition(
+		farosv1alpha1.ObjectHealthyType,
+		condStatus,
+		result.reason,
+		result.message,
+	)
+	gittrackobjectutils.SetGitTrackObjectCondition(status, *cond)
+}
+
+// setDriftCondition sets the ObjectDrifted condition from the result of
+// comparing the child to its desired state
+func setDriftCondition(status *farosv1alpha1.GitTrackObjectStatus, result driftResult) {
+	condStatus := v1.ConditionFalse
+	if result.drifted {
+		condStatus = v1.ConditionTrue
+	} else if result.reason == gittrackobjectutils.ErrorCheckingDrift {
+		condStatus = v1.ConditionUnknown
+	}
+	cond := gittrackobjectutils.NewGitTrackObjectCondition(
+		farosv1alpha1.ObjectDriftedType,
+		condStatus,
+		result.reason,
+		result.message,
+	)
+	gittrackobjectutils.SetGitTrackObjectCondition(status, *cond)
+}
+
+// setFlappingCondition sets the ObjectFlapping condition for a child that is
+// repeatedly being reverted
+func setFlappingCondition(status *farosv1alpha1.GitTrackObjectStatus, result flapResult) {
+	cond := gittrackobjectutils.NewGitTrackObjectCondition(
+		farosv1alpha1.ObjectFlappingType,
+		v1.ConditionTrue,
+		gittrackobjectutils.ChildFlapping,
+		result.message,
+	)
+	gittrackobjectutils.SetGitTrackObjectCondition(status, *cond)
+}
+
 // updateStatus calculates a new status for the GitTrackObject and then updates
 // the resource on the API if the status differs from before.
 func (r *ReconcileGitTrackObject) updateStatus(original farosv1alpha1.GitTrackObjectInterface, opts *statusOpts) error {
 	// Default inSyncReason if opts are empty
-	if opts.isEmpty() {
+	if opts.inSyncIsEmpty() {
 		opts.inSyncReason = gittrackobjectutils.ChildAppliedSuccess
 	}
 	gto := original.DeepCopyInterface()
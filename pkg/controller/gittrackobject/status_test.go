@@ -25,6 +25,7 @@ import (
 	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
 	gittrackobjectutils "github.com/pusher/faros/pkg/controller/gittrackobject/utils"
 	farosflags "github.com/pusher/faros/pkg/flags"
+	"github.com/pusher/faros/pkg/health"
 	testutils "github.com/pusher/faros/test/utils"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -144,6 +145,105 @@ var _ = Describe("Status Suite", func() {
 					)
 				})
 			})
+
+			Context("with a healthy child", func() {
+				BeforeEach(func() {
+					opts.health = healthResult{status: health.StatusHealthy, reason: gittrackobjectutils.ChildHealthy}
+					r.updateStatus(gto, opts)
+				})
+
+				It("should set the healthy condition", func() {
+					m.Eventually(gto).Should(
+						testutils.WithGitTrackObjectStatusConditions(
+							ContainElement(
+								SatisfyAll(
+									testutils.WithGitTrackObjectConditionType(Equal(farosv1alpha1.ObjectHealthyType)),
+									testutils.WithGitTrackObjectConditionStatus(Equal(corev1.ConditionTrue)),
+									testutils.WithGitTrackObjectConditionReason(Equal(string(gittrackobjectutils.ChildHealthy))),
+								),
+							),
+						),
+					)
+				})
+
+				It("should set the inSync condition", func() {
+					m.Eventually(gto).Should(
+						testutils.WithGitTrackObjectStatusConditions(
+							ContainElement(
+								SatisfyAll(
+									testutils.WithGitTrackObjectConditionType(Equal(farosv1alpha1.ObjectInSyncType)),
+									testutils.WithGitTrackObjectConditionStatus(Equal(corev1.ConditionTrue)),
+									testutils.WithGitTrackObjectConditionReason(Equal(string(gittrackobjectutils.ChildAppliedSuccess))),
+								),
+							),
+						),
+					)
+				})
+
+				It("should record the data the health was assessed for", func() {
+					m.Eventually(gto).Should(testutils.WithGitTrackObjectObservedDataHash(Equal(gittrackobjectutils.DataHash(gto))))
+				})
+			})
+
+			Context("with a degraded child", func() {
+				BeforeEach(func() {
+					opts.health = healthResult{status: health.StatusDegraded, reason: gittrackobjectutils.ChildDegraded, message: "job failed"}
+					r.updateStatus(gto, opts)
+				})
+
+				It("should set the healthy condition", func() {
+					m.Eventually(gto).Should(
+						testutils.WithGitTrackObjectStatusConditions(
+							ContainElement(
+								SatisfyAll(
+									testutils.WithGitTrackObjectConditionType(Equal(farosv1alpha1.ObjectHealthyType)),
+									testutils.WithGitTrackObjectConditionStatus(Equal(corev1.ConditionFalse)),
+									testutils.WithGitTrackObjectConditionReason(Equal(string(gittrackobjectutils.ChildDegraded))),
+									testutils.WithGitTrackObjectConditionMessage(Equal("job failed")),
+								),
+							),
+						),
+					)
+				})
+			})
+
+			Context("with applied data", func() {
+				BeforeEach(func() {
+					opts.applied = true
+					r.updateStatus(gto, opts)
+				})
+
+				It("should record the data applied to the child", func() {
+					m.Eventually(gto).Should(testutils.WithGitTrackObjectAppliedDataHash(Equal(gittrackobjectutils.DataHash(gto))))
+				})
+			})
+
+			Context("without applied data", func() {
+				BeforeEach(func() {
+					r.updateStatus(gto, opts)
+				})
+
+				It("should not record the data", func() {
+					m.Consistently(gto, time.Second).Should(testutils.WithGitTrackObjectAppliedDataHash(BeEmpty()))
+				})
+			})
+
+			Context("with a handled reconcile request", func() {
+				BeforeEach(func() {
+					opts.handledReconcileAt = "2019-01-01T00:00:00Z"
+					r.updateStatus(gto, opts)
+				})
+
+				It("should record the handled request", func() {
+					m.Eventually(gto).Should(testutils.WithGitTrackObjectLastHandledReconcileAt(Equal("2019-01-01T00:00:00Z")))
+				})
+
+				It("should keep the handled request when no request is handled", func() {
+					m.Eventually(gto).Should(testutils.WithGitTrackObjectLastHandledReconcileAt(Equal("2019-01-01T00:00:00Z")))
+					r.updateStatus(gto, &statusOpts{})
+					m.Consistently(gto).Should(testutils.WithGitTrackObjectLastHandledReconcileAt(Equal("2019-01-01T00:00:00Z")))
+				})
+			})
 		})
 
 		Context("with a ClusterGitTrackObject", func() {
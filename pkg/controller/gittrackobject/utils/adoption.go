@@ -0,0 +1,49 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const adoptionAnnotation = "faros.pusher.com/adoption"
+
+// GetAdoptionPolicy returns the value of the `faros.pusher.com/adoption`
+// annotation, or the given default if one doesn't exist
+func GetAdoptionPolicy(obj *unstructured.Unstructured, defaultPolicy farosv1alpha1.GitTrackAdoptionPolicy) (farosv1alpha1.GitTrackAdoptionPolicy, error) {
+	annotations := obj.GetAnnotations()
+	if data, ok := annotations[adoptionAnnotation]; ok {
+		return validAdoptionPolicy(farosv1alpha1.GitTrackAdoptionPolicy(data))
+	}
+	if defaultPolicy == "" {
+		return farosv1alpha1.AdoptionAlways, nil
+	}
+	return validAdoptionPolicy(defaultPolicy)
+}
+
+// validAdoptionPolicy returns whether a given adoption policy is valid or not
+func validAdoptionPolicy(p farosv1alpha1.GitTrackAdoptionPolicy) (farosv1alpha1.GitTrackAdoptionPolicy, error) {
+	switch p {
+	case farosv1alpha1.AdoptionAlways, farosv1alpha1.AdoptionIfUnowned, farosv1alpha1.AdoptionNever:
+		return p, nil
+	default:
+		return p, fmt.Errorf("invalid adoption policy: %s", p)
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const applyModeAnnotation = "faros.pusher.com/apply-mode"
+
+const (
+	// ClientSideApplyMode represents the apply mode where the child is updated
+	// with a three way merge against the last applied annotation
+	ClientSideApplyMode ApplyMode = "client"
+	// ServerSideApplyMode represents the apply mode where the child is updated
+	// using server-side apply
+	ServerSideApplyMode ApplyMode = "server"
+)
+
+// ApplyMode represents a valid apply mode
+type ApplyMode string
+
+// GetApplyMode returns the value of the `faros.pusher.com/apply-mode`
+// annotation, or the given default if one doesn't exist
+func GetApplyMode(obj *unstructured.Unstructured, defaultMode ApplyMode) (ApplyMode, error) {
+	annotations := obj.GetAnnotations()
+	if data, ok := annotations[applyModeAnnotation]; ok {
+		return validApplyMode(ApplyMode(data))
+	}
+	if defaultMode == "" {
+		return ClientSideApplyMode, nil
+	}
+	return validApplyMode(defaultMode)
+}
+
+// validApplyMode returns whether a given apply mode is valid or not
+func validApplyMode(m ApplyMode) (ApplyMode, error) {
+	switch m {
+	case ClientSideApplyMode, ServerSideApplyMode:
+		return m, nil
+	default:
+		return m, fmt.Errorf("invalid apply mode: %s", m)
+	}
+}
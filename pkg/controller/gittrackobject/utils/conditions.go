@@ -50,6 +50,65 @@ const (
 	// ErrorWatchingChild represents the condition reason when the controller
 	// cannot create an informer for the child's kind
 	ErrorWatchingChild ConditionReason = "ErrorWatchingChild"
+
+	// AdoptionRefused represents the condition reason when the child already
+	// exists and the adoption policy doesn't allow taking it over
+	AdoptionRefused ConditionReason = "AdoptionRefused"
+
+	// ApplyConflict represents the condition reason when the child can't be
+	// applied server-side as fields are owned by other field managers
+	ApplyConflict ConditionReason = "ApplyConflict"
+
+	// ServerSideApplyUnsupported represents the condition reason when the
+	// child should be applied server-side but the API server doesn't support
+	// server-side apply
+	ServerSideApplyUnsupported ConditionReason = "ServerSideApplyUnsupported"
+
+	// PermissionDenied represents the condition reason when the API server
+	// forbids the controller from managing the child, for example because the
+	// ServiceAccount of the owning GitTrack lacks the RBAC permissions
+	PermissionDenied ConditionReason = "PermissionDenied"
+
+	// ErrorGettingOwner represents the condition reason when the controller
+	// hits an error trying to get the GitTrack owning the object
+	ErrorGettingOwner ConditionReason = "ErrorGettingOwner"
+
+	// ErrorConnectingToCluster represents the condition reason when the
+	// controller hits an error trying to connect to the remote cluster of the
+	// child
+	ErrorConnectingToCluster ConditionReason = "ErrorConnectingToCluster"
+
+	// ChildDrifted represents the condition reason when the child has been
+	// modified outside of Git
+	ChildDrifted ConditionReason = "ChildDrifted"
+
+	// ChildNotDrifted represents the condition reason when the child matches
+	// its desired state
+	ChildNotDrifted ConditionReason = "ChildNotDrifted"
+
+	// ErrorCheckingDrift represents the condition reason when the controller
+	// hits an error trying to compare the child to its desired state
+	ErrorCheckingDrift ConditionReason = "ErrorCheckingDrift"
+
+	// ChildFlapping represents the condition reason when the child is
+	// repeatedly being changed by another controller and reverted
+	ChildFlapping ConditionReason = "ChildFlapping"
+
+	// ChildHealthy represents the condition reason when the child has reached
+	// its desired state
+	ChildHealthy ConditionReason = "ChildHealthy"
+
+	// ChildProgressing represents the condition reason when the child is still
+	// working towards its desired state
+	ChildProgressing ConditionReason = "ChildProgressing"
+
+	// ChildDegraded represents the condition reason when the child has failed
+	// to reach its desired state
+	ChildDegraded ConditionReason = "ChildDegraded"
+
+	// ErrorCheckingHealth represents the condition reason when the controller
+	// hits an error trying to assess the health of the child
+	ErrorCheckingHealth ConditionReason = "ErrorCheckingHealth"
 )
 
 // ConditionReason represents a valid condition reason
@@ -79,10 +138,10 @@ func GetGitTrackObjectCondition(status farosv1alpha1.GitTrackObjectStatus, condT
 }
 
 // SetGitTrackObjectCondition updates the GitTrackObject to include the provided condition. If the condition that
-// we are about to add already exists and has the same status and reason then we are not going to update.
+// we are about to add already exists and has the same status, reason and message then we are not going to update.
 func SetGitTrackObjectCondition(status *farosv1alpha1.GitTrackObjectStatus, condition farosv1alpha1.GitTrackObjectCondition) {
 	currentCond := GetGitTrackObjectCondition(*status, condition.Type)
-	if currentCond != nil && currentCond.Status == condition.Status && currentCond.Reason == condition.Reason {
+	if currentCond != nil && currentCond.Status == condition.Status && currentCond.Reason == condition.Reason && currentCond.Message == condition.Message {
 		return
 	}
 	// Do not update lastTransitionTime if the status of the condition doesn't change.
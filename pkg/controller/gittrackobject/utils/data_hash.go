@@ -0,0 +1,42 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+)
+
+// DataHash returns a hash of the data of the (Cluster)GitTrackObject, which
+// identifies the desired state of its child
+func DataHash(gto farosv1alpha1.GitTrackObjectInterface) string {
+	return fmt.Sprintf("%x", sha256.Sum256(gto.GetSpec().Data))
+}
+
+// StatusObserved returns whether the conditions of the
+// (Cluster)GitTrackObject were last updated for the given data hash
+func StatusObserved(gto farosv1alpha1.GitTrackObjectInterface, hash string) bool {
+	return gto.GetStatus().ObservedDataHash == hash
+}
+
+// DataApplied returns whether the data of the (Cluster)GitTrackObject was
+// last applied to its child
+func DataApplied(gto farosv1alpha1.GitTrackObjectInterface) bool {
+	return gto.GetStatus().AppliedDataHash == DataHash(gto)
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const driftPolicyAnnotation = "faros.pusher.com/drift-policy"
+
+// GetDriftPolicy returns the value of the `faros.pusher.com/drift-policy`
+// annotation, or the given default if one doesn't exist
+func GetDriftPolicy(obj *unstructured.Unstructured, defaultPolicy farosv1alpha1.GitTrackDriftPolicy) (farosv1alpha1.GitTrackDriftPolicy, error) {
+	annotations := obj.GetAnnotations()
+	if data, ok := annotations[driftPolicyAnnotation]; ok {
+		return validDriftPolicy(farosv1alpha1.GitTrackDriftPolicy(data))
+	}
+	if defaultPolicy == "" {
+		return farosv1alpha1.DriftPolicyRevert, nil
+	}
+	return validDriftPolicy(defaultPolicy)
+}
+
+// validDriftPolicy returns whether a given drift policy is valid or not
+func validDriftPolicy(p farosv1alpha1.GitTrackDriftPolicy) (farosv1alpha1.GitTrackDriftPolicy, error) {
+	switch p {
+	case farosv1alpha1.DriftPolicyRevert, farosv1alpha1.DriftPolicyReport:
+		return p, nil
+	default:
+		return p, fmt.Errorf("invalid drift policy: %s", p)
+	}
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const ignoreFieldsAnnotation = "faros.pusher.com/ignore-fields"
+
+// GetIgnoredFields returns the comma separated field paths of the
+// `faros.pusher.com/ignore-fields` annotation
+func GetIgnoredFields(obj *unstructured.Unstructured) []string {
+	fields := []string{}
+	for _, field := range strings.Split(obj.GetAnnotations()[ignoreFieldsAnnotation], ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
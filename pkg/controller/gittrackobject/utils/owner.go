@@ -0,0 +1,81 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	"github.com/pusher/faros/pkg/utils"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetOwner returns the GitTrack controlling the GitTrackObjectInterface, or
+// nil if it is not controlled by a GitTrack
+//
+// The GitTrack of a GitTrackObject is in its namespace. ClusterGitTrackObjects
+// don't record the namespace of their owner in the owner reference, so it is
+// read from the GitTrack annotation instead.
+func GetOwner(c client.Reader, gto farosv1alpha1.GitTrackObjectInterface) (*farosv1alpha1.GitTrack, error) {
+	ref := metav1.GetControllerOf(gto)
+	if ref == nil || ref.Kind != "GitTrack" {
+		return nil, nil
+	}
+
+	namespace := gto.GetNamespace()
+	if namespace == "" {
+		var name string
+		var err error
+		namespace, name, err = cache.SplitMetaNamespaceKey(gto.GetAnnotations()[utils.GitTrackAnnotation])
+		if err != nil || namespace == "" || name != ref.Name {
+			return listOwner(c, ref)
+		}
+	}
+
+	gt := &farosv1alpha1.GitTrack{}
+	err := c.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: ref.Name}, gt)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to get GitTrack: %v", err)
+	}
+	if gt.UID != ref.UID {
+		return nil, nil
+	}
+	return gt, nil
+}
+
+// listOwner lists GitTracks to find the one referenced by UID, for
+// ClusterGitTrackObjects created before the GitTrack annotation was recorded
+func listOwner(c client.Reader, ref *metav1.OwnerReference) (*farosv1alpha1.GitTrack, error) {
+	gtList := &farosv1alpha1.GitTrackList{}
+	err := c.List(context.TODO(), gtList)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list GitTracks: %v", err)
+	}
+	for _, gt := range gtList.Items {
+		if gt.UID == ref.UID {
+			return gt.DeepCopy(), nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// RemoteOwnerAnnotation records the (Cluster)GitTrackObject managing a child
+// in a remote cluster, as the child can't have an owner reference to an object
+// in another cluster
+const RemoteOwnerAnnotation = "faros.pusher.com/owner"
+
+// SetRemoteOwner sets the `faros.pusher.com/owner` annotation of the child to
+// the namespaced name of the (Cluster)GitTrackObject
+func SetRemoteOwner(owner farosv1alpha1.GitTrackObjectInterface, child metav1.Object) {
+	annotations := child.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[RemoteOwnerAnnotation] = owner.GetNamespacedName()
+	child.SetAnnotations(annotations)
+}
+
+// GetRemoteOwner returns the value of the `faros.pusher.com/owner` annotation
+// of the child, or an empty string if it isn't set
+func GetRemoteOwner(child metav1.Object) string {
+	return child.GetAnnotations()[RemoteOwnerAnnotation]
+}
+
+// IsRemotelyOwnedBy returns whether the `faros.pusher.com/owner` annotation of
+// the child names the (Cluster)GitTrackObject
+func IsRemotelyOwnedBy(child metav1.Object, owner farosv1alpha1.GitTrackObjectInterface) bool {
+	return GetRemoteOwner(child) == owner.GetNamespacedName()
+}
+
+// EnqueueRequestForRemoteOwner enqueues Requests for the (Cluster)GitTrackObject
+// named by the `faros.pusher.com/owner` annotation of the object that was the
+// source of the Event
+var EnqueueRequestForRemoteOwner = &handler.EnqueueRequestsFromMapFunc{
+	ToRequests: handler.ToRequestsFunc(remoteOwnerRequests),
+}
+
+// remoteOwnerRequests maps a child in a remote cluster to a Request for its
+// owner
+func remoteOwnerRequests(obj handler.MapObject) []reconcile.Request {
+	metadata, err := meta.Accessor(obj.Object)
+	if err != nil {
+		return nil
+	}
+	owner := GetRemoteOwner(metadata)
+	if owner == "" {
+		return nil
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(owner)
+	if err != nil {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+}
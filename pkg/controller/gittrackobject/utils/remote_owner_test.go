@@ -0,0 +1,83 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	testutils "github.com/pusher/faros/test/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("RemoteOwner Suite", func() {
+	var child *appsv1.Deployment
+
+	BeforeEach(func() {
+		child = testutils.ExampleDeployment.DeepCopy()
+	})
+
+	Context("SetRemoteOwner", func() {
+		It("records the namespaced name of a GitTrackObject", func() {
+			SetRemoteOwner(testutils.ExampleGitTrackObject.DeepCopy(), child)
+			Expect(child.GetAnnotations()).To(HaveKeyWithValue(RemoteOwnerAnnotation, "default/example"))
+			Expect(IsRemotelyOwnedBy(child, testutils.ExampleGitTrackObject)).To(BeTrue())
+		})
+
+		It("records the name of a ClusterGitTrackObject", func() {
+			SetRemoteOwner(testutils.ExampleClusterGitTrackObject.DeepCopy(), child)
+			Expect(child.GetAnnotations()).To(HaveKeyWithValue(RemoteOwnerAnnotation, testutils.ExampleClusterGitTrackObject.Name))
+			Expect(IsRemotelyOwnedBy(child, testutils.ExampleGitTrackObject)).To(BeFalse())
+		})
+	})
+
+	Context("EnqueueRequestForRemoteOwner", func() {
+		var requests []reconcile.Request
+
+		JustBeforeEach(func() {
+			requests = remoteOwnerRequests(handler.MapObject{Meta: child, Object: child})
+		})
+
+		Context("with a child owned by a GitTrackObject", func() {
+			BeforeEach(func() {
+				SetRemoteOwner(testutils.ExampleGitTrackObject.DeepCopy(), child)
+			})
+
+			It("enqueues a request for the GitTrackObject", func() {
+				Expect(requests).To(ConsistOf(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "example"}}))
+			})
+		})
+
+		Context("with a child owned by a ClusterGitTrackObject", func() {
+			BeforeEach(func() {
+				SetRemoteOwner(testutils.ExampleClusterGitTrackObject.DeepCopy(), child)
+			})
+
+			It("enqueues a request for the ClusterGitTrackObject", func() {
+				Expect(requests).To(ConsistOf(reconcile.Request{NamespacedName: types.NamespacedName{Name: testutils.ExampleClusterGitTrackObject.Name}}))
+			})
+		})
+
+		Context("with a child without a remote owner", func() {
+			It("doesn't enqueue any requests", func() {
+				Expect(requests).To(BeEmpty())
+			})
+		})
+	})
+})
@@ -34,6 +34,16 @@ const (
 	// RecreateUpdateStrategy represents the update strategy where a resource should
 	// first be deleted and then created again, rather than updated in-place
 	RecreateUpdateStrategy UpdateStrategy = "recreate"
+	// ReplaceUpdateStrategy represents the update strategy where a resource
+	// should be replaced with its desired state in full, rather than patched
+	ReplaceUpdateStrategy UpdateStrategy = "replace"
+	// CreateOnlyUpdateStrategy represents the update strategy where a resource
+	// should be created if it is missing but never updated or deleted
+	CreateOnlyUpdateStrategy UpdateStrategy = "create-only"
+	// UpdateOrRecreateUpdateStrategy represents the update strategy where a
+	// resource should be updated in-place, unless the update changes immutable
+	// fields in which case it should be deleted and created again
+	UpdateOrRecreateUpdateStrategy UpdateStrategy = "update-or-recreate"
 )
 
 // UpdateStrategy represents a valid update strategy
@@ -52,7 +62,7 @@ func GetUpdateStrategy(obj *unstructured.Unstructured) (UpdateStrategy, error) {
 // validUpdateStrategy returns whether a given update strategy is valid or not
 func validUpdateStrategy(s UpdateStrategy) (UpdateStrategy, error) {
 	switch s {
-	case DefaultUpdateStrategy, NeverUpdateStrategy, RecreateUpdateStrategy:
+	case DefaultUpdateStrategy, NeverUpdateStrategy, RecreateUpdateStrategy, ReplaceUpdateStrategy, CreateOnlyUpdateStrategy, UpdateOrRecreateUpdateStrategy:
 		return s, nil
 	default:
 		return s, fmt.Errorf("invalid update strategy: %s", s)
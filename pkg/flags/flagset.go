@@ -35,19 +35,64 @@ var (
 	// ignoredResources is a list of Kubernets kinds to ignore when reconciling
 	ignoredResources []string
 
+	// ignoredFields is a list of fields of children to ignore when updating
+	// children
+	ignoredFields []string
+
+	// protectedKinds is a list of kinds of children that are never deleted
+	// unless their manifests lift the protection
+	protectedKinds []string
+
 	// ServerDryRun whether to enable Server side dry run or not
 	ServerDryRun bool
 
+	// ApplyMode is the default mode used to apply children, either client or
+	// server
+	ApplyMode string
+
+	// ForceConflicts whether to take ownership of fields owned by other field
+	// managers when applying children server-side
+	ForceConflicts bool
+
 	// FetchTimeout in seconds for fetching changes from repositories
 	FetchTimeout time.Duration
+
+	// HealthChecksFile is the path to a file containing custom health checks
+	HealthChecksFile string
+
+	// PruneThresholdCount is the default maximum number of children a GitTrack
+	// may prune at once
+	PruneThresholdCount int64
+
+	// PruneThresholdPercent is the default maximum percentage of its children a
+	// GitTrack may prune at once
+	PruneThresholdPercent int64
+
+	// Users are usernames Faros authenticates to the API server as in addition
+	// to the ServiceAccount it runs as, used by the admission webhooks to
+	// recognise changes made by Faros
+	Users []string
+
+	// BreakGlassGroups are the groups whose members may change children
+	// despite the child lock admission webhook
+	BreakGlassGroups []string
 )
 
 func init() {
 	FlagSet = flag.NewFlagSet("faros", flag.PanicOnError)
 	FlagSet.StringVar(&Namespace, "namespace", "", "Only manage GitTrack resources in given namespace")
 	FlagSet.StringSliceVar(&ignoredResources, "ignore-resource", []string{}, "Ignore resources of these kinds found in repositories, specified in <resource>.<group>/<version> format eg jobs.batch/v1")
+	FlagSet.StringSliceVar(&ignoredFields, "ignore-field", []string{}, "Never update these fields of children, specified in <kind>.<group>=<path> format eg Deployment.apps=/spec/replicas")
+	FlagSet.StringSliceVar(&protectedKinds, "protected-kind", []string{"Namespace", "PersistentVolumeClaim", "CustomResourceDefinition.apiextensions.k8s.io"}, "Never delete children of these kinds unless annotated with faros.pusher.com/delete-protection: disabled, specified in <kind>.<group> format eg CustomResourceDefinition.apiextensions.k8s.io")
 	FlagSet.BoolVar(&ServerDryRun, "server-dry-run", true, "Enable/Disable server side dry run before updating resources")
+	FlagSet.StringVar(&ApplyMode, "apply-mode", "client", "Default mode used to apply children, either client (three way merge) or server (server-side apply)")
+	FlagSet.BoolVar(&ForceConflicts, "force-conflicts", false, "Take ownership of fields owned by other field managers when applying children server-side rather than reporting conflicts")
 	FlagSet.DurationVar(&FetchTimeout, "fetch-timeout", 30*time.Second, "Timeout in seconds for fetching changes from repositories")
+	FlagSet.StringVar(&HealthChecksFile, "health-checks", "", "Path to a YAML file defining health checks for custom resources")
+	FlagSet.Int64Var(&PruneThresholdCount, "prune-threshold-count", 0, "Maximum number of children a GitTrack may prune at once without acknowledgement, 0 disables the threshold")
+	FlagSet.Int64Var(&PruneThresholdPercent, "prune-threshold-percent", 0, "Maximum percentage of its children a GitTrack may prune at once without acknowledgement, 0 disables the threshold")
+	FlagSet.StringSliceVar(&Users, "faros-user", []string{}, "Usernames Faros authenticates to the API server as in addition to the ServiceAccount it runs as, required when the user can't be determined from its credentials. (Cluster)GitTrackObjects created or changed by other users are rejected by the admission webhook")
+	FlagSet.StringSliceVar(&BreakGlassGroups, "break-glass-group", []string{"system:masters"}, "Groups whose members may change children by hand when the child lock admission webhook is enabled")
 }
 
 // ParseIgnoredResources attempts to parse the ignore-resource flag value and
@@ -72,3 +117,35 @@ func ParseIgnoredResources() (map[schema.GroupVersionResource]interface{}, error
 	}
 	return gvrs, nil
 }
+
+// ParseProtectedKinds attempts to parse the protected-kind flag values and
+// create a set of GroupKinds from the slice
+func ParseProtectedKinds() (map[schema.GroupKind]bool, error) {
+	gks := make(map[schema.GroupKind]bool)
+	for _, protected := range protectedKinds {
+		if protected == "" {
+			continue
+		}
+		gk := schema.ParseGroupKind(protected)
+		if gk.Kind == "" {
+			return nil, fmt.Errorf("%s is invalid, should be of format <kind>.<group>", protected)
+		}
+		gks[gk] = true
+	}
+	return gks, nil
+}
+
+// ParseIgnoredFields attempts to parse the ignore-field flag values and
+// create a map of the paths of the fields to ignore by GroupKind
+func ParseIgnoredFields() (map[schema.GroupKind][]string, error) {
+	fields := make(map[schema.GroupKind][]string)
+	for _, ignored := range ignoredFields {
+		split := strings.SplitN(ignored, "=", 2)
+		if len(split) != 2 || split[0] == "" || split[1] == "" {
+			return nil, fmt.Errorf("%s is invalid, should be of format <kind>.<group>=<path>", ignored)
+		}
+		gk := schema.ParseGroupKind(split[0])
+		fields[gk] = append(fields[gk], split[1])
+	}
+	return fields, nil
+}
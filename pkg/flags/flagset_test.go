@@ -63,4 +63,57 @@ var _ = Describe("FlagSet Suite", func() {
 			Expect(ok).To(BeTrue())
 		})
 	})
+
+	Context("ParseProtectedKinds", func() {
+		var defaultProtectedKinds []string
+
+		BeforeEach(func() {
+			defaultProtectedKinds = protectedKinds
+		})
+
+		AfterEach(func() {
+			protectedKinds = defaultProtectedKinds
+		})
+
+		It("protects Namespaces, PersistentVolumeClaims and CustomResourceDefinitions by default", func() {
+			kinds, err := ParseProtectedKinds()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kinds).To(HaveKey(schema.GroupKind{Kind: "Namespace"}))
+			Expect(kinds).To(HaveKey(schema.GroupKind{Kind: "PersistentVolumeClaim"}))
+			Expect(kinds).To(HaveKey(schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}))
+		})
+
+		It("parses kinds with groups", func() {
+			protectedKinds = []string{"StatefulSet.apps"}
+			kinds, err := ParseProtectedKinds()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kinds).To(Equal(map[schema.GroupKind]bool{{Group: "apps", Kind: "StatefulSet"}: true}))
+		})
+
+		It("errors without a kind", func() {
+			protectedKinds = []string{".apps"}
+			_, err := ParseProtectedKinds()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("ParseIgnoredFields", func() {
+		AfterEach(func() {
+			ignoredFields = []string{}
+		})
+
+		It("parses fields by GroupKind", func() {
+			ignoredFields = []string{"Deployment.apps=/spec/replicas", "Service=/spec/clusterIP", "Deployment.apps=.spec.template.metadata.annotations"}
+			fields, err := ParseIgnoredFields()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fields).To(HaveKeyWithValue(schema.GroupKind{Group: "apps", Kind: "Deployment"}, []string{"/spec/replicas", ".spec.template.metadata.annotations"}))
+			Expect(fields).To(HaveKeyWithValue(schema.GroupKind{Kind: "Service"}, []string{"/spec/clusterIP"}))
+		})
+
+		It("errors without a path", func() {
+			ignoredFields = []string{"Deployment.apps"}
+			_, err := ParseIgnoredFields()
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
@@ -0,0 +1,197 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// deploymentHealth follows the same logic as `kubectl rollout status` to
+// determine whether a Deployment has finished rolling out
+func deploymentHealth(obj *unstructured.Unstructured) (Result, error) {
+	deployment := &appsv1.Deployment{}
+	if err := fromUnstructured(obj, deployment); err != nil {
+		return Result{}, err
+	}
+
+	if deployment.Spec.Paused {
+		return healthy("deployment is paused"), nil
+	}
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		return progressing("waiting for deployment spec update to be observed"), nil
+	}
+	for _, cond := range deployment.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Reason == "ProgressDeadlineExceeded" {
+			return degraded("deployment %q exceeded its progress deadline", deployment.Name), nil
+		}
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	if status.UpdatedReplicas < replicas {
+		return progressing("%d out of %d new replicas have been updated", status.UpdatedReplicas, replicas), nil
+	}
+	if status.Replicas > status.UpdatedReplicas {
+		return progressing("%d old replicas are pending termination", status.Replicas-status.UpdatedReplicas), nil
+	}
+	if status.AvailableReplicas < status.UpdatedReplicas {
+		return progressing("%d of %d updated replicas are available", status.AvailableReplicas, status.UpdatedReplicas), nil
+	}
+	return healthy(""), nil
+}
+
+// statefulSetHealth follows the same logic as `kubectl rollout status` to
+// determine whether a StatefulSet has finished rolling out
+func statefulSetHealth(obj *unstructured.Unstructured) (Result, error) {
+	sts := &appsv1.StatefulSet{}
+	if err := fromUnstructured(obj, sts); err != nil {
+		return Result{}, err
+	}
+
+	if sts.Status.ObservedGeneration == 0 || sts.Status.ObservedGeneration < sts.Generation {
+		return progressing("waiting for statefulset spec update to be observed"), nil
+	}
+	if sts.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		return healthy("rollout status is not available for the OnDelete update strategy"), nil
+	}
+
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+	status := sts.Status
+	if status.ReadyReplicas < replicas {
+		return progressing("%d of %d replicas are ready", status.ReadyReplicas, replicas), nil
+	}
+	if rollingUpdate := sts.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil {
+		expected := replicas - *rollingUpdate.Partition
+		if status.UpdatedReplicas < expected {
+			return progressing("%d of %d new replicas have been updated", status.UpdatedReplicas, expected), nil
+		}
+		return healthy(""), nil
+	}
+	if status.UpdateRevision != status.CurrentRevision {
+		return progressing("waiting for rolling update to complete, %d of %d replicas updated", status.UpdatedReplicas, replicas), nil
+	}
+	return healthy(""), nil
+}
+
+// daemonSetHealth follows the same logic as `kubectl rollout status` to
+// determine whether a DaemonSet has finished rolling out
+func daemonSetHealth(obj *unstructured.Unstructured) (Result, error) {
+	ds := &appsv1.DaemonSet{}
+	if err := fromUnstructured(obj, ds); err != nil {
+		return Result{}, err
+	}
+
+	if ds.Status.ObservedGeneration < ds.Generation {
+		return progressing("waiting for daemonset spec update to be observed"), nil
+	}
+	if ds.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType {
+		return healthy("rollout status is not available for the OnDelete update strategy"), nil
+	}
+
+	status := ds.Status
+	if status.UpdatedNumberScheduled < status.DesiredNumberScheduled {
+		return progressing("%d out of %d new pods have been updated", status.UpdatedNumberScheduled, status.DesiredNumberScheduled), nil
+	}
+	if status.NumberAvailable < status.DesiredNumberScheduled {
+		return progressing("%d of %d updated pods are available", status.NumberAvailable, status.DesiredNumberScheduled), nil
+	}
+	return healthy(""), nil
+}
+
+// jobHealth considers a Job healthy once it has completed and degraded if it
+// has failed
+func jobHealth(obj *unstructured.Unstructured) (Result, error) {
+	job := &batchv1.Job{}
+	if err := fromUnstructured(obj, job); err != nil {
+		return Result{}, err
+	}
+
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobComplete:
+			return healthy(""), nil
+		case batchv1.JobFailed:
+			return degraded("job failed: %s", cond.Message), nil
+		}
+	}
+	return progressing("job has not completed, %d pods active", job.Status.Active), nil
+}
+
+// pvcHealth considers a PersistentVolumeClaim healthy once it is bound
+func pvcHealth(obj *unstructured.Unstructured) (Result, error) {
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := fromUnstructured(obj, pvc); err != nil {
+		return Result{}, err
+	}
+
+	switch pvc.Status.Phase {
+	case corev1.ClaimBound:
+		return healthy(""), nil
+	case corev1.ClaimLost:
+		return degraded("persistent volume claim has lost its volume"), nil
+	default:
+		return progressing("waiting for persistent volume claim to be bound"), nil
+	}
+}
+
+// serviceHealth considers a Service of type LoadBalancer healthy once its
+// load balancer has been provisioned. All other Services are always healthy.
+func serviceHealth(obj *unstructured.Unstructured) (Result, error) {
+	svc := &corev1.Service{}
+	if err := fromUnstructured(obj, svc); err != nil {
+		return Result{}, err
+	}
+
+	if svc.Spec.Type == corev1.ServiceTypeLoadBalancer && len(svc.Status.LoadBalancer.Ingress) == 0 {
+		return progressing("waiting for load balancer to be provisioned"), nil
+	}
+	return healthy(""), nil
+}
+
+// crdHealth considers a CustomResourceDefinition healthy once it has been
+// established
+func crdHealth(obj *unstructured.Unstructured) (Result, error) {
+	crd := &apiextensionsv1beta1.CustomResourceDefinition{}
+	if err := fromUnstructured(obj, crd); err != nil {
+		return Result{}, err
+	}
+
+	for _, cond := range crd.Status.Conditions {
+		if cond.Type == apiextensionsv1beta1.NamesAccepted && cond.Status == apiextensionsv1beta1.ConditionFalse {
+			return degraded("names not accepted: %s", cond.Message), nil
+		}
+	}
+	for _, cond := range crd.Status.Conditions {
+		if cond.Type == apiextensionsv1beta1.Established && cond.Status == apiextensionsv1beta1.ConditionTrue {
+			return healthy(""), nil
+		}
+	}
+	return progressing("waiting for custom resource definition to be established"), nil
+}
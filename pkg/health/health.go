@@ -0,0 +1,99 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package health assesses the health of child objects managed by Faros from
+// their live status.
+package health
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// StatusHealthy represents an object that has reached its desired state
+	StatusHealthy Status = "Healthy"
+
+	// StatusProgressing represents an object that is still working towards its
+	// desired state
+	StatusProgressing Status = "Progressing"
+
+	// StatusDegraded represents an object that has failed to reach its desired
+	// state
+	StatusDegraded Status = "Degraded"
+)
+
+// Status represents the health of an object
+type Status string
+
+// Result is the outcome of a health assessment
+type Result struct {
+	// Status is the health of the object
+	Status Status
+
+	// Message is a human readable explanation of the Status
+	Message string
+}
+
+// checkFunc assesses the health of a single kind of object
+type checkFunc func(*unstructured.Unstructured) (Result, error)
+
+// builtinChecks maps GroupKinds to the check used to assess their health
+var builtinChecks = map[schema.GroupKind]checkFunc{
+	{Group: "apps", Kind: "Deployment"}:                               deploymentHealth,
+	{Group: "extensions", Kind: "Deployment"}:                         deploymentHealth,
+	{Group: "apps", Kind: "StatefulSet"}:                              statefulSetHealth,
+	{Group: "apps", Kind: "DaemonSet"}:                                daemonSetHealth,
+	{Group: "extensions", Kind: "DaemonSet"}:                          daemonSetHealth,
+	{Group: "batch", Kind: "Job"}:                                     jobHealth,
+	{Group: "", Kind: "PersistentVolumeClaim"}:                        pvcHealth,
+	{Group: "", Kind: "Service"}:                                      serviceHealth,
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}: crdHealth,
+}
+
+// Assess determines the health of the given live object using only the
+// built-in health checks
+func Assess(obj *unstructured.Unstructured) (Result, error) {
+	return (&Checker{}).Assess(obj)
+}
+
+// fromUnstructured converts the unstructured object into the typed object
+// given
+func fromUnstructured(obj *unstructured.Unstructured, into interface{}) error {
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), into)
+	if err != nil {
+		return fmt.Errorf("unable to convert %s %s: %v", obj.GetKind(), obj.GetName(), err)
+	}
+	return nil
+}
+
+// healthy is a convenience function for creating a healthy result
+func healthy(message string) Result {
+	return Result{Status: StatusHealthy, Message: message}
+}
+
+// progressing is a convenience function for creating a progressing result
+func progressing(messageFmt string, args ...interface{}) Result {
+	return Result{Status: StatusProgressing, Message: fmt.Sprintf(messageFmt, args...)}
+}
+
+// degraded is a convenience function for creating a degraded result
+func degraded(messageFmt string, args ...interface{}) Result {
+	return Result{Status: StatusDegraded, Message: fmt.Sprintf(messageFmt, args...)}
+}
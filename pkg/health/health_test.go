@@ -0,0 +1,424 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pusher/faros/pkg/utils"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Health Suite", func() {
+	var obj *unstructured.Unstructured
+	var result Result
+	var err error
+
+	var fromYAML = func(in string) *unstructured.Unstructured {
+		u, err := utils.YAMLToUnstructured([]byte(in))
+		Expect(err).NotTo(HaveOccurred())
+		return &u
+	}
+
+	var assessAs = func(status Status) {
+		It("should not error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should assess the object as "+string(status), func() {
+			Expect(result.Status).To(Equal(status))
+		})
+	}
+
+	JustBeforeEach(func() {
+		result, err = Assess(obj)
+	})
+
+	Context("with a kind without a health check", func() {
+		BeforeEach(func() {
+			obj = fromYAML(configMap)
+		})
+
+		assessAs(StatusHealthy)
+	})
+
+	Context("with a Deployment", func() {
+		Context("that has rolled out", func() {
+			BeforeEach(func() {
+				obj = fromYAML(deployment + `
+status:
+  observedGeneration: 2
+  replicas: 3
+  updatedReplicas: 3
+  availableReplicas: 3
+`)
+			})
+
+			assessAs(StatusHealthy)
+		})
+
+		Context("whose spec has not been observed", func() {
+			BeforeEach(func() {
+				obj = fromYAML(deployment + `
+status:
+  observedGeneration: 1
+  replicas: 3
+  updatedReplicas: 3
+  availableReplicas: 3
+`)
+			})
+
+			assessAs(StatusProgressing)
+		})
+
+		Context("that is still rolling out", func() {
+			BeforeEach(func() {
+				obj = fromYAML(deployment + `
+status:
+  observedGeneration: 2
+  replicas: 4
+  updatedReplicas: 2
+  availableReplicas: 3
+`)
+			})
+
+			assessAs(StatusProgressing)
+		})
+
+		Context("that exceeded its progress deadline", func() {
+			BeforeEach(func() {
+				obj = fromYAML(deployment + `
+status:
+  observedGeneration: 2
+  replicas: 4
+  updatedReplicas: 2
+  conditions:
+  - type: Progressing
+    status: "False"
+    reason: ProgressDeadlineExceeded
+`)
+			})
+
+			assessAs(StatusDegraded)
+		})
+	})
+
+	Context("with a StatefulSet", func() {
+		Context("that has rolled out", func() {
+			BeforeEach(func() {
+				obj = fromYAML(statefulSet + `
+status:
+  observedGeneration: 1
+  replicas: 2
+  readyReplicas: 2
+  currentRevision: web-1
+  updateRevision: web-1
+`)
+			})
+
+			assessAs(StatusHealthy)
+		})
+
+		Context("that is still rolling out", func() {
+			BeforeEach(func() {
+				obj = fromYAML(statefulSet + `
+status:
+  observedGeneration: 1
+  replicas: 2
+  readyReplicas: 2
+  currentRevision: web-1
+  updateRevision: web-2
+`)
+			})
+
+			assessAs(StatusProgressing)
+		})
+	})
+
+	Context("with a DaemonSet", func() {
+		Context("that has rolled out", func() {
+			BeforeEach(func() {
+				obj = fromYAML(daemonSet + `
+status:
+  observedGeneration: 1
+  desiredNumberScheduled: 3
+  updatedNumberScheduled: 3
+  numberAvailable: 3
+`)
+			})
+
+			assessAs(StatusHealthy)
+		})
+
+		Context("with unavailable pods", func() {
+			BeforeEach(func() {
+				obj = fromYAML(daemonSet + `
+status:
+  observedGeneration: 1
+  desiredNumberScheduled: 3
+  updatedNumberScheduled: 3
+  numberAvailable: 1
+`)
+			})
+
+			assessAs(StatusProgressing)
+		})
+	})
+
+	Context("with a Job", func() {
+		Context("that has completed", func() {
+			BeforeEach(func() {
+				obj = fromYAML(job + `
+status:
+  conditions:
+  - type: Complete
+    status: "True"
+`)
+			})
+
+			assessAs(StatusHealthy)
+		})
+
+		Context("that has failed", func() {
+			BeforeEach(func() {
+				obj = fromYAML(job + `
+status:
+  conditions:
+  - type: Failed
+    status: "True"
+    message: BackoffLimitExceeded
+`)
+			})
+
+			assessAs(StatusDegraded)
+		})
+
+		Context("that is running", func() {
+			BeforeEach(func() {
+				obj = fromYAML(job + `
+status:
+  active: 1
+`)
+			})
+
+			assessAs(StatusProgressing)
+		})
+	})
+
+	Context("with a PersistentVolumeClaim", func() {
+		Context("that is bound", func() {
+			BeforeEach(func() {
+				obj = fromYAML(pvc + `
+status:
+  phase: Bound
+`)
+			})
+
+			assessAs(StatusHealthy)
+		})
+
+		Context("that is pending", func() {
+			BeforeEach(func() {
+				obj = fromYAML(pvc + `
+status:
+  phase: Pending
+`)
+			})
+
+			assessAs(StatusProgressing)
+		})
+
+		Context("that has lost its volume", func() {
+			BeforeEach(func() {
+				obj = fromYAML(pvc + `
+status:
+  phase: Lost
+`)
+			})
+
+			assessAs(StatusDegraded)
+		})
+	})
+
+	Context("with a LoadBalancer Service", func() {
+		Context("that has been provisioned", func() {
+			BeforeEach(func() {
+				obj = fromYAML(loadBalancer + `
+status:
+  loadBalancer:
+    ingress:
+    - ip: 10.0.0.1
+`)
+			})
+
+			assessAs(StatusHealthy)
+		})
+
+		Context("that has not been provisioned", func() {
+			BeforeEach(func() {
+				obj = fromYAML(loadBalancer)
+			})
+
+			assessAs(StatusProgressing)
+		})
+	})
+
+	Context("with a CustomResourceDefinition", func() {
+		Context("that is established", func() {
+			BeforeEach(func() {
+				obj = fromYAML(crd + `
+status:
+  conditions:
+  - type: NamesAccepted
+    status: "True"
+  - type: Established
+    status: "True"
+`)
+			})
+
+			assessAs(StatusHealthy)
+		})
+
+		Context("whose names were not accepted", func() {
+			BeforeEach(func() {
+				obj = fromYAML(crd + `
+status:
+  conditions:
+  - type: NamesAccepted
+    status: "False"
+    message: conflicting names
+`)
+			})
+
+			assessAs(StatusDegraded)
+		})
+	})
+})
+
+var configMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: example
+  namespace: default
+`
+
+var deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: example
+  namespace: default
+  generation: 2
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      app: nginx
+  template:
+    metadata:
+      labels:
+        app: nginx
+    spec:
+      containers:
+      - name: nginx
+        image: nginx`
+
+var statefulSet = `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: web
+  namespace: default
+  generation: 1
+spec:
+  replicas: 2
+  serviceName: web
+  selector:
+    matchLabels:
+      app: nginx
+  template:
+    metadata:
+      labels:
+        app: nginx
+    spec:
+      containers:
+      - name: nginx
+        image: nginx`
+
+var daemonSet = `apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: example
+  namespace: default
+  generation: 1
+spec:
+  selector:
+    matchLabels:
+      app: nginx
+  template:
+    metadata:
+      labels:
+        app: nginx
+    spec:
+      containers:
+      - name: nginx
+        image: nginx`
+
+var job = `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: example
+  namespace: default
+spec:
+  template:
+    spec:
+      restartPolicy: Never
+      containers:
+      - name: pi
+        image: perl`
+
+var pvc = `apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: example
+  namespace: default
+spec:
+  accessModes:
+  - ReadWriteOnce
+  resources:
+    requests:
+      storage: 1Gi`
+
+var loadBalancer = `apiVersion: v1
+kind: Service
+metadata:
+  name: example
+  namespace: default
+spec:
+  type: LoadBalancer
+  ports:
+  - port: 80`
+
+var crd = `apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: foos.example.com
+spec:
+  group: example.com
+  version: v1
+  names:
+    kind: Foo
+    plural: foos
+  scope: Namespaced`
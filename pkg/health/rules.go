@@ -0,0 +1,204 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// Rule matches an object when the JSONPath expression evaluates to the Value.
+// If no Value is given, the Rule matches whenever the expression evaluates to
+// a non-empty result.
+type Rule struct {
+	// JSONPath is a kubectl style JSONPath template,
+	// eg. {.status.conditions[?(@.type=="Ready")].status}
+	JSONPath string `json:"jsonPath"`
+
+	// Value is the value the result of the JSONPath must equal to match
+	Value string `json:"value,omitempty"`
+
+	// Message is used as the message of the health Result when the Rule
+	// matches
+	Message string `json:"message,omitempty"`
+}
+
+// CustomCheck defines the health rules for a single GroupKind.
+//
+// Degraded rules are evaluated first, followed by Progressing and then Healthy
+// rules. The first rule to match determines the health of the object. If no
+// rule matches, the object is considered to be progressing.
+type CustomCheck struct {
+	Group       string `json:"group"`
+	Kind        string `json:"kind"`
+	Healthy     []Rule `json:"healthy,omitempty"`
+	Progressing []Rule `json:"progressing,omitempty"`
+	Degraded    []Rule `json:"degraded,omitempty"`
+}
+
+// compiledRule is a Rule with its JSONPath parsed
+type compiledRule struct {
+	Rule
+	path *jsonpath.JSONPath
+}
+
+// Checker assesses the health of objects using the built-in health checks
+// and any custom checks it was configured with
+type Checker struct {
+	custom map[schema.GroupKind]checkFunc
+}
+
+// NewChecker constructs a Checker from the custom checks given.
+// Custom checks take precedence over built-in checks for the same GroupKind.
+func NewChecker(checks []CustomCheck) (*Checker, error) {
+	c := &Checker{custom: make(map[schema.GroupKind]checkFunc)}
+	for _, check := range checks {
+		gk := schema.GroupKind{Group: check.Group, Kind: check.Kind}
+		if gk.Kind == "" {
+			return nil, fmt.Errorf("health check for group %q has no kind", gk.Group)
+		}
+		if _, ok := c.custom[gk]; ok {
+			return nil, fmt.Errorf("duplicate health check for %s", gk.String())
+		}
+		fn, err := compileCheck(check)
+		if err != nil {
+			return nil, fmt.Errorf("invalid health check for %s: %v", gk.String(), err)
+		}
+		c.custom[gk] = fn
+	}
+	return c, nil
+}
+
+// LoadChecks reads a list of custom checks in YAML or JSON format from the
+// file at path
+func LoadChecks(path string) ([]CustomCheck, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open health checks file: %v", err)
+	}
+	defer f.Close()
+
+	checks := []CustomCheck{}
+	err = yaml.NewYAMLOrJSONDecoder(f, 4096).Decode(&checks)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("unable to decode health checks file: %v", err)
+	}
+	return checks, nil
+}
+
+// Assess determines the health of the given live object.
+//
+// Objects of a kind that has no health check are considered healthy as soon
+// as they exist.
+func (c *Checker) Assess(obj *unstructured.Unstructured) (Result, error) {
+	if obj == nil {
+		return Result{}, fmt.Errorf("cannot assess health of nil object")
+	}
+	gk := obj.GroupVersionKind().GroupKind()
+	if c != nil {
+		if check, ok := c.custom[gk]; ok {
+			return check(obj)
+		}
+	}
+	if check, ok := builtinChecks[gk]; ok {
+		return check(obj)
+	}
+	return healthy(""), nil
+}
+
+// compileCheck parses all of the rules within the CustomCheck and returns a
+// checkFunc evaluating them
+func compileCheck(check CustomCheck) (checkFunc, error) {
+	healthyRules, err := compileRules("healthy", check.Healthy)
+	if err != nil {
+		return nil, err
+	}
+	progressingRules, err := compileRules("progressing", check.Progressing)
+	if err != nil {
+		return nil, err
+	}
+	degradedRules, err := compileRules("degraded", check.Degraded)
+	if err != nil {
+		return nil, err
+	}
+	if len(healthyRules) == 0 {
+		return nil, fmt.Errorf("at least one healthy rule is required")
+	}
+
+	return func(obj *unstructured.Unstructured) (Result, error) {
+		ordered := []struct {
+			status Status
+			rules  []compiledRule
+		}{
+			{StatusDegraded, degradedRules},
+			{StatusProgressing, progressingRules},
+			{StatusHealthy, healthyRules},
+		}
+		for _, o := range ordered {
+			for _, rule := range o.rules {
+				matched, err := rule.matches(obj)
+				if err != nil {
+					return Result{}, err
+				}
+				if matched {
+					return Result{Status: o.status, Message: rule.Message}, nil
+				}
+			}
+		}
+		return progressing("waiting for %s to become healthy", obj.GetKind()), nil
+	}, nil
+}
+
+// compileRules parses the JSONPath of each of the rules given
+func compileRules(name string, rules []Rule) ([]compiledRule, error) {
+	compiled := []compiledRule{}
+	for i, rule := range rules {
+		path := jsonpath.New(fmt.Sprintf("%s[%d]", name, i))
+		path.AllowMissingKeys(true)
+		if err := path.Parse(rule.JSONPath); err != nil {
+			return nil, fmt.Errorf("unable to parse %s rule %q: %v", name, rule.JSONPath, err)
+		}
+		compiled = append(compiled, compiledRule{Rule: rule, path: path})
+	}
+	return compiled, nil
+}
+
+// matches evaluates the rule against the object
+func (r compiledRule) matches(obj *unstructured.Unstructured) (bool, error) {
+	results, err := r.path.FindResults(obj.UnstructuredContent())
+	if err != nil {
+		return false, fmt.Errorf("unable to evaluate %q: %v", r.JSONPath, err)
+	}
+	for _, result := range results {
+		for _, value := range result {
+			if !value.IsValid() || !value.CanInterface() {
+				continue
+			}
+			str := fmt.Sprintf("%v", value.Interface())
+			if r.Value == "" && str != "" || r.Value != "" && str == r.Value {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pusher/faros/pkg/utils"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Custom health checks", func() {
+	var checker *Checker
+	var obj *unstructured.Unstructured
+	var result Result
+	var err error
+
+	var fromYAML = func(in string) *unstructured.Unstructured {
+		u, err := utils.YAMLToUnstructured([]byte(in))
+		Expect(err).NotTo(HaveOccurred())
+		return &u
+	}
+
+	var certificateCheck = CustomCheck{
+		Group: "certmanager.k8s.io",
+		Kind:  "Certificate",
+		Healthy: []Rule{
+			{JSONPath: `{.status.conditions[?(@.type=="Ready")].status}`, Value: "True"},
+		},
+		Degraded: []Rule{
+			{JSONPath: `{.status.conditions[?(@.type=="Ready")].reason}`, Value: "Failed", Message: "certificate issuance failed"},
+		},
+	}
+
+	Context("NewChecker", func() {
+		It("accepts valid checks", func() {
+			_, err := NewChecker([]CustomCheck{certificateCheck})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects checks without a kind", func() {
+			_, err := NewChecker([]CustomCheck{{Group: "example.com", Healthy: certificateCheck.Healthy}})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("rejects checks without healthy rules", func() {
+			_, err := NewChecker([]CustomCheck{{Group: "example.com", Kind: "Foo"}})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("rejects invalid JSONPath expressions", func() {
+			_, err := NewChecker([]CustomCheck{{
+				Group:   "example.com",
+				Kind:    "Foo",
+				Healthy: []Rule{{JSONPath: "{.status"}},
+			}})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("rejects duplicate checks", func() {
+			_, err := NewChecker([]CustomCheck{certificateCheck, certificateCheck})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Assess", func() {
+		BeforeEach(func() {
+			checker, err = NewChecker([]CustomCheck{certificateCheck})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			result, err = checker.Assess(obj)
+		})
+
+		Context("with a ready Certificate", func() {
+			BeforeEach(func() {
+				obj = fromYAML(certificate + `
+status:
+  conditions:
+  - type: Ready
+    status: "True"
+    reason: Ready
+`)
+			})
+
+			It("should assess the object as Healthy", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Status).To(Equal(StatusHealthy))
+			})
+		})
+
+		Context("with a failed Certificate", func() {
+			BeforeEach(func() {
+				obj = fromYAML(certificate + `
+status:
+  conditions:
+  - type: Ready
+    status: "False"
+    reason: Failed
+`)
+			})
+
+			It("should assess the object as Degraded", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Status).To(Equal(StatusDegraded))
+				Expect(result.Message).To(Equal("certificate issuance failed"))
+			})
+		})
+
+		Context("with a Certificate without status", func() {
+			BeforeEach(func() {
+				obj = fromYAML(certificate)
+			})
+
+			It("should assess the object as Progressing", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Status).To(Equal(StatusProgressing))
+			})
+		})
+
+		Context("with a built-in kind", func() {
+			BeforeEach(func() {
+				obj = fromYAML(loadBalancer)
+			})
+
+			It("should use the built-in check", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Status).To(Equal(StatusProgressing))
+			})
+		})
+	})
+
+	Context("LoadChecks", func() {
+		var path string
+
+		BeforeEach(func() {
+			f, err := ioutil.TempFile("", "health-checks")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = f.WriteString(`- group: certmanager.k8s.io
+  kind: Certificate
+  healthy:
+  - jsonPath: '{.status.conditions[?(@.type=="Ready")].status}'
+    value: "True"
+`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.Close()).To(Succeed())
+			path = f.Name()
+		})
+
+		AfterEach(func() {
+			Expect(os.Remove(path)).To(Succeed())
+		})
+
+		It("parses the checks from the file", func() {
+			checks, err := LoadChecks(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(checks).To(HaveLen(1))
+			Expect(checks[0].Kind).To(Equal("Certificate"))
+			Expect(checks[0].Healthy).To(ConsistOf(Rule{JSONPath: `{.status.conditions[?(@.type=="Ready")].status}`, Value: "True"}))
+		})
+	})
+})
+
+var certificate = `apiVersion: certmanager.k8s.io/v1alpha1
+kind: Certificate
+metadata:
+  name: example
+  namespace: default
+spec:
+  secretName: example-tls`
@@ -24,6 +24,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -56,6 +57,8 @@ type Options struct {
 // Client defines the interface for the Applier
 type Client interface {
 	Apply(context.Context, *ApplyOptions, runtime.Object) error
+	Diff(context.Context, *ApplyOptions, runtime.Object) ([]byte, error)
+	Replace(context.Context, *ApplyOptions, runtime.Object) error
 }
 
 // Make sure Applier implements Client
@@ -128,9 +131,15 @@ type ApplyOptions struct {
 	Overwrite           *bool // Automatically resolve conflicts between the modified and live configuration by using values from the modified configuration
 	ForceDeletion       *bool
 	CascadeDeletion     *bool
+	DeletionPropagation *metav1.DeletionPropagation // If set, overrides the propagation policy chosen by CascadeDeletion
 	DeletionTimeout     *time.Duration
 	DeletionGracePeriod *int
 	ServerDryRun        *bool
+	Patch               *[]byte  // If set, receives the patch sent to update the resource
+	IgnoreFields        []string // Paths of fields that are never updated, see ParseFieldPath
+	ServerSideApply     *bool    // Apply the resource using server-side apply rather than a three way merge
+	ForceConflicts      *bool    // Take ownership of fields managed by others when applying server-side
+	RecreateOnImmutable *bool    // Delete and recreate the resource if the update changes immutable fields
 }
 
 // Complete defaults valus within the ApplyOptions struct
@@ -142,6 +151,9 @@ func (a *ApplyOptions) Complete() {
 	deletionTimeout := time.Duration(30 * time.Second)
 	deletionGracePeriod := -1
 	serverDryRun := false
+	serverSideApply := false
+	forceConflicts := false
+	recreateOnImmutable := false
 
 	if a.Overwrite == nil {
 		a.Overwrite = &overwrite
@@ -161,6 +173,15 @@ func (a *ApplyOptions) Complete() {
 	if a.ServerDryRun == nil {
 		a.ServerDryRun = &serverDryRun
 	}
+	if a.ServerSideApply == nil {
+		a.ServerSideApply = &serverSideApply
+	}
+	if a.ForceConflicts == nil {
+		a.ForceConflicts = &forceConflicts
+	}
+	if a.RecreateOnImmutable == nil {
+		a.RecreateOnImmutable = &recreateOnImmutable
+	}
 }
 
 // Apply performs a strategic three way merge update to the resource if it exists,
@@ -179,20 +200,117 @@ func (a *Applier) Apply(ctx context.Context, opts *ApplyOptions, modified runtim
 	// Check if the resource already exists
 	err = a.client.Get(context.TODO(), objectKey, current)
 	if err != nil && errors.IsNotFound(err) {
+		if *opts.ServerSideApply {
+			// Server-side apply creates the object if it doesn't exist
+			return a.applyServerSide(ctx, opts, nil, modified)
+		}
 		// Object is not found, create it
 		return a.create(ctx, opts, modified)
 	} else if err != nil {
-		return fmt.Errorf("unable to get current resource: %v", err)
+		return wrapError(err, "unable to get current resource")
+	}
+	if *opts.ServerSideApply {
+		return a.applyServerSide(ctx, opts, current, modified)
 	}
 	// Update the object
 	err = a.update(ctx, opts, current, modified)
 	if err != nil {
-		return fmt.Errorf("error applying update: %v", err)
+		return wrapError(err, "error applying update")
 	}
 
 	return nil
 }
 
+// Diff computes the three way merge patch that Apply would send to update the
+// existing resource to the modified configuration, without updating it.
+//
+// An empty patch ("{}") is returned if the resource doesn't differ from the
+// modified configuration. If the resource doesn't exist, a NotFound error is
+// returned.
+//
+// With server-side apply, the patch is the JSON merge patch between the
+// resource and the result of a forced dry run apply.
+func (a *Applier) Diff(ctx context.Context, opts *ApplyOptions, modified runtime.Object) ([]byte, error) {
+	// Default option values
+	opts.Complete()
+
+	current := newUnstructuredFor(modified)
+
+	objectKey, err := getNamespacedName(modified)
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine NamespacedName: %v", err)
+	}
+
+	err = a.client.Get(ctx, objectKey, current)
+	if err != nil && errors.IsNotFound(err) {
+		return nil, err
+	} else if err != nil {
+		return nil, wrapError(err, "unable to get current resource")
+	}
+	if *opts.ServerSideApply {
+		return a.diffServerSide(ctx, opts, current, modified)
+	}
+	return a.threeWayPatch(opts, current, modified)
+}
+
+// threeWayPatch computes the three way merge patch from the current object to
+// the modified configuration, leaving out ignored fields
+func (a *Applier) threeWayPatch(opts *ApplyOptions, current *unstructured.Unstructured, modified runtime.Object) ([]byte, error) {
+	modifiedJSON, err := modifiedConfiguration(opts, modified)
+	if err != nil {
+		return nil, err
+	}
+
+	patcher, err := a.newPatcher(opts, modified)
+	if err != nil {
+		return nil, fmt.Errorf("unable to construct patcher: %v", err)
+	}
+	_, patch, err := patcher.createPatch(current, modifiedJSON, current.GetSelfLink(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute patch: %v", err)
+	}
+	return patch, nil
+}
+
+// Replace replaces the resource with the modified configuration if it exists,
+// else it creates the resource.
+//
+// Unlike Apply, no patch is sent: the whole resource is sent to the API, so
+// fields set by others are removed. The resource is only replaced when the
+// modified configuration differs from the last applied configuration or the
+// resource has been changed since, in which case the change is reverted.
+// Fields only added to the resource by others, such as annotations, don't
+// cause it to be replaced. Ignored fields keep their current values, and
+// immutable fields populated by the server, such as the clusterIP of a
+// Service, are kept if the modified configuration leaves them out. The
+// resource version of the existing resource is sent with it so that the
+// update fails if the resource changes concurrently.
+func (a *Applier) Replace(ctx context.Context, opts *ApplyOptions, modified runtime.Object) error {
+	// Default option values
+	opts.Complete()
+
+	current := newUnstructuredFor(modified)
+
+	objectKey, err := getNamespacedName(modified)
+	if err != nil {
+		return fmt.Errorf("unable to determine NamespacedName: %v", err)
+	}
+
+	err = a.client.Get(ctx, objectKey, current)
+	if err != nil && errors.IsNotFound(err) {
+		// Object is not found, create it
+		return a.create(ctx, opts, modified)
+	} else if err != nil {
+		return wrapError(err, "unable to get current resource")
+	}
+
+	err = a.replace(ctx, opts, current, modified)
+	if err != nil {
+		return wrapError(err, "error replacing resource")
+	}
+	return nil
+}
+
 func (a *Applier) create(ctx context.Context, opts *ApplyOptions, obj runtime.Object) error {
 	metadata, err := meta.Accessor(obj)
 	if err != nil {
@@ -235,11 +353,158 @@ func (a *Applier) create(ctx context.Context, opts *ApplyOptions, obj runtime.Ob
 		Do().
 		Into(obj)
 	if err != nil {
-		return fmt.Errorf("error creating object: %v", err)
+		return wrapError(err, "error creating object")
 	}
 	return nil
 }
 
+func (a *Applier) replace(ctx context.Context, opts *ApplyOptions, current *unstructured.Unstructured, obj runtime.Object) error {
+	metadata, err := meta.Accessor(obj)
+	if err != nil {
+		return fmt.Errorf("unable to read metadata from object: %v", err)
+	}
+	log := a.log.WithValues(
+		"kind", obj.GetObjectKind().GroupVersionKind().String(),
+		"name", metadata.GetName(),
+		"namespace", metadata.GetNamespace(),
+	)
+	log.V(2).Info("replacing resource", "dry-run", *opts.ServerDryRun)
+
+	// Keep the annotation up to date so the resource can later be applied
+	err = createApplyAnnotation(obj, unstructured.UnstructuredJSONScheme)
+	if err != nil {
+		return fmt.Errorf("unable to apply LastAppliedAnnotation to object: %v", err)
+	}
+
+	// Only replace the resource when its desired state has changed since it
+	// was last applied, or it has drifted from it, so that the fields other
+	// controllers add to it, such as annotations, aren't removed on every
+	// reconcile
+	changed, err := lastAppliedChanged(current, obj)
+	if err != nil {
+		return fmt.Errorf("unable to compare object to last applied configuration: %v", err)
+	}
+	if !changed {
+		patch, err := a.threeWayPatch(opts, current, obj)
+		if err != nil {
+			return fmt.Errorf("unable to compare object to current resource: %v", err)
+		}
+		changed = string(patch) != "{}"
+	}
+	if !changed {
+		log.V(2).Info("resource unchanged since last applied, not replacing")
+		if opts.Patch != nil {
+			*opts.Patch = nil
+		}
+		err = a.copyInto(current, obj)
+		if err != nil {
+			return fmt.Errorf("error copying current resource: %v", err)
+		}
+		return nil
+	}
+
+	modified, err := toUnstructured(obj)
+	if err != nil {
+		return fmt.Errorf("unable to convert object: %v", err)
+	}
+	err = keepServerPopulatedFields(current, modified)
+	if err != nil {
+		return fmt.Errorf("unable to keep server populated fields: %v", err)
+	}
+	err = copyFields(current.Object, modified.Object, opts.IgnoreFields)
+	if err != nil {
+		return fmt.Errorf("unable to keep ignored fields: %v", err)
+	}
+	modified.SetResourceVersion(current.GetResourceVersion())
+
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	restClient, err := a.restClientFor(gvk.GroupVersion())
+	if err != nil {
+		return fmt.Errorf("unable to construct REST client for GroupVersion %s: %v", gvk.GroupVersion().String(), err)
+	}
+
+	mapping, err := a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return fmt.Errorf("unable to get REST mapping for GroupVersionKind %s: %v", gvk.String(), err)
+	}
+
+	updateOptions := &metav1.UpdateOptions{}
+	if *opts.ServerDryRun {
+		updateOptions.DryRun = []string{metav1.DryRunAll}
+	}
+
+	result := &unstructured.Unstructured{}
+	err = restClient.Put().
+		NamespaceIfScoped(metadata.GetNamespace(), isNamespaced(mapping)).
+		Resource(mapping.Resource.Resource).
+		Name(metadata.GetName()).
+		Body(modified).
+		VersionedParams(updateOptions, metav1.ParameterCodec).
+		Context(ctx).
+		Do().
+		Into(result)
+	if err != nil {
+		return wrapError(err, "error updating object")
+	}
+
+	if opts.Patch != nil {
+		patch, err := diffObjects(current, result)
+		if err != nil {
+			return fmt.Errorf("unable to compute replaced fields: %v", err)
+		}
+		*opts.Patch = patch
+	}
+
+	// Copy the result into the modified runtime.Object
+	err = a.copyInto(result, obj)
+	if err != nil {
+		return fmt.Errorf("error copying response: %v", err)
+	}
+	return nil
+}
+
+// lastAppliedChanged returns whether the last applied configuration of the
+// modified object differs from the one recorded on the current object
+func lastAppliedChanged(current *unstructured.Unstructured, modified runtime.Object) (bool, error) {
+	original, err := getOriginalConfiguration(current)
+	if err != nil {
+		return false, err
+	}
+	if original == nil {
+		return true, nil
+	}
+	desired, err := getOriginalConfiguration(modified)
+	if err != nil {
+		return false, err
+	}
+
+	var originalValue, desiredValue interface{}
+	if err := json.Unmarshal(original, &originalValue); err != nil {
+		// An unreadable annotation can't be trusted, so replace the resource
+		return true, nil
+	}
+	if err := json.Unmarshal(desired, &desiredValue); err != nil {
+		return false, err
+	}
+	return !reflect.DeepEqual(originalValue, desiredValue), nil
+}
+
+// toUnstructured returns a copy of the object as an unstructured object
+func toUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u.DeepCopy(), nil
+	}
+	data, err := runtime.Encode(unstructured.UnstructuredJSONScheme, obj)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{}
+	if err := u.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
 func (a *Applier) update(ctx context.Context, opts *ApplyOptions, current, modified runtime.Object) error {
 	metadata, err := meta.Accessor(modified)
 	if err != nil {
@@ -253,9 +518,9 @@ func (a *Applier) update(ctx context.Context, opts *ApplyOptions, current, modif
 	)
 	log.V(2).Info("updating resource", "dry-run", *opts.ServerDryRun)
 
-	modifiedJSON, err := getModifiedConfiguration(modified, true, unstructured.UnstructuredJSONScheme)
+	modifiedJSON, err := modifiedConfiguration(opts, modified)
 	if err != nil {
-		return fmt.Errorf("unable to get modified configuration: %v", err)
+		return err
 	}
 
 	patcher, err := a.newPatcher(opts, modified)
@@ -263,9 +528,12 @@ func (a *Applier) update(ctx context.Context, opts *ApplyOptions, current, modif
 		return fmt.Errorf("unable to construct patcher: %v", err)
 	}
 	source := metadata.GetSelfLink() // This is optional and would normally be the file path
-	_, patchedObj, err := patcher.Patch(current, modifiedJSON, source, metadata.GetNamespace(), metadata.GetName(), nil)
+	patch, patchedObj, err := patcher.Patch(current, modifiedJSON, source, metadata.GetNamespace(), metadata.GetName(), nil)
 	if err != nil {
-		return fmt.Errorf("unable to patch object: %v", err)
+		return wrapError(err, "unable to patch object")
+	}
+	if opts.Patch != nil {
+		*opts.Patch = patch
 	}
 
 	// Copy the patchedObj into the modified runtime.Object
@@ -276,6 +544,20 @@ func (a *Applier) update(ctx context.Context, opts *ApplyOptions, current, modif
 	return nil
 }
 
+// modifiedConfiguration returns the modified configuration of the object,
+// without the fields that are ignored
+func modifiedConfiguration(opts *ApplyOptions, modified runtime.Object) ([]byte, error) {
+	desired, err := withoutFields(modified, opts.IgnoreFields)
+	if err != nil {
+		return nil, err
+	}
+	modifiedJSON, err := getModifiedConfiguration(desired, true, unstructured.UnstructuredJSONScheme)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get modified configuration: %v", err)
+	}
+	return modifiedJSON, nil
+}
+
 func (a *Applier) newPatcher(opts *ApplyOptions, obj runtime.Object) (*Patcher, error) {
 	gvk := obj.GetObjectKind().GroupVersionKind()
 	mapping, err := a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
@@ -297,11 +579,15 @@ func (a *Applier) newPatcher(opts *ApplyOptions, obj runtime.Object) (*Patcher,
 		BackOff:       clockwork.NewRealClock(),
 		Force:         *opts.ForceDeletion,
 		Cascade:       *opts.CascadeDeletion,
+		Propagation:   opts.DeletionPropagation,
 		Timeout:       *opts.DeletionTimeout,
 		GracePeriod:   *opts.DeletionGracePeriod,
 		ServerDryRun:  *opts.ServerDryRun,
 		OpenapiSchema: nil, // Not supporting OpenapiSchema patching
 		Retries:       maxPatchRetry,
+		IgnoreFields:  opts.IgnoreFields,
+
+		RecreateOnImmutable: *opts.RecreateOnImmutable,
 	}
 	return p, nil
 }
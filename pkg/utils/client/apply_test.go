@@ -25,9 +25,12 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/pusher/faros/pkg/utils/client/test"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -168,6 +171,40 @@ var _ = Describe("Applier Suite", func() {
 				})
 			})
 
+			Context("with the container image ignored", func() {
+				BeforeEach(func() {
+					o.IgnoreFields = []string{"/spec/template/spec/containers/*/image"}
+					replicas := int32(3)
+					deployment.Spec.Replicas = &replicas
+					Expect(a.Apply(context.TODO(), o, deployment)).NotTo(HaveOccurred())
+				})
+
+				It("should not update the container's image", func() {
+					serverDeployment := test.ExampleDeployment.DeepCopy()
+					m.Get(serverDeployment, timeout).Should(Succeed())
+					Expect(serverDeployment).Should(test.WithContainers(ContainElement(test.WithImage(Equal("nginx")))))
+				})
+
+				It("should update other fields", func() {
+					serverDeployment := test.ExampleDeployment.DeepCopy()
+					m.Get(serverDeployment, timeout).Should(Succeed())
+					Expect(*serverDeployment.Spec.Replicas).To(Equal(int32(3)))
+				})
+			})
+
+			Context("with a Patch", func() {
+				var patch []byte
+
+				BeforeEach(func() {
+					o.Patch = &patch
+					Expect(a.Apply(context.TODO(), o, deployment)).NotTo(HaveOccurred())
+				})
+
+				It("returns the patch sent to the server", func() {
+					Expect(string(patch)).To(ContainSubstring("nginx:latest"))
+				})
+			})
+
 			Context("with ServerDryRun true", func() {
 				BeforeEach(func() {
 					if skipDryRun {
@@ -204,6 +241,286 @@ var _ = Describe("Applier Suite", func() {
 		})
 	})
 
+	Describe("Diff", func() {
+		Context("when the deployment does not exist", func() {
+			It("returns a NotFound error", func() {
+				_, err := a.Diff(context.TODO(), o, deployment)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+			})
+		})
+
+		Context("when the deployment has been applied", func() {
+			BeforeEach(func() {
+				Expect(a.Apply(context.TODO(), o, deployment.DeepCopy())).NotTo(HaveOccurred())
+			})
+
+			It("returns an empty patch if nothing changed", func() {
+				patch, err := a.Diff(context.TODO(), o, deployment)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(patch)).To(Equal("{}"))
+			})
+
+			Context("and the deployment is modified", func() {
+				BeforeEach(func() {
+					deployment.Spec.Template.Spec.Containers[0].Image = "nginx:latest"
+				})
+
+				It("returns a patch containing the change", func() {
+					patch, err := a.Diff(context.TODO(), o, deployment)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(patch)).To(ContainSubstring("nginx:latest"))
+				})
+
+				It("should not update the server deployment", func() {
+					_, err := a.Diff(context.TODO(), o, deployment)
+					Expect(err).NotTo(HaveOccurred())
+
+					serverDeployment := test.ExampleDeployment.DeepCopy()
+					m.Get(serverDeployment, timeout).Should(Succeed())
+					Expect(serverDeployment).Should(test.WithContainers(ContainElement(test.WithImage(Equal("nginx")))))
+				})
+			})
+		})
+	})
+
+	Describe("Replace", func() {
+		Context("when the deployment has been replaced", func() {
+			var replacedVersion string
+
+			BeforeEach(func() {
+				Expect(a.Replace(context.TODO(), o, deployment)).NotTo(HaveOccurred())
+				m.Get(deployment, timeout).Should(Succeed())
+
+				// Another controller annotates the deployment
+				annotated := deployment.DeepCopy()
+				annotated.Annotations["deployment.kubernetes.io/revision"] = "1"
+				Expect(c.Update(context.TODO(), annotated)).To(Succeed())
+				replacedVersion = annotated.GetResourceVersion()
+			})
+
+			Context("and the deployment is unchanged", func() {
+				BeforeEach(func() {
+					var patch []byte
+					o.Patch = &patch
+					deployment = test.ExampleDeployment.DeepCopy()
+					Expect(a.Replace(context.TODO(), o, deployment)).NotTo(HaveOccurred())
+				})
+
+				It("should not replace the deployment", func() {
+					Expect(deployment).Should(test.WithResourceVersion(Equal(replacedVersion)))
+					Expect(*o.Patch).To(BeEmpty())
+				})
+
+				It("should keep annotations added by other controllers", func() {
+					serverDeployment := test.ExampleDeployment.DeepCopy()
+					m.Get(serverDeployment, timeout).Should(Succeed())
+					Expect(serverDeployment.Annotations).To(HaveKeyWithValue("deployment.kubernetes.io/revision", "1"))
+				})
+			})
+
+			Context("and the deployment is modified", func() {
+				BeforeEach(func() {
+					deployment = test.ExampleDeployment.DeepCopy()
+					deployment.Spec.Template.Spec.Containers[0].Image = "nginx:latest"
+					Expect(a.Replace(context.TODO(), o, deployment)).NotTo(HaveOccurred())
+				})
+
+				It("should update the container's image", func() {
+					Expect(deployment).Should(test.WithContainers(ContainElement(test.WithImage(Equal("nginx:latest")))))
+				})
+
+				It("should remove annotations added by other controllers", func() {
+					Expect(deployment.Annotations).NotTo(HaveKey("deployment.kubernetes.io/revision"))
+				})
+			})
+
+			Context("and the deployment is changed by another controller", func() {
+				BeforeEach(func() {
+					// Retry as the cache may not have seen the annotation yet
+					Eventually(func() error {
+						serverDeployment := test.ExampleDeployment.DeepCopy()
+						if err := c.Get(context.TODO(), types.NamespacedName{Namespace: serverDeployment.Namespace, Name: serverDeployment.Name}, serverDeployment); err != nil {
+							return err
+						}
+						serverDeployment.Spec.Template.Spec.Containers[0].Image = "nginx:latest"
+						return c.Update(context.TODO(), serverDeployment)
+					}, timeout).Should(Succeed())
+
+					deployment = test.ExampleDeployment.DeepCopy()
+					Expect(a.Replace(context.TODO(), o, deployment)).NotTo(HaveOccurred())
+				})
+
+				It("should revert the change", func() {
+					Expect(deployment).Should(test.WithContainers(ContainElement(test.WithImage(Equal("nginx")))))
+				})
+			})
+
+			Context("and the deployment is modified with its replicas ignored", func() {
+				BeforeEach(func() {
+					// Retry as the cache may not have seen the annotation yet
+					Eventually(func() error {
+						serverDeployment := test.ExampleDeployment.DeepCopy()
+						if err := c.Get(context.TODO(), types.NamespacedName{Namespace: serverDeployment.Namespace, Name: serverDeployment.Name}, serverDeployment); err != nil {
+							return err
+						}
+						replicas := int32(5)
+						serverDeployment.Spec.Replicas = &replicas
+						return c.Update(context.TODO(), serverDeployment)
+					}, timeout).Should(Succeed())
+
+					o.IgnoreFields = []string{"/spec/replicas"}
+					deployment = test.ExampleDeployment.DeepCopy()
+					deployment.Spec.Template.Spec.Containers[0].Image = "nginx:latest"
+					Expect(a.Replace(context.TODO(), o, deployment)).NotTo(HaveOccurred())
+				})
+
+				It("should update the container's image", func() {
+					Expect(deployment).Should(test.WithContainers(ContainElement(test.WithImage(Equal("nginx:latest")))))
+				})
+
+				It("should keep the current value of the ignored field", func() {
+					Expect(*deployment.Spec.Replicas).To(Equal(int32(5)))
+				})
+			})
+		})
+
+		Context("when a service without a clusterIP has been replaced", func() {
+			var service *corev1.Service
+			var clusterIP string
+
+			BeforeEach(func() {
+				service = &corev1.Service{
+					TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+					ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
+					Spec: corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 80}},
+					},
+				}
+				Expect(a.Replace(context.TODO(), o, service.DeepCopy())).NotTo(HaveOccurred())
+				created := service.DeepCopy()
+				m.Get(created, timeout).Should(Succeed())
+				clusterIP = created.Spec.ClusterIP
+				Expect(clusterIP).NotTo(BeEmpty())
+			})
+
+			AfterEach(func() {
+				Expect(c.Delete(context.TODO(), service)).To(Succeed())
+			})
+
+			It("should keep the clusterIP when the service is modified", func() {
+				service.Labels = map[string]string{"app": "nginx"}
+				Expect(a.Replace(context.TODO(), o, service)).NotTo(HaveOccurred())
+				Expect(service.Labels).To(HaveKeyWithValue("app", "nginx"))
+				Expect(service.Spec.ClusterIP).To(Equal(clusterIP))
+			})
+		})
+	})
+
+	Describe("with server-side apply", func() {
+		BeforeEach(func() {
+			if skipServerSideApply {
+				Skip("the API server doesn't support server-side apply")
+			}
+			serverSideApply := true
+			o.ServerSideApply = &serverSideApply
+		})
+
+		Context("when the deployment does not exist", func() {
+			BeforeEach(func() {
+				Expect(a.Apply(context.TODO(), o, deployment)).NotTo(HaveOccurred())
+			})
+
+			It("creates the deployment", func() {
+				m.Get(test.ExampleDeployment.DeepCopy(), timeout).Should(Succeed())
+			})
+
+			It("does not set the last applied annotation", func() {
+				Expect(deployment.GetAnnotations()).NotTo(HaveKey(LastAppliedAnnotation))
+			})
+		})
+
+		Context("when the deployment does not exist and its replicas are ignored", func() {
+			BeforeEach(func() {
+				o.IgnoreFields = []string{"/spec/replicas"}
+				replicas := int32(3)
+				deployment.Spec.Replicas = &replicas
+				Expect(a.Apply(context.TODO(), o, deployment)).NotTo(HaveOccurred())
+			})
+
+			It("creates the deployment with the ignored field", func() {
+				serverDeployment := test.ExampleDeployment.DeepCopy()
+				m.Get(serverDeployment, timeout).Should(Succeed())
+				Expect(*serverDeployment.Spec.Replicas).To(Equal(int32(3)))
+			})
+
+			It("does not update the ignored field once the deployment exists", func() {
+				serverDeployment := test.ExampleDeployment.DeepCopy()
+				m.Get(serverDeployment, timeout).Should(Succeed())
+				replicas := int32(5)
+				serverDeployment.Spec.Replicas = &replicas
+				Expect(c.Update(context.TODO(), serverDeployment)).To(Succeed())
+
+				Expect(a.Apply(context.TODO(), o, deployment)).NotTo(HaveOccurred())
+				m.Get(serverDeployment, timeout).Should(Succeed())
+				Expect(*serverDeployment.Spec.Replicas).To(Equal(int32(5)))
+			})
+		})
+
+		Context("when the deployment was applied client-side", func() {
+			BeforeEach(func() {
+				Expect(a.Apply(context.TODO(), &ApplyOptions{}, deployment.DeepCopy())).NotTo(HaveOccurred())
+				deployment.Spec.Template.Spec.Containers[0].Image = "nginx:latest"
+				Expect(a.Apply(context.TODO(), o, deployment)).NotTo(HaveOccurred())
+			})
+
+			It("should update the container's image", func() {
+				serverDeployment := test.ExampleDeployment.DeepCopy()
+				m.Get(serverDeployment, timeout).Should(Succeed())
+				Expect(serverDeployment).Should(test.WithContainers(ContainElement(test.WithImage(Equal("nginx:latest")))))
+			})
+
+			It("removes the last applied annotation", func() {
+				serverDeployment := test.ExampleDeployment.DeepCopy()
+				m.Get(serverDeployment, timeout).Should(Succeed())
+				Expect(serverDeployment.GetAnnotations()).NotTo(HaveKey(LastAppliedAnnotation))
+			})
+		})
+
+		Context("when another manager has changed a field", func() {
+			BeforeEach(func() {
+				Expect(a.Apply(context.TODO(), o, deployment.DeepCopy())).NotTo(HaveOccurred())
+
+				serverDeployment := test.ExampleDeployment.DeepCopy()
+				m.Get(serverDeployment, timeout).Should(Succeed())
+				serverDeployment.Spec.Template.Spec.Containers[0].Image = "nginx:1.15"
+				Expect(c.Update(context.TODO(), serverDeployment)).To(Succeed())
+
+				deployment.Spec.Template.Spec.Containers[0].Image = "nginx:latest"
+			})
+
+			It("returns a conflict error", func() {
+				err := a.Apply(context.TODO(), o, deployment)
+				Expect(IsApplyConflict(err)).To(BeTrue())
+			})
+
+			It("takes ownership of the field with ForceConflicts true", func() {
+				forceConflicts := true
+				o.ForceConflicts = &forceConflicts
+				Expect(a.Apply(context.TODO(), o, deployment)).NotTo(HaveOccurred())
+
+				serverDeployment := test.ExampleDeployment.DeepCopy()
+				m.Get(serverDeployment, timeout).Should(Succeed())
+				Expect(serverDeployment).Should(test.WithContainers(ContainElement(test.WithImage(Equal("nginx:latest")))))
+			})
+
+			It("returns a patch reverting the change from Diff", func() {
+				patch, err := a.Diff(context.TODO(), o, deployment)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(patch)).To(ContainSubstring("nginx:latest"))
+			})
+		})
+	})
+
 	Describe("with CRDs", func() {
 		var foo *unstructured.Unstructured
 		var crd *apiextensionsv1beta1.CustomResourceDefinition
@@ -25,6 +25,7 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/pusher/faros/pkg/utils"
 	"github.com/pusher/faros/test/reporters"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/klogr"
@@ -36,6 +37,7 @@ import (
 
 var cfg *rest.Config
 var skipDryRun bool
+var skipServerSideApply bool
 
 func TestMain(t *testing.T) {
 	RegisterFailHandler(Fail)
@@ -57,6 +59,10 @@ var _ = BeforeSuite(func() {
 		skipDryRun, err = strconv.ParseBool(skipDryRunEnv)
 		Expect(err).NotTo(HaveOccurred())
 	}
+	// The API server used by the tests may predate server-side apply
+	verifier, err := utils.NewServerSideApplyVerifier(cfg)
+	Expect(err).NotTo(HaveOccurred())
+	skipServerSideApply = verifier.HasSupport() != nil
 })
 
 var _ = AfterSuite(func() {
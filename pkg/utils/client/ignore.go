@@ -0,0 +1,238 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// wildcard matches every element of a list or every key of a map in a field
+// path
+const wildcard = "*"
+
+// ParseFieldPath splits a field path into its segments.
+//
+// Paths may either be JSON pointers (eg. /spec/replicas) or simple JSONPaths
+// (eg. .spec.replicas or $.spec.template.spec.containers[*].image).
+// A segment of `*` matches every element of a list or every key of a map.
+func ParseFieldPath(path string) ([]string, error) {
+	switch {
+	case strings.HasPrefix(path, "/"):
+		segments := strings.Split(path[1:], "/")
+		for i, s := range segments {
+			segments[i] = strings.Replace(strings.Replace(s, "~1", "/", -1), "~0", "~", -1)
+		}
+		return validSegments(path, segments)
+	case strings.HasPrefix(path, "."), strings.HasPrefix(path, "$."):
+		path = strings.TrimPrefix(path, "$")
+		// Convert list indexes to segments, eg. containers[0] to containers.0
+		normalized := strings.NewReplacer("[", ".", "]", "").Replace(path[1:])
+		return validSegments(path, strings.Split(normalized, "."))
+	default:
+		return nil, fmt.Errorf("invalid field path %q: must be a JSON pointer or start with '.'", path)
+	}
+}
+
+// validSegments checks that none of the segments of the path are empty
+func validSegments(path string, segments []string) ([]string, error) {
+	for _, s := range segments {
+		if s == "" {
+			return nil, fmt.Errorf("invalid field path %q: empty segment", path)
+		}
+	}
+	return segments, nil
+}
+
+// removeFields removes the fields at the given paths from the JSON document
+func removeFields(data []byte, paths []string) ([]byte, error) {
+	if len(paths) == 0 || len(data) == 0 {
+		return data, nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal configuration: %v", err)
+	}
+	for _, path := range paths {
+		segments, err := ParseFieldPath(path)
+		if err != nil {
+			return nil, err
+		}
+		removeField(doc, segments)
+	}
+	return json.Marshal(doc)
+}
+
+// removeField removes the field at the path given by the segments from the
+// object, ignoring paths that don't exist
+func removeField(obj interface{}, segments []string) {
+	if len(segments) == 0 {
+		return
+	}
+	segment, rest := segments[0], segments[1:]
+
+	switch o := obj.(type) {
+	case map[string]interface{}:
+		if segment == wildcard {
+			for key := range o {
+				removeKey(o, key, rest)
+			}
+			return
+		}
+		removeKey(o, segment, rest)
+	case []interface{}:
+		if segment == wildcard {
+			for _, item := range o {
+				removeField(item, rest)
+			}
+			return
+		}
+		// Removing list elements would change the indexes of later elements
+		// so only fields within elements are removed
+		i, err := strconv.Atoi(segment)
+		if err != nil || i < 0 || i >= len(o) {
+			return
+		}
+		removeField(o[i], rest)
+	}
+}
+
+// removeKey removes the key from the map if it is the last segment of the path
+// or continues along the path otherwise
+func removeKey(obj map[string]interface{}, key string, rest []string) {
+	if len(rest) == 0 {
+		delete(obj, key)
+		return
+	}
+	if value, ok := obj[key]; ok {
+		removeField(value, rest)
+	}
+}
+
+// withoutFields returns a copy of the object with the fields at the given
+// paths removed
+func withoutFields(obj runtime.Object, paths []string) (runtime.Object, error) {
+	if len(paths) == 0 {
+		return obj, nil
+	}
+
+	data, err := runtime.Encode(unstructured.UnstructuredJSONScheme, obj)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode object: %v", err)
+	}
+	data, err = removeFields(data, paths)
+	if err != nil {
+		return nil, fmt.Errorf("unable to remove ignored fields: %v", err)
+	}
+	u := &unstructured.Unstructured{}
+	if err := u.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("unable to decode object: %v", err)
+	}
+	return u, nil
+}
+
+// copyFields sets the fields at the given paths in the object to their values
+// in the source object, removing those that the source doesn't have. Fields
+// within list elements are only copied if the element exists in both objects.
+func copyFields(from, to map[string]interface{}, paths []string) error {
+	for _, path := range paths {
+		segments, err := ParseFieldPath(path)
+		if err != nil {
+			return err
+		}
+		copyField(from, to, segments)
+	}
+	return nil
+}
+
+// copyField copies the field at the path given by the segments from one
+// object to the other
+func copyField(from, to interface{}, segments []string) {
+	if len(segments) == 0 {
+		return
+	}
+	segment, rest := segments[0], segments[1:]
+
+	switch t := to.(type) {
+	case map[string]interface{}:
+		f, ok := from.(map[string]interface{})
+		if !ok {
+			return
+		}
+		if segment == wildcard {
+			for key := range f {
+				copyKey(f, t, key, rest)
+			}
+			for key := range t {
+				copyKey(f, t, key, rest)
+			}
+			return
+		}
+		copyKey(f, t, segment, rest)
+	case []interface{}:
+		f, ok := from.([]interface{})
+		// As with removeField, only fields within list elements are copied
+		if !ok || len(rest) == 0 {
+			return
+		}
+		if segment == wildcard {
+			for i := range t {
+				if i < len(f) {
+					copyField(f[i], t[i], rest)
+				}
+			}
+			return
+		}
+		i, err := strconv.Atoi(segment)
+		if err != nil || i < 0 || i >= len(t) || i >= len(f) {
+			return
+		}
+		copyField(f[i], t[i], rest)
+	}
+}
+
+// copyKey copies the key from one map to the other if it is the last segment
+// of the path or continues along the path otherwise
+func copyKey(from, to map[string]interface{}, key string, rest []string) {
+	if len(rest) == 0 {
+		if value, ok := from[key]; ok {
+			to[key] = runtime.DeepCopyJSONValue(value)
+		} else {
+			delete(to, key)
+		}
+		return
+	}
+	fromValue, ok := from[key]
+	if !ok {
+		return
+	}
+	toValue, ok := to[key]
+	if _, isMap := fromValue.(map[string]interface{}); !ok && isMap {
+		// Add the missing map so that the fields within it can be copied
+		toValue = map[string]interface{}{}
+		to[key] = toValue
+	} else if !ok {
+		return
+	}
+	copyField(fromValue, toValue, rest)
+}
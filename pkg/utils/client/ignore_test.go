@@ -0,0 +1,127 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Ignored fields", func() {
+	Context("ParseFieldPath", func() {
+		It("parses JSON pointers", func() {
+			Expect(ParseFieldPath("/metadata/annotations/example.com~1key")).To(Equal([]string{"metadata", "annotations", "example.com/key"}))
+		})
+
+		It("parses JSONPaths", func() {
+			Expect(ParseFieldPath("$.spec.template.spec.containers[*].image")).To(Equal([]string{"spec", "template", "spec", "containers", "*", "image"}))
+			Expect(ParseFieldPath(".spec.replicas")).To(Equal([]string{"spec", "replicas"}))
+		})
+
+		It("rejects invalid paths", func() {
+			_, err := ParseFieldPath("spec.replicas")
+			Expect(err).To(HaveOccurred())
+			_, err = ParseFieldPath("/spec//replicas")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("removeFields", func() {
+		const doc = `{"spec":{"replicas":3,"template":{"spec":{"containers":[{"name":"a","image":"nginx"},{"name":"b","image":"redis"}]}}}}`
+
+		It("removes fields", func() {
+			data, err := removeFields([]byte(doc), []string{"/spec/replicas"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(`{"spec":{"template":{"spec":{"containers":[{"image":"nginx","name":"a"},{"image":"redis","name":"b"}]}}}}`))
+		})
+
+		It("removes fields from every list element", func() {
+			data, err := removeFields([]byte(doc), []string{".spec.template.spec.containers[*].image"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(`{"spec":{"replicas":3,"template":{"spec":{"containers":[{"name":"a"},{"name":"b"}]}}}}`))
+		})
+
+		It("removes fields from a single list element", func() {
+			data, err := removeFields([]byte(doc), []string{"/spec/template/spec/containers/1/image"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(`{"spec":{"replicas":3,"template":{"spec":{"containers":[{"image":"nginx","name":"a"},{"name":"b"}]}}}}`))
+		})
+
+		It("ignores paths that don't exist", func() {
+			data, err := removeFields([]byte(doc), []string{"/status/replicas", "/spec/template/spec/containers/5/image"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(MatchJSON(doc))
+		})
+
+		It("leaves empty documents untouched", func() {
+			data, err := removeFields(nil, []string{"/spec/replicas"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(BeNil())
+		})
+	})
+
+	Context("copyFields", func() {
+		var from, to map[string]interface{}
+
+		BeforeEach(func() {
+			from = map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{"example.com/key": "value"},
+				},
+				"spec": map[string]interface{}{
+					"replicas": int64(5),
+					"containers": []interface{}{
+						map[string]interface{}{"name": "a", "image": "nginx:latest"},
+					},
+				},
+			}
+			to = map[string]interface{}{
+				"metadata": map[string]interface{}{},
+				"spec": map[string]interface{}{
+					"replicas": int64(3),
+					"paused":   true,
+					"containers": []interface{}{
+						map[string]interface{}{"name": "a", "image": "nginx"},
+						map[string]interface{}{"name": "b", "image": "redis"},
+					},
+				},
+			}
+		})
+
+		It("copies fields", func() {
+			Expect(copyFields(from, to, []string{"/spec/replicas", "/metadata/annotations/example.com~1key"})).To(Succeed())
+			Expect(to["spec"]).To(HaveKeyWithValue("replicas", int64(5)))
+			Expect(to["metadata"]).To(HaveKeyWithValue("annotations", HaveKeyWithValue("example.com/key", "value")))
+		})
+
+		It("removes fields the source doesn't have", func() {
+			Expect(copyFields(from, to, []string{"/spec/paused"})).To(Succeed())
+			Expect(to["spec"]).NotTo(HaveKey("paused"))
+		})
+
+		It("copies fields of list elements that exist in both objects", func() {
+			Expect(copyFields(from, to, []string{".spec.containers[*].image"})).To(Succeed())
+			containers := to["spec"].(map[string]interface{})["containers"].([]interface{})
+			Expect(containers[0]).To(HaveKeyWithValue("image", "nginx:latest"))
+			Expect(containers[1]).To(HaveKeyWithValue("image", "redis"))
+		})
+
+		It("rejects invalid paths", func() {
+			Expect(copyFields(from, to, []string{"spec.replicas"})).NotTo(Succeed())
+		})
+	})
+})
@@ -0,0 +1,94 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// serverPopulatedFields are the immutable fields the API server or other
+// controllers fill in when they are left out of a resource. Replacing the
+// resource without them would change them, which is rejected.
+var serverPopulatedFields = map[schema.GroupKind][][]string{
+	{Group: "", Kind: "Service"}: {
+		{"spec", "clusterIP"},
+	},
+	{Group: "", Kind: "PersistentVolumeClaim"}: {
+		{"spec", "volumeName"},
+		{"spec", "storageClassName"},
+	},
+	{Group: "batch", Kind: "Job"}: {
+		{"spec", "selector"},
+		{"spec", "template", "metadata", "labels", "controller-uid"},
+		{"spec", "template", "metadata", "labels", "job-name"},
+	},
+}
+
+// IsImmutableFieldError returns whether the error is the API server rejecting
+// a change to a field that can't be changed once the object is created, for
+// example the template of a Job, the clusterIP of a Service or the selector of
+// a StatefulSet
+func IsImmutableFieldError(err error) bool {
+	if !errors.IsInvalid(err) {
+		return false
+	}
+	status, ok := err.(errors.APIStatus)
+	if !ok || status.Status().Details == nil {
+		return false
+	}
+	for _, cause := range status.Status().Details.Causes {
+		if strings.Contains(cause.Message, "immutable") {
+			return true
+		}
+		// StatefulSets reject updates to most of their spec as forbidden
+		if cause.Type == metav1.CauseTypeFieldValueForbidden && strings.Contains(cause.Message, "updates to") {
+			return true
+		}
+	}
+	return false
+}
+
+// keepServerPopulatedFields copies the server populated immutable fields that
+// the modified resource leaves out from the current resource
+func keepServerPopulatedFields(current, modified *unstructured.Unstructured) error {
+	for _, path := range serverPopulatedFields[current.GroupVersionKind().GroupKind()] {
+		_, found, err := unstructured.NestedFieldNoCopy(modified.Object, path...)
+		if err != nil {
+			return err
+		}
+		if found {
+			continue
+		}
+		value, found, err := unstructured.NestedFieldCopy(current.Object, path...)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		err = unstructured.SetNestedField(modified.Object, value, path...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
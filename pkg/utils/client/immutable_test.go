@@ -0,0 +1,104 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var _ = Describe("IsImmutableFieldError", func() {
+	jobGK := schema.GroupKind{Group: "batch", Kind: "Job"}
+
+	It("matches changes to immutable fields", func() {
+		err := errors.NewInvalid(jobGK, "example", field.ErrorList{
+			field.Invalid(field.NewPath("spec", "template"), nil, "field is immutable"),
+		})
+		Expect(IsImmutableFieldError(err)).To(BeTrue())
+	})
+
+	It("matches forbidden updates to StatefulSets", func() {
+		err := errors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "StatefulSet"}, "example", field.ErrorList{
+			field.Forbidden(field.NewPath("spec"), "updates to statefulset spec for fields other than 'replicas', 'template', and 'updateStrategy' are forbidden"),
+		})
+		Expect(IsImmutableFieldError(err)).To(BeTrue())
+	})
+
+	It("does not match other invalid values", func() {
+		err := errors.NewInvalid(jobGK, "example", field.ErrorList{
+			field.Required(field.NewPath("spec", "template"), ""),
+		})
+		Expect(IsImmutableFieldError(err)).To(BeFalse())
+	})
+
+	It("does not match other errors", func() {
+		Expect(IsImmutableFieldError(fmt.Errorf("field is immutable"))).To(BeFalse())
+	})
+})
+
+var _ = Describe("keepServerPopulatedFields", func() {
+	var current *unstructured.Unstructured
+
+	BeforeEach(func() {
+		current = &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"spec": map[string]interface{}{
+				"clusterIP": "10.0.0.10",
+				"type":      "ClusterIP",
+			},
+		}}
+	})
+
+	It("copies server populated fields left out of the modified object", func() {
+		modified := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"spec":       map[string]interface{}{},
+		}}
+		Expect(keepServerPopulatedFields(current, modified)).To(Succeed())
+		Expect(modified.Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("clusterIP", "10.0.0.10")))
+	})
+
+	It("keeps server populated fields set in the modified object", func() {
+		modified := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"spec": map[string]interface{}{
+				"clusterIP": "None",
+			},
+		}}
+		Expect(keepServerPopulatedFields(current, modified)).To(Succeed())
+		Expect(modified.Object).To(HaveKeyWithValue("spec", HaveKeyWithValue("clusterIP", "None")))
+	})
+
+	It("doesn't copy other fields", func() {
+		modified := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"spec":       map[string]interface{}{},
+		}}
+		Expect(keepServerPopulatedFields(current, modified)).To(Succeed())
+		Expect(modified.Object).To(HaveKeyWithValue("spec", Not(HaveKey("type"))))
+	})
+})
@@ -64,7 +64,7 @@ const (
 )
 
 func (p *Patcher) delete(namespace, name string) error {
-	return runDelete(namespace, name, p.Mapping, p.DynamicClient, p.Cascade, p.GracePeriod, p.ServerDryRun)
+	return runDelete(namespace, name, p.Mapping, p.DynamicClient, p.Cascade, p.Propagation, p.GracePeriod, p.ServerDryRun)
 }
 
 // Patcher is used to perform a three-way-merge on runtime.Objects
@@ -78,6 +78,7 @@ type Patcher struct {
 
 	Force        bool
 	Cascade      bool
+	Propagation  *metav1.DeletionPropagation
 	Timeout      time.Duration
 	GracePeriod  int
 	ServerDryRun bool
@@ -89,19 +90,63 @@ type Patcher struct {
 	Retries int
 
 	OpenapiSchema openapi.Resources
+
+	// Paths of fields that are removed from the original configuration, so
+	// that they are never patched
+	IgnoreFields []string
+
+	// If set, deletes and recreates the object when the patch changes
+	// immutable fields
+	RecreateOnImmutable bool
 }
 
 func (p *Patcher) patchSimple(obj runtime.Object, modified []byte, source, namespace, name string, errOut io.Writer) ([]byte, runtime.Object, error) {
+	patchType, patch, err := p.createPatch(obj, modified, source, errOut)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if string(patch) == "{}" {
+		return patch, obj, nil
+	}
+
+	if p.ResourceVersion != nil {
+		patch, err = addResourceVersion(patch, *p.ResourceVersion)
+		if err != nil {
+			return nil, nil, addSourceToErr("Failed to insert resourceVersion in patch", source, err)
+		}
+	}
+
+	options := metav1.UpdateOptions{}
+	if p.ServerDryRun {
+		options.DryRun = []string{metav1.DryRunAll}
+	}
+
+	patchedObj, err := p.Helper.Patch(namespace, name, patchType, patch, &options)
+	return patch, patchedObj, err
+}
+
+// createPatch computes the three way merge patch between the original
+// configuration of the object, the modified configuration and the current
+// configuration of the object from the server.
+func (p *Patcher) createPatch(obj runtime.Object, modified []byte, source string, errOut io.Writer) (types.PatchType, []byte, error) {
 	// Serialize the current configuration of the object from the server.
 	current, err := runtime.Encode(unstructured.UnstructuredJSONScheme, obj)
 	if err != nil {
-		return nil, nil, addSourceToErr(fmt.Sprintf("serializing current configuration from:\n%v\nfor:", obj), source, err)
+		return "", nil, addSourceToErr(fmt.Sprintf("serializing current configuration from:\n%v\nfor:", obj), source, err)
 	}
 
 	// Retrieve the original configuration of the object from the annotation.
 	original, err := getOriginalConfiguration(obj)
 	if err != nil {
-		return nil, nil, addSourceToErr(fmt.Sprintf("retrieving original configuration from:\n%v\nfor:", obj), source, err)
+		return "", nil, addSourceToErr(fmt.Sprintf("retrieving original configuration from:\n%v\nfor:", obj), source, err)
+	}
+
+	// Remove ignored fields from the original configuration so that they are
+	// neither updated nor deleted by the patch
+	original, err = removeFields(original, p.IgnoreFields)
+	if err != nil {
+		return "", nil, addSourceToErr("removing ignored fields from original configuration for:", source, err)
 	}
 
 	var patchType types.PatchType
@@ -122,12 +167,12 @@ func (p *Patcher) patchSimple(obj runtime.Object, modified []byte, source, names
 		patch, err = jsonmergepatch.CreateThreeWayJSONMergePatch(original, modified, current, preconditions...)
 		if err != nil {
 			if mergepatch.IsPreconditionFailed(err) {
-				return nil, nil, fmt.Errorf("%s", "At least one of apiVersion, kind and name was changed")
+				return "", nil, fmt.Errorf("%s", "At least one of apiVersion, kind and name was changed")
 			}
-			return nil, nil, addSourceToErr(fmt.Sprintf(createPatchErrFormat, original, modified, current), source, err)
+			return "", nil, addSourceToErr(fmt.Sprintf(createPatchErrFormat, original, modified, current), source, err)
 		}
 	case err != nil:
-		return nil, nil, addSourceToErr(fmt.Sprintf("getting instance of versioned object for %v:", p.Mapping.GroupVersionKind), source, err)
+		return "", nil, addSourceToErr(fmt.Sprintf("getting instance of versioned object for %v:", p.Mapping.GroupVersionKind), source, err)
 	default:
 		// Compute a three way strategic merge patch to send to server.
 		patchType = types.StrategicMergePatchType
@@ -149,33 +194,16 @@ func (p *Patcher) patchSimple(obj runtime.Object, modified []byte, source, names
 		if patch == nil {
 			lookupPatchMeta, err = strategicpatch.NewPatchMetaFromStruct(versionedObject)
 			if err != nil {
-				return nil, nil, addSourceToErr(fmt.Sprintf(createPatchErrFormat, original, modified, current), source, err)
+				return "", nil, addSourceToErr(fmt.Sprintf(createPatchErrFormat, original, modified, current), source, err)
 			}
 			patch, err = strategicpatch.CreateThreeWayMergePatch(original, modified, current, lookupPatchMeta, p.Overwrite)
 			if err != nil {
-				return nil, nil, addSourceToErr(fmt.Sprintf(createPatchErrFormat, original, modified, current), source, err)
+				return "", nil, addSourceToErr(fmt.Sprintf(createPatchErrFormat, original, modified, current), source, err)
 			}
 		}
 	}
 
-	if string(patch) == "{}" {
-		return patch, obj, nil
-	}
-
-	if p.ResourceVersion != nil {
-		patch, err = addResourceVersion(patch, *p.ResourceVersion)
-		if err != nil {
-			return nil, nil, addSourceToErr("Failed to insert resourceVersion in patch", source, err)
-		}
-	}
-
-	options := metav1.UpdateOptions{}
-	if p.ServerDryRun {
-		options.DryRun = []string{metav1.DryRunAll}
-	}
-
-	patchedObj, err := p.Helper.Patch(namespace, name, patchType, patch, &options)
-	return patch, patchedObj, err
+	return patchType, patch, nil
 }
 
 // Patch performs a strategic three way merge patch on the given object.
@@ -201,6 +229,8 @@ func (p *Patcher) Patch(current runtime.Object, modified []byte, source, namespa
 	}
 	if err != nil && (errors.IsConflict(err) || errors.IsInvalid(err)) && p.Force {
 		patchBytes, patchObject, err = p.deleteAndCreate(current, modified, namespace, name)
+	} else if err != nil && p.RecreateOnImmutable && IsImmutableFieldError(err) {
+		patchBytes, patchObject, err = p.deleteAndCreate(current, modified, namespace, name)
 	}
 	return patchBytes, patchObject, err
 }
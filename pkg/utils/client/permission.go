@@ -0,0 +1,58 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// PermissionDeniedError is returned when the API server forbids a request the
+// Applier makes, for example because the user it impersonates lacks the RBAC
+// permissions to manage the resource
+type PermissionDeniedError struct {
+	msg string
+	err error
+}
+
+// Error implements the error interface
+func (e *PermissionDeniedError) Error() string {
+	return fmt.Sprintf("%s: %v", e.msg, e.err)
+}
+
+// IsPermissionDenied returns whether the error is a PermissionDeniedError or a
+// Forbidden error returned by the API server
+func IsPermissionDenied(err error) bool {
+	if _, ok := err.(*PermissionDeniedError); ok {
+		return true
+	}
+	return errors.IsForbidden(err)
+}
+
+// wrapError adds the message to the error. Forbidden errors are wrapped in a
+// PermissionDeniedError so that they can still be recognised by callers.
+func wrapError(err error, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	if e, ok := err.(*PermissionDeniedError); ok {
+		return &PermissionDeniedError{msg: fmt.Sprintf("%s: %s", msg, e.msg), err: e.err}
+	}
+	if errors.IsForbidden(err) {
+		return &PermissionDeniedError{msg: msg, err: err}
+	}
+	return fmt.Errorf("%s: %v", msg, err)
+}
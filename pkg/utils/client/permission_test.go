@@ -0,0 +1,51 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("IsPermissionDenied", func() {
+	forbidden := errors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"}, "example", fmt.Errorf("access denied"))
+
+	It("matches Forbidden errors", func() {
+		Expect(IsPermissionDenied(forbidden)).To(BeTrue())
+	})
+
+	It("matches wrapped Forbidden errors", func() {
+		err := wrapError(forbidden, "error creating object")
+		Expect(IsPermissionDenied(err)).To(BeTrue())
+		Expect(err.Error()).To(HavePrefix("error creating object: "))
+	})
+
+	It("keeps every message when wrapped twice", func() {
+		err := wrapError(wrapError(forbidden, "unable to patch object"), "error applying update")
+		Expect(IsPermissionDenied(err)).To(BeTrue())
+		Expect(err.Error()).To(HavePrefix("error applying update: unable to patch object: "))
+	})
+
+	It("does not match other errors", func() {
+		err := wrapError(errors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "example", fmt.Errorf("conflict")), "error updating object")
+		Expect(IsPermissionDenied(err)).To(BeFalse())
+	})
+})
@@ -0,0 +1,323 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/jsonmergepatch"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// FieldManager is the name of the field manager Faros uses for server-side
+// apply
+const FieldManager = "faros"
+
+// applyPatchType is the content type of server-side apply requests. It is
+// defined here as the vendored apimachinery predates server-side apply.
+const applyPatchType types.PatchType = "application/apply-patch+yaml"
+
+// ApplyConflictError is returned by a server-side apply when fields of the
+// object are owned by another field manager and conflicts aren't forced
+type ApplyConflictError struct {
+	err error
+}
+
+// Error implements the error interface
+func (e *ApplyConflictError) Error() string {
+	return fmt.Sprintf("conflict with other field managers: %v", e.err)
+}
+
+// IsApplyConflict returns whether the error is an ApplyConflictError
+func IsApplyConflict(err error) bool {
+	_, ok := err.(*ApplyConflictError)
+	return ok
+}
+
+// applyServerSide applies the object using server-side apply. Current should be
+// the object as fetched from the API, or nil if it doesn't exist.
+//
+// Objects previously applied with the last applied annotation are migrated:
+// they are updated once more with a three way merge, so that fields removed
+// from the modified configuration are removed, before they are applied
+// server-side and the annotation is removed. As the object then matches the
+// modified configuration, the apply shares ownership of its fields with their
+// previous managers rather than conflicting with them, so conflicts are only
+// forced with ForceConflicts.
+func (a *Applier) applyServerSide(ctx context.Context, opts *ApplyOptions, current *unstructured.Unstructured, modified runtime.Object) error {
+	metadata, err := meta.Accessor(modified)
+	if err != nil {
+		return fmt.Errorf("unable to get object metadata: %v", err)
+	}
+	log := a.log.WithValues(
+		"kind", modified.GetObjectKind().GroupVersionKind().String(),
+		"name", metadata.GetName(),
+		"namespace", metadata.GetNamespace(),
+	)
+
+	migrate := false
+	if current != nil {
+		original, err := getOriginalConfiguration(current)
+		if err != nil {
+			return fmt.Errorf("unable to get original configuration: %v", err)
+		}
+		migrate = original != nil && !*opts.ServerDryRun
+	}
+	if migrate {
+		log.V(1).Info("migrating resource to server-side apply")
+		err = a.update(ctx, opts, current, modified.DeepCopyObject())
+		if err != nil {
+			return wrapError(err, "unable to migrate from last applied configuration")
+		}
+	}
+
+	// Ignored fields are only left out once the object exists, so that they
+	// are set when it is created
+	var ignoreFields []string
+	if current != nil {
+		ignoreFields = opts.IgnoreFields
+	}
+
+	log.V(2).Info("applying resource server-side", "dry-run", *opts.ServerDryRun)
+	result, err := a.patchServerSide(ctx, opts, modified, ignoreFields, *opts.ForceConflicts)
+	if err != nil && current != nil && recreateOnError(opts, err) {
+		log.V(1).Info("recreating resource", "error", err.Error())
+		result, err = a.recreateServerSide(ctx, opts, current, modified)
+	}
+	if err != nil && IsApplyConflict(err) {
+		return err
+	} else if err != nil {
+		return wrapError(err, "error applying object")
+	}
+
+	if migrate {
+		result, err = a.removeLastAppliedAnnotation(ctx, result)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.Patch != nil && current != nil {
+		patch, err := diffObjects(current, result)
+		if err != nil {
+			return fmt.Errorf("unable to compute applied patch: %v", err)
+		}
+		*opts.Patch = patch
+	}
+
+	// Copy the result into the modified runtime.Object
+	err = a.copyInto(result, modified)
+	if err != nil {
+		return fmt.Errorf("error copying response: %v", err)
+	}
+	return nil
+}
+
+// diffServerSide computes the patch a server-side apply of the object would
+// make to the current object by applying it with dry run
+func (a *Applier) diffServerSide(ctx context.Context, opts *ApplyOptions, current *unstructured.Unstructured, modified runtime.Object) ([]byte, error) {
+	dryRunOpts := *opts
+	serverDryRun := true
+	dryRunOpts.ServerDryRun = &serverDryRun
+
+	result, err := a.patchServerSide(ctx, &dryRunOpts, modified, opts.IgnoreFields, true)
+	if err != nil {
+		return nil, wrapError(err, "error applying object")
+	}
+	return diffObjects(current, result)
+}
+
+// recreateOnError returns whether the options require the object to be
+// deleted and created again when the apply fails with the given error
+func recreateOnError(opts *ApplyOptions, err error) bool {
+	if *opts.ForceDeletion && errors.IsInvalid(err) {
+		return true
+	}
+	return *opts.RecreateOnImmutable && IsImmutableFieldError(err)
+}
+
+// recreateServerSide deletes the current object, waits for it to be removed
+// and then applies the object again to create it, including ignored fields
+func (a *Applier) recreateServerSide(ctx context.Context, opts *ApplyOptions, current *unstructured.Unstructured, modified runtime.Object) (*unstructured.Unstructured, error) {
+	gvk := current.GroupVersionKind()
+	mapping, err := a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get REST mapping for GroupVersionKind %s: %v", gvk.String(), err)
+	}
+
+	err = runDelete(current.GetNamespace(), current.GetName(), mapping, a.dynamicClient, *opts.CascadeDeletion, opts.DeletionPropagation, *opts.DeletionGracePeriod, *opts.ServerDryRun)
+	if err != nil {
+		return nil, wrapError(err, "unable to delete object")
+	}
+	if !*opts.ServerDryRun {
+		err = wait.PollImmediate(1*time.Second, *opts.DeletionTimeout, func() (bool, error) {
+			_, err := a.dynamicClient.Resource(mapping.Resource).Namespace(current.GetNamespace()).Get(current.GetName(), metav1.GetOptions{})
+			if errors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		})
+		if err != nil {
+			return nil, wrapError(err, "unable to wait for object to be deleted")
+		}
+	}
+	return a.patchServerSide(ctx, opts, modified, nil, *opts.ForceConflicts)
+}
+
+// patchServerSide sends the server-side apply request for the object, leaving
+// out the given ignored fields. Errors returned by the API are not wrapped so
+// that they can be inspected.
+func (a *Applier) patchServerSide(ctx context.Context, opts *ApplyOptions, modified runtime.Object, ignoreFields []string, force bool) (*unstructured.Unstructured, error) {
+	metadata, err := meta.Accessor(modified)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get object metadata: %v", err)
+	}
+
+	body, err := serverSideConfiguration(modified, ignoreFields)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get applied configuration: %v", err)
+	}
+
+	gvk := modified.GetObjectKind().GroupVersionKind()
+	restClient, err := a.restClientFor(gvk.GroupVersion())
+	if err != nil {
+		return nil, fmt.Errorf("unable to construct REST client for GroupVersion %s: %v", gvk.GroupVersion().String(), err)
+	}
+	mapping, err := a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get REST mapping for GroupVersionKind %s: %v", gvk.String(), err)
+	}
+
+	req := restClient.Patch(applyPatchType).
+		NamespaceIfScoped(metadata.GetNamespace(), isNamespaced(mapping)).
+		Resource(mapping.Resource.Resource).
+		Name(metadata.GetName()).
+		Param("fieldManager", FieldManager)
+	if force {
+		req = req.Param("force", "true")
+	}
+	if *opts.ServerDryRun {
+		req = req.Param("dryRun", metav1.DryRunAll)
+	}
+
+	result := &unstructured.Unstructured{}
+	err = req.Body(body).Context(ctx).Do().Into(result)
+	if err != nil && errors.IsConflict(err) {
+		return nil, &ApplyConflictError{err: err}
+	} else if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// removeLastAppliedAnnotation removes the last applied annotation from an
+// object migrated to server-side apply
+func (a *Applier) removeLastAppliedAnnotation(ctx context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{LastAppliedAnnotation: nil},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to construct patch: %v", err)
+	}
+
+	gvk := obj.GroupVersionKind()
+	restClient, err := a.restClientFor(gvk.GroupVersion())
+	if err != nil {
+		return nil, fmt.Errorf("unable to construct REST client for GroupVersion %s: %v", gvk.GroupVersion().String(), err)
+	}
+	mapping, err := a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get REST mapping for GroupVersionKind %s: %v", gvk.String(), err)
+	}
+
+	result := &unstructured.Unstructured{}
+	err = restClient.Patch(types.MergePatchType).
+		NamespaceIfScoped(obj.GetNamespace(), isNamespaced(mapping)).
+		Resource(mapping.Resource.Resource).
+		Name(obj.GetName()).
+		Param("fieldManager", FieldManager).
+		Body(patch).
+		Context(ctx).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, wrapError(err, "unable to remove last applied annotation")
+	}
+	return result, nil
+}
+
+// serverSideConfiguration returns the configuration of the object to send
+// with server-side apply, without read-only metadata, managed fields, the last
+// applied annotation or ignored fields
+func serverSideConfiguration(obj runtime.Object, ignoreFields []string) ([]byte, error) {
+	paths := append([]string{"/metadata/managedFields"}, ignoreFields...)
+	desired, err := withoutFields(obj.DeepCopyObject(), paths)
+	if err != nil {
+		return nil, err
+	}
+
+	annots, err := metadataAccessor.Annotations(desired)
+	if err != nil {
+		return nil, err
+	}
+	if annots != nil {
+		delete(annots, LastAppliedAnnotation)
+		if err := metadataAccessor.SetAnnotations(desired, annots); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := clearReadOnlyMeta(desired); err != nil {
+		return nil, err
+	}
+	return runtime.Encode(unstructured.UnstructuredJSONScheme, desired)
+}
+
+// diffObjects returns the JSON merge patch from the current object to the
+// result of an apply, ignoring metadata maintained by the server
+func diffObjects(current, result *unstructured.Unstructured) ([]byte, error) {
+	currentJSON, err := comparableJSON(current)
+	if err != nil {
+		return nil, err
+	}
+	resultJSON, err := comparableJSON(result)
+	if err != nil {
+		return nil, err
+	}
+	return jsonmergepatch.CreateThreeWayJSONMergePatch(currentJSON, resultJSON, currentJSON)
+}
+
+// comparableJSON serializes the object without metadata maintained by the
+// server
+func comparableJSON(obj *unstructured.Unstructured) ([]byte, error) {
+	u := obj.DeepCopy()
+	unstructured.RemoveNestedField(u.Object, "metadata", "managedFields")
+	unstructured.RemoveNestedField(u.Object, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(u.Object, "metadata", "generation")
+	return u.MarshalJSON()
+}
@@ -201,7 +201,9 @@ func addSourceToErr(verb string, source string, err error) error {
 	return err
 }
 
-func runDelete(namespace, name string, mapping *meta.RESTMapping, c dynamic.Interface, cascade bool, gracePeriod int, serverDryRun bool) error {
+// runDelete deletes the object. If propagation is set, it overrides the
+// propagation policy chosen by cascade.
+func runDelete(namespace, name string, mapping *meta.RESTMapping, c dynamic.Interface, cascade bool, propagation *metav1.DeletionPropagation, gracePeriod int, serverDryRun bool) error {
 	options := &metav1.DeleteOptions{}
 	if gracePeriod >= 0 {
 		options = metav1.NewDeleteOptions(int64(gracePeriod))
@@ -213,6 +215,9 @@ func runDelete(namespace, name string, mapping *meta.RESTMapping, c dynamic.Inte
 	if !cascade {
 		policy = metav1.DeletePropagationOrphan
 	}
+	if propagation != nil {
+		policy = *propagation
+	}
 	options.PropagationPolicy = &policy
 	return c.Resource(mapping.Resource).Namespace(namespace).Delete(name, options)
 }
@@ -0,0 +1,114 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultKubeconfigKey is the key of the kubeconfig within the Secret
+// referenced by a GitTrackCluster when no key is given
+const DefaultKubeconfigKey = "kubeconfig"
+
+// RemoteChildFinalizer is added to (Cluster)GitTrackObjects managing a child in
+// a remote cluster, where the child isn't garbage collected, so that the child
+// can be deleted before the (Cluster)GitTrackObject is removed.
+// It is removed without deleting the child when the child is orphaned.
+const RemoteChildFinalizer = "faros.pusher.com/remote-child"
+
+// GetKubeconfig reads the kubeconfig referenced by the GitTrackCluster from
+// its Secret in the given namespace
+func GetKubeconfig(c client.Client, namespace string, cluster *farosv1alpha1.GitTrackCluster) ([]byte, error) {
+	key := cluster.Key
+	if key == "" {
+		key = DefaultKubeconfigKey
+	}
+
+	secret := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: cluster.SecretName}, secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up secret %s: %v", cluster.SecretName, err)
+	}
+
+	kubeconfig, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("invalid cluster secret %s: key %s not found", cluster.SecretName, key)
+	}
+	return kubeconfig, nil
+}
+
+// ClusterKey identifies the kubeconfig Secret referenced by the GitTrackCluster
+// in the given namespace, formatted as <namespace>/<secret name>/<key>
+func ClusterKey(namespace string, cluster *farosv1alpha1.GitTrackCluster) string {
+	key := cluster.Key
+	if key == "" {
+		key = DefaultKubeconfigKey
+	}
+	return fmt.Sprintf("%s/%s/%s", namespace, cluster.SecretName, key)
+}
+
+// RESTConfigFromKubeconfig creates a rest.Config from a kubeconfig read from
+// the Secret of a GitTrackCluster.
+//
+// The Secret is supplied by the users of GitTracks rather than the operators
+// of Faros, so only inline credentials are allowed. Exec and auth provider
+// plugins would run with the identity of Faros, and paths would read files
+// such as its own ServiceAccount token and send them to the remote cluster.
+func RESTConfigFromKubeconfig(kubeconfig []byte) (*rest.Config, error) {
+	config, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load kubeconfig: %v", err)
+	}
+	if err = validateKubeconfig(config); err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig: %v", err)
+	}
+	return clientcmd.NewDefaultClientConfig(*config, &clientcmd.ConfigOverrides{}).ClientConfig()
+}
+
+// validateKubeconfig returns an error if any cluster or user of the kubeconfig
+// reads credentials from anywhere but the kubeconfig itself
+func validateKubeconfig(config *clientcmdapi.Config) error {
+	for name, cluster := range config.Clusters {
+		if cluster.CertificateAuthority != "" {
+			return fmt.Errorf("cluster %s sets certificate-authority, use certificate-authority-data instead", name)
+		}
+	}
+	for name, user := range config.AuthInfos {
+		switch {
+		case user.Exec != nil:
+			return fmt.Errorf("user %s sets exec, which is not allowed", name)
+		case user.AuthProvider != nil:
+			return fmt.Errorf("user %s sets auth-provider, which is not allowed", name)
+		case user.TokenFile != "":
+			return fmt.Errorf("user %s sets tokenFile, use token instead", name)
+		case user.ClientCertificate != "":
+			return fmt.Errorf("user %s sets client-certificate, use client-certificate-data instead", name)
+		case user.ClientKey != "":
+			return fmt.Errorf("user %s sets client-key, use client-key-data instead", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package utils_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	. "github.com/pusher/faros/pkg/utils"
+)
+
+// kubeconfigWith returns a kubeconfig whose cluster and user have the given
+// fields in addition to the server and a token
+func kubeconfigWith(clusterFields, userFields string) []byte {
+	return []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: remote
+  cluster:
+    server: https://remote.example.com
+%s
+users:
+- name: faros
+  user:
+    token: abcdef
+%s
+contexts:
+- name: remote
+  context:
+    cluster: remote
+    user: faros
+current-context: remote
+`, clusterFields, userFields))
+}
+
+var _ = Describe("ClusterKey", func() {
+	It("formats the namespace, Secret and key", func() {
+		cluster := &farosv1alpha1.GitTrackCluster{SecretName: "remote", Key: "config"}
+		Expect(ClusterKey("example", cluster)).To(Equal("example/remote/config"))
+	})
+
+	It("uses the default key when none is given", func() {
+		cluster := &farosv1alpha1.GitTrackCluster{SecretName: "remote"}
+		Expect(ClusterKey("example", cluster)).To(Equal("example/remote/kubeconfig"))
+	})
+})
+
+var _ = Describe("RESTConfigFromKubeconfig", func() {
+	It("loads a kubeconfig with inline credentials", func() {
+		config, err := RESTConfigFromKubeconfig(kubeconfigWith(
+			"    certificate-authority-data: Y2EtZGF0YQ==",
+			"    client-certificate-data: Y2VydC1kYXRh\n    client-key-data: a2V5LWRhdGE=",
+		))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.Host).To(Equal("https://remote.example.com"))
+		Expect(config.BearerToken).To(Equal("abcdef"))
+		Expect(config.CAData).To(Equal([]byte("ca-data")))
+	})
+
+	for _, rejected := range []struct {
+		field         string
+		clusterFields string
+		userFields    string
+	}{
+		{field: "certificate-authority", clusterFields: "    certificate-authority: /var/run/secrets/kubernetes.io/serviceaccount/ca.crt"},
+		{field: "exec", userFields: "    exec:\n      apiVersion: client.authentication.k8s.io/v1alpha1\n      command: /bin/sh"},
+		{field: "auth-provider", userFields: "    auth-provider:\n      name: gcp"},
+		{field: "tokenFile", userFields: "    tokenFile: /var/run/secrets/kubernetes.io/serviceaccount/token"},
+		{field: "client-certificate", userFields: "    client-certificate: /etc/faros/tls.crt"},
+		{field: "client-key", userFields: "    client-key: /etc/faros/tls.key"},
+	} {
+		rejected := rejected
+		It(fmt.Sprintf("rejects a kubeconfig using %s", rejected.field), func() {
+			_, err := RESTConfigFromKubeconfig(kubeconfigWith(rejected.clusterFields, rejected.userFields))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("sets %s", rejected.field)))
+		})
+	}
+})
@@ -0,0 +1,78 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// DeletionPropagationAnnotation is the annotation used to choose the
+	// propagation policy used when deleting a child
+	DeletionPropagationAnnotation = "faros.pusher.com/deletion-propagation"
+
+	// DeleteProtectionAnnotation is the annotation used to protect a child
+	// from being deleted, or to lift the protection of protected kinds
+	DeleteProtectionAnnotation = "faros.pusher.com/delete-protection"
+
+	// DeleteProtectionEnabled is the value of the DeleteProtectionAnnotation
+	// which protects a child from being deleted
+	DeleteProtectionEnabled = "enabled"
+
+	// DeleteProtectionDisabled is the value of the DeleteProtectionAnnotation
+	// which allows a child of a protected kind to be deleted
+	DeleteProtectionDisabled = "disabled"
+)
+
+// GetDeletionPropagation returns the propagation policy set by the
+// `faros.pusher.com/deletion-propagation` annotation, or nil if the annotation
+// isn't set
+func GetDeletionPropagation(obj metav1.Object) (*metav1.DeletionPropagation, error) {
+	value, ok := obj.GetAnnotations()[DeletionPropagationAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	propagation := metav1.DeletionPropagation(value)
+	switch propagation {
+	case metav1.DeletePropagationForeground, metav1.DeletePropagationBackground, metav1.DeletePropagationOrphan:
+		return &propagation, nil
+	default:
+		return nil, fmt.Errorf("invalid deletion propagation: %s", value)
+	}
+}
+
+// DeleteProtected returns whether the object is protected from deletion,
+// either by the `faros.pusher.com/delete-protection` annotation or because its
+// kind is protected and the annotation doesn't lift the protection
+func DeleteProtected(obj *unstructured.Unstructured, protectedKinds map[schema.GroupKind]bool) (bool, error) {
+	value, ok := obj.GetAnnotations()[DeleteProtectionAnnotation]
+	if !ok {
+		return protectedKinds[obj.GroupVersionKind().GroupKind()], nil
+	}
+	switch value {
+	case DeleteProtectionEnabled:
+		return true, nil
+	case DeleteProtectionDisabled:
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid delete protection: %s", value)
+	}
+}
@@ -0,0 +1,70 @@
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/pusher/faros/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("Deletion", func() {
+	var obj *unstructured.Unstructured
+
+	BeforeEach(func() {
+		obj = &unstructured.Unstructured{}
+		obj.SetAPIVersion("v1")
+		obj.SetKind("Namespace")
+		obj.SetName("example")
+	})
+
+	Context("GetDeletionPropagation", func() {
+		It("returns nil without the annotation", func() {
+			Expect(GetDeletionPropagation(obj)).To(BeNil())
+		})
+
+		It("returns the propagation policy of the annotation", func() {
+			obj.SetAnnotations(map[string]string{DeletionPropagationAnnotation: "Orphan"})
+			propagation, err := GetDeletionPropagation(obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*propagation).To(Equal(metav1.DeletePropagationOrphan))
+		})
+
+		It("errors for an invalid propagation policy", func() {
+			obj.SetAnnotations(map[string]string{DeletionPropagationAnnotation: "Sometimes"})
+			_, err := GetDeletionPropagation(obj)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("DeleteProtected", func() {
+		protectedKinds := map[schema.GroupKind]bool{{Kind: "Namespace"}: true}
+
+		It("protects protected kinds", func() {
+			Expect(DeleteProtected(obj, protectedKinds)).To(BeTrue())
+		})
+
+		It("does not protect other kinds", func() {
+			obj.SetKind("ConfigMap")
+			Expect(DeleteProtected(obj, protectedKinds)).To(BeFalse())
+		})
+
+		It("allows the annotation to lift the protection", func() {
+			obj.SetAnnotations(map[string]string{DeleteProtectionAnnotation: DeleteProtectionDisabled})
+			Expect(DeleteProtected(obj, protectedKinds)).To(BeFalse())
+		})
+
+		It("allows the annotation to protect other kinds", func() {
+			obj.SetKind("ConfigMap")
+			obj.SetAnnotations(map[string]string{DeleteProtectionAnnotation: DeleteProtectionEnabled})
+			Expect(DeleteProtected(obj, protectedKinds)).To(BeTrue())
+		})
+
+		It("errors for an invalid value", func() {
+			obj.SetAnnotations(map[string]string{DeleteProtectionAnnotation: "maybe"})
+			_, err := DeleteProtected(obj, protectedKinds)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,37 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/rest"
+)
+
+// ServiceAccountUsername returns the username the API server authenticates the
+// ServiceAccount in the namespace as
+func ServiceAccountUsername(namespace, name string) string {
+	return fmt.Sprintf("%s%s:%s", serviceAccountUsernamePrefix, namespace, name)
+}
+
+// ImpersonateServiceAccount returns a copy of the rest.Config that
+// impersonates the ServiceAccount in the namespace
+func ImpersonateServiceAccount(config *rest.Config, namespace, name string) *rest.Config {
+	impersonated := rest.CopyConfig(config)
+	impersonated.Impersonate = rest.ImpersonationConfig{UserName: ServiceAccountUsername(namespace, name)}
+	return impersonated
+}
@@ -0,0 +1,32 @@
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/pusher/faros/pkg/utils"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("ImpersonateServiceAccount", func() {
+	var config *rest.Config
+
+	BeforeEach(func() {
+		config = &rest.Config{Host: "https://example.com", BearerToken: "token"}
+	})
+
+	It("impersonates the ServiceAccount", func() {
+		impersonated := ImpersonateServiceAccount(config, "example", "deployer")
+		Expect(impersonated.Impersonate.UserName).To(Equal("system:serviceaccount:example:deployer"))
+	})
+
+	It("keeps the rest of the config", func() {
+		impersonated := ImpersonateServiceAccount(config, "example", "deployer")
+		Expect(impersonated.Host).To(Equal(config.Host))
+		Expect(impersonated.BearerToken).To(Equal(config.BearerToken))
+	})
+
+	It("doesn't modify the original config", func() {
+		ImpersonateServiceAccount(config, "example", "deployer")
+		Expect(config.Impersonate.UserName).To(BeEmpty())
+	})
+})
@@ -0,0 +1,122 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// SourcePathAnnotation records the path within the repository of the file
+	// a child was produced from
+	SourcePathAnnotation = "faros.pusher.com/source-path"
+
+	// SourceDocumentAnnotation records the index, starting at 1, of the object
+	// a child was produced from among the objects in its file
+	SourceDocumentAnnotation = "faros.pusher.com/source-document"
+
+	// SourceCommitAnnotation records the SHA of the commit a child was
+	// produced from
+	SourceCommitAnnotation = "faros.pusher.com/source-commit"
+
+	// GitTrackAnnotation records the namespaced name of the GitTrack a child
+	// was produced by
+	GitTrackAnnotation = "faros.pusher.com/gittrack"
+)
+
+// provenanceAnnotations are the annotations recording where a child was
+// produced from
+var provenanceAnnotations = []string{
+	SourcePathAnnotation,
+	SourceDocumentAnnotation,
+	SourceCommitAnnotation,
+	GitTrackAnnotation,
+}
+
+// SetSource records the file and the index of the object within the file that
+// the object was produced from in its annotations
+func SetSource(obj metav1.Object, path string, document int) {
+	setAnnotation(obj, SourcePathAnnotation, path)
+	setAnnotation(obj, SourceDocumentAnnotation, strconv.Itoa(document))
+}
+
+// SetGitTrack records the GitTrack the object was produced by in its
+// annotations, unless it is empty
+func SetGitTrack(obj metav1.Object, gitTrack string) {
+	setAnnotation(obj, GitTrackAnnotation, gitTrack)
+}
+
+// SetSourceCommit records the commit the object was produced from in its
+// annotations, unless it is empty
+func SetSourceCommit(obj metav1.Object, commit string) {
+	setAnnotation(obj, SourceCommitAnnotation, commit)
+}
+
+// CopyProvenance copies the annotations recording where an object was
+// produced from to another object
+func CopyProvenance(from, to metav1.Object) {
+	for _, key := range provenanceAnnotations {
+		setAnnotation(to, key, from.GetAnnotations()[key])
+	}
+}
+
+// SourceFile describes the file, and the object within the file, that the
+// object was produced from, or returns an empty string if it isn't recorded
+func SourceFile(obj metav1.Object) string {
+	annotations := obj.GetAnnotations()
+	path := annotations[SourcePathAnnotation]
+	if path == "" {
+		return ""
+	}
+	if document := annotations[SourceDocumentAnnotation]; document != "" && document != "1" {
+		return fmt.Sprintf("%s (document %s)", path, document)
+	}
+	return path
+}
+
+// DescribeSource describes the file and commit the object was produced from,
+// or returns an empty string if they aren't recorded
+func DescribeSource(obj metav1.Object) string {
+	source := SourceFile(obj)
+	if source == "" {
+		return ""
+	}
+	annotations := obj.GetAnnotations()
+	if commit := annotations[SourceCommitAnnotation]; commit != "" {
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		source = fmt.Sprintf("%s at %s", source, commit)
+	}
+	return source
+}
+
+// setAnnotation sets the annotation on the object unless the value is empty
+func setAnnotation(obj metav1.Object, key, value string) {
+	if value == "" {
+		return
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[key] = value
+	obj.SetAnnotations(annotations)
+}
@@ -0,0 +1,93 @@
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/pusher/faros/pkg/utils"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Provenance", func() {
+	var obj *unstructured.Unstructured
+
+	BeforeEach(func() {
+		obj = &unstructured.Unstructured{}
+		obj.SetAPIVersion("v1")
+		obj.SetKind("ConfigMap")
+		obj.SetName("example")
+		obj.SetAnnotations(map[string]string{"example": "annotation"})
+	})
+
+	Context("SetSource", func() {
+		It("records the file and document", func() {
+			SetSource(obj, "config/example.yaml", 2)
+			Expect(obj.GetAnnotations()).To(Equal(map[string]string{
+				"example":                "annotation",
+				SourcePathAnnotation:     "config/example.yaml",
+				SourceDocumentAnnotation: "2",
+			}))
+		})
+	})
+
+	Context("SetGitTrack and SetSourceCommit", func() {
+		It("record the GitTrack and commit", func() {
+			SetGitTrack(obj, "default/example")
+			SetSourceCommit(obj, "a14443638218c782b84cae56a14f1090ee9e5c9c")
+			Expect(obj.GetAnnotations()).To(HaveKeyWithValue(GitTrackAnnotation, "default/example"))
+			Expect(obj.GetAnnotations()).To(HaveKeyWithValue(SourceCommitAnnotation, "a14443638218c782b84cae56a14f1090ee9e5c9c"))
+		})
+
+		It("don't record empty values", func() {
+			SetGitTrack(obj, "")
+			SetSourceCommit(obj, "")
+			Expect(obj.GetAnnotations()).To(Equal(map[string]string{"example": "annotation"}))
+		})
+	})
+
+	Context("CopyProvenance", func() {
+		It("copies only the provenance annotations", func() {
+			SetSource(obj, "config/example.yaml", 1)
+			SetGitTrack(obj, "default/example")
+			SetSourceCommit(obj, "a14443638218c782b84cae56a14f1090ee9e5c9c")
+			to := &unstructured.Unstructured{}
+			CopyProvenance(obj, to)
+			Expect(to.GetAnnotations()).To(HaveLen(4))
+			Expect(to.GetAnnotations()).NotTo(HaveKey("example"))
+		})
+	})
+
+	Context("SourceFile", func() {
+		It("returns an empty string without a recorded file", func() {
+			Expect(SourceFile(obj)).To(BeEmpty())
+		})
+
+		It("returns the file without the commit", func() {
+			SetSource(obj, "config/example.yaml", 1)
+			SetSourceCommit(obj, "a14443638218c782b84cae56a14f1090ee9e5c9c")
+			Expect(SourceFile(obj)).To(Equal("config/example.yaml"))
+		})
+
+		It("includes the document of files with several objects", func() {
+			SetSource(obj, "config/example.yaml", 2)
+			Expect(SourceFile(obj)).To(Equal("config/example.yaml (document 2)"))
+		})
+	})
+
+	Context("DescribeSource", func() {
+		It("returns an empty string without a recorded file", func() {
+			Expect(DescribeSource(obj)).To(BeEmpty())
+		})
+
+		It("describes the file and commit", func() {
+			SetSource(obj, "config/example.yaml", 1)
+			SetGitTrack(obj, "default/example")
+			SetSourceCommit(obj, "a14443638218c782b84cae56a14f1090ee9e5c9c")
+			Expect(DescribeSource(obj)).To(Equal("config/example.yaml at a144436"))
+		})
+
+		It("includes the document of files with several objects", func() {
+			SetSource(obj, "config/example.yaml", 2)
+			Expect(DescribeSource(obj)).To(Equal("config/example.yaml (document 2)"))
+		})
+	})
+})
@@ -0,0 +1,39 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReconcileRequestedAnnotation is the annotation used on GitTracks and
+// (Cluster)GitTrackObjects to request an immediate reconcile. Its value is a
+// token, conventionally the current time, which is recorded in the status of
+// the object once the requested reconcile has been completed.
+const ReconcileRequestedAnnotation = "faros.pusher.com/reconcile-requested-at"
+
+// ReconcileRequest returns the token of the
+// `faros.pusher.com/reconcile-requested-at` annotation on the object, or an
+// empty string if the annotation isn't set or its token has already been
+// handled
+func ReconcileRequest(obj metav1.Object, lastHandled string) string {
+	token := obj.GetAnnotations()[ReconcileRequestedAnnotation]
+	if token == lastHandled {
+		return ""
+	}
+	return token
+}
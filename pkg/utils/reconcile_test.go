@@ -0,0 +1,33 @@
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/pusher/faros/pkg/utils"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("ReconcileRequest", func() {
+	var obj *unstructured.Unstructured
+
+	BeforeEach(func() {
+		obj = &unstructured.Unstructured{}
+		obj.SetAPIVersion("faros.pusher.com/v1alpha1")
+		obj.SetKind("GitTrack")
+		obj.SetName("example")
+	})
+
+	It("returns an empty token without the annotation", func() {
+		Expect(ReconcileRequest(obj, "")).To(BeEmpty())
+	})
+
+	It("returns the token of an unhandled request", func() {
+		obj.SetAnnotations(map[string]string{ReconcileRequestedAnnotation: "2019-01-02T00:00:00Z"})
+		Expect(ReconcileRequest(obj, "2019-01-01T00:00:00Z")).To(Equal("2019-01-02T00:00:00Z"))
+	})
+
+	It("returns an empty token once the request has been handled", func() {
+		obj.SetAnnotations(map[string]string{ReconcileRequestedAnnotation: "2019-01-02T00:00:00Z"})
+		Expect(ReconcileRequest(obj, "2019-01-02T00:00:00Z")).To(BeEmpty())
+	})
+})
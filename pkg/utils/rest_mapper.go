@@ -18,8 +18,12 @@ package utils
 
 import (
 	"fmt"
+	"strings"
 
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
@@ -58,3 +62,75 @@ func GetAPIResource(restMapper meta.RESTMapper, gvk schema.GroupVersionKind) (sc
 	}
 	return mapping.Resource, mapping.Scope == meta.RESTScopeNamespace, nil
 }
+
+// clusterScopedKinds are the built in kinds which aren't namespaced
+var clusterScopedKinds = map[schema.GroupKind]bool{
+	{Group: "", Kind: "ComponentStatus"}:                                            true,
+	{Group: "", Kind: "Namespace"}:                                                  true,
+	{Group: "", Kind: "Node"}:                                                       true,
+	{Group: "", Kind: "PersistentVolume"}:                                           true,
+	{Group: "admissionregistration.k8s.io", Kind: "InitializerConfiguration"}:       true,
+	{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"}:   true,
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}: true,
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}:               true,
+	{Group: "apiregistration.k8s.io", Kind: "APIService"}:                           true,
+	{Group: "auditregistration.k8s.io", Kind: "AuditSink"}:                          true,
+	{Group: "certificates.k8s.io", Kind: "CertificateSigningRequest"}:               true,
+	{Group: "extensions", Kind: "PodSecurityPolicy"}:                                true,
+	{Group: "faros.pusher.com", Kind: "ClusterGitTrackObject"}:                      true,
+	{Group: "policy", Kind: "PodSecurityPolicy"}:                                    true,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"}:                       true,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"}:                true,
+	{Group: "scheduling.k8s.io", Kind: "PriorityClass"}:                             true,
+	{Group: "storage.k8s.io", Kind: "StorageClass"}:                                 true,
+	{Group: "storage.k8s.io", Kind: "VolumeAttachment"}:                             true,
+}
+
+// NewStaticRestMapper creates a restMapper without an API server from the kinds
+// known to the scheme and those defined by any CustomResourceDefinitions in the
+// given objects
+func NewStaticRestMapper(s *runtime.Scheme, objects []*unstructured.Unstructured) (meta.RESTMapper, error) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for gvk := range s.AllKnownTypes() {
+		if strings.HasSuffix(gvk.Kind, "List") || gvk.Version == runtime.APIVersionInternal {
+			continue
+		}
+		scope := meta.RESTScopeNamespace
+		if clusterScopedKinds[gvk.GroupKind()] {
+			scope = meta.RESTScopeRoot
+		}
+		mapper.Add(gvk, scope)
+	}
+
+	for _, u := range objects {
+		if u.GroupVersionKind().GroupKind() != apiextensionsv1beta1.SchemeGroupVersion.WithKind("CustomResourceDefinition").GroupKind() {
+			continue
+		}
+		crd := &apiextensionsv1beta1.CustomResourceDefinition{}
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, crd)
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert CustomResourceDefinition %s: %v", u.GetName(), err)
+		}
+
+		scope := meta.RESTScopeNamespace
+		if crd.Spec.Scope == apiextensionsv1beta1.ClusterScoped {
+			scope = meta.RESTScopeRoot
+		}
+		singular := crd.Spec.Names.Singular
+		if singular == "" {
+			singular = strings.ToLower(crd.Spec.Names.Kind)
+		}
+		versions := []string{crd.Spec.Version}
+		for _, version := range crd.Spec.Versions {
+			versions = append(versions, version.Name)
+		}
+		for _, version := range versions {
+			if version == "" {
+				continue
+			}
+			gv := schema.GroupVersion{Group: crd.Spec.Group, Version: version}
+			mapper.AddSpecific(gv.WithKind(crd.Spec.Names.Kind), gv.WithResource(crd.Spec.Names.Plural), gv.WithResource(singular), scope)
+		}
+	}
+	return mapper, nil
+}
@@ -0,0 +1,76 @@
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/pusher/faros/pkg/utils"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var crd = `---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+    plural: widgets
+  scope: Cluster
+  versions:
+  - name: v1
+    served: true
+    storage: true
+`
+
+var _ = Describe("NewStaticRestMapper", func() {
+	var restMapper meta.RESTMapper
+
+	BeforeEach(func() {
+		objects, err := YAMLToUnstructuredSlice([]byte(crd))
+		Expect(err).ToNot(HaveOccurred())
+		restMapper, err = NewStaticRestMapper(scheme.Scheme, objects)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("maps namespaced kinds known to the scheme", func() {
+		gvr, namespaced, err := GetAPIResource(restMapper, schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gvr).To(Equal(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}))
+		Expect(namespaced).To(BeTrue())
+	})
+
+	It("maps cluster scoped kinds known to the scheme", func() {
+		_, namespaced, err := GetAPIResource(restMapper, schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(namespaced).To(BeFalse())
+	})
+
+	It("maps kinds defined by CustomResourceDefinitions", func() {
+		gvr, namespaced, err := GetAPIResource(restMapper, schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gvr.Resource).To(Equal("widgets"))
+		Expect(namespaced).To(BeFalse())
+	})
+
+	It("doesn't map unknown kinds", func() {
+		_, _, err := GetAPIResource(restMapper, schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Gadget"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects invalid CustomResourceDefinitions", func() {
+		invalid := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1beta1",
+			"kind":       "CustomResourceDefinition",
+			"metadata":   map[string]interface{}{"name": "invalid"},
+			"spec":       "invalid",
+		}}
+		_, err := NewStaticRestMapper(runtime.NewScheme(), []*unstructured.Unstructured{invalid})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,95 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"sync"
+
+	openapi_v2 "github.com/googleapis/gnostic/OpenAPIv2"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+)
+
+// applyPatchContentType is the content type of server-side apply requests
+const applyPatchContentType = "application/apply-patch+yaml"
+
+// ServerSideApplyVerifier verifies if the current server supports server-side
+// apply. Sending server-side apply requests to an apiserver that doesn't
+// support it fails with an unsupported media type error for every child.
+//
+// It reads the OpenAPI to see if any PATCH operation accepts apply patches.
+// The result is only looked up once, as the server doesn't gain or lose
+// support without being upgraded.
+type ServerSideApplyVerifier struct {
+	OpenAPIGetter discovery.OpenAPISchemaInterface
+
+	mutex    sync.Mutex
+	checked  bool
+	supports bool
+}
+
+// HasSupport verifies if the server supports server-side apply. An error is
+// returned if it doesn't.
+func (v *ServerSideApplyVerifier) HasSupport() error {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if !v.checked {
+		oapi, err := v.OpenAPIGetter.OpenAPISchema()
+		if err != nil {
+			return fmt.Errorf("failed to download openapi: %v", err)
+		}
+		v.supports = supportsApplyPatch(oapi)
+		v.checked = true
+	}
+	if !v.supports {
+		return fmt.Errorf("server doesn't support server-side apply")
+	}
+	return nil
+}
+
+// supportsApplyPatch returns whether any PATCH operation in the OpenAPI
+// accepts apply patches
+func supportsApplyPatch(oapi *openapi_v2.Document) bool {
+	if oapi.GetPaths() == nil {
+		return false
+	}
+	for _, path := range oapi.GetPaths().GetPath() {
+		patch := path.GetValue().GetPatch()
+		if patch == nil {
+			continue
+		}
+		for _, contentType := range patch.GetConsumes() {
+			if contentType == applyPatchContentType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NewServerSideApplyVerifier constructs a new ServerSideApplyVerifier
+func NewServerSideApplyVerifier(config *rest.Config) (*ServerSideApplyVerifier, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Discovery Client: %v", err)
+	}
+
+	return &ServerSideApplyVerifier{
+		OpenAPIGetter: discoveryClient,
+	}, nil
+}
@@ -0,0 +1,60 @@
+package utils_test
+
+import (
+	openapi_v2 "github.com/googleapis/gnostic/OpenAPIv2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/pusher/faros/pkg/utils"
+)
+
+// fakeOpenAPIGetter returns an OpenAPI document with a PATCH operation
+// accepting the given content types and counts the times it is fetched
+type fakeOpenAPIGetter struct {
+	consumes []string
+	fetched  int
+}
+
+func (f *fakeOpenAPIGetter) OpenAPISchema() (*openapi_v2.Document, error) {
+	f.fetched++
+	return &openapi_v2.Document{
+		Paths: &openapi_v2.Paths{
+			Path: []*openapi_v2.NamedPathItem{
+				{
+					Name: "/api/v1/namespaces/{namespace}/configmaps/{name}",
+					Value: &openapi_v2.PathItem{
+						Get:   &openapi_v2.Operation{Consumes: []string{"*/*"}},
+						Patch: &openapi_v2.Operation{Consumes: f.consumes},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+var _ = Describe("ServerSideApplyVerifier", func() {
+	patchTypes := []string{
+		"application/json-patch+json",
+		"application/merge-patch+json",
+		"application/strategic-merge-patch+json",
+	}
+
+	It("supports servers accepting apply patches", func() {
+		getter := &fakeOpenAPIGetter{consumes: append(patchTypes, "application/apply-patch+yaml")}
+		verifier := &ServerSideApplyVerifier{OpenAPIGetter: getter}
+		Expect(verifier.HasSupport()).To(Succeed())
+	})
+
+	It("doesn't support servers without apply patches", func() {
+		getter := &fakeOpenAPIGetter{consumes: patchTypes}
+		verifier := &ServerSideApplyVerifier{OpenAPIGetter: getter}
+		Expect(verifier.HasSupport()).NotTo(Succeed())
+	})
+
+	It("only fetches the OpenAPI once", func() {
+		getter := &fakeOpenAPIGetter{consumes: patchTypes}
+		verifier := &ServerSideApplyVerifier{OpenAPIGetter: getter}
+		Expect(verifier.HasSupport()).NotTo(Succeed())
+		Expect(verifier.HasSupport()).NotTo(Succeed())
+		Expect(getter.fetched).To(Equal(1))
+	})
+})
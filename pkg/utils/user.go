@@ -0,0 +1,63 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/client-go/rest"
+)
+
+// serviceAccountUsernamePrefix prefixes the usernames of ServiceAccounts
+const serviceAccountUsernamePrefix = "system:serviceaccount:"
+
+// UsernameFor returns the username the config authenticates to the API server
+// as, when it can be determined from the config alone: either the user it
+// impersonates or the subject of its ServiceAccount token, as used when
+// running in a cluster
+func UsernameFor(config *rest.Config) (string, error) {
+	if config.Impersonate.UserName != "" {
+		return config.Impersonate.UserName, nil
+	}
+	if config.BearerToken == "" {
+		return "", fmt.Errorf("config doesn't authenticate with a token")
+	}
+
+	// ServiceAccount tokens are JWTs whose subject is the ServiceAccount's
+	// username. The token is only read, it is verified by the API server.
+	parts := strings.Split(config.BearerToken, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("token is not a ServiceAccount token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return "", fmt.Errorf("unable to decode token: %v", err)
+	}
+	claims := struct {
+		Subject string `json:"sub"`
+	}{}
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return "", fmt.Errorf("unable to decode token: %v", err)
+	}
+	if !strings.HasPrefix(claims.Subject, serviceAccountUsernamePrefix) {
+		return "", fmt.Errorf("token is not a ServiceAccount token")
+	}
+	return claims.Subject, nil
+}
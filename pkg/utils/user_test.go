@@ -0,0 +1,47 @@
+package utils_test
+
+import (
+	"encoding/base64"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/pusher/faros/pkg/utils"
+	"k8s.io/client-go/rest"
+)
+
+// tokenWithClaims returns an unsigned JWT with the given claims
+func tokenWithClaims(claims string) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","kid":""}`))
+	payload := base64.RawURLEncoding.EncodeToString([]byte(claims))
+	return header + "." + payload + ".signature"
+}
+
+var _ = Describe("UsernameFor", func() {
+	It("returns the subject of a ServiceAccount token", func() {
+		config := &rest.Config{BearerToken: tokenWithClaims(`{"iss":"kubernetes/serviceaccount","sub":"system:serviceaccount:faros:faros"}`)}
+		Expect(UsernameFor(config)).To(Equal("system:serviceaccount:faros:faros"))
+	})
+
+	It("returns the impersonated user", func() {
+		config := &rest.Config{
+			BearerToken: tokenWithClaims(`{"sub":"system:serviceaccount:faros:faros"}`),
+			Impersonate: rest.ImpersonationConfig{UserName: "faros"},
+		}
+		Expect(UsernameFor(config)).To(Equal("faros"))
+	})
+
+	It("fails without a token", func() {
+		_, err := UsernameFor(&rest.Config{Username: "admin", Password: "secret"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("fails for tokens that aren't JWTs", func() {
+		_, err := UsernameFor(&rest.Config{BearerToken: "abcdef"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("fails for JWTs of other users", func() {
+		_, err := UsernameFor(&rest.Config{BearerToken: tokenWithClaims(`{"sub":"jane@example.com"}`)})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,84 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// injectCABundle sets the CA bundle of every webhook in the named mutating and
+// validating webhook configurations so that the API server trusts the
+// certificate of the webhook server. Empty names are skipped.
+func injectCABundle(c client.Client, caBundle []byte, mutatingName string, validatingNames ...string) error {
+	if mutatingName != "" {
+		config := &admissionregistrationv1beta1.MutatingWebhookConfiguration{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: mutatingName}, config); err != nil {
+			return fmt.Errorf("unable to get MutatingWebhookConfiguration %s: %v", mutatingName, err)
+		}
+		setCABundle(config.Webhooks, caBundle)
+		if err := c.Update(context.TODO(), config); err != nil {
+			return fmt.Errorf("unable to update MutatingWebhookConfiguration %s: %v", mutatingName, err)
+		}
+	}
+
+	for _, validatingName := range validatingNames {
+		if validatingName == "" {
+			continue
+		}
+		config := &admissionregistrationv1beta1.ValidatingWebhookConfiguration{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: validatingName}, config); err != nil {
+			return fmt.Errorf("unable to get ValidatingWebhookConfiguration %s: %v", validatingName, err)
+		}
+		setCABundle(config.Webhooks, caBundle)
+		if err := c.Update(context.TODO(), config); err != nil {
+			return fmt.Errorf("unable to update ValidatingWebhookConfiguration %s: %v", validatingName, err)
+		}
+	}
+	return nil
+}
+
+// injectConversionCABundle sets the CA bundle of the conversion webhook of each
+// of the named CRDs. CRDs that don't convert through a webhook are skipped.
+func injectConversionCABundle(c client.Client, caBundle []byte, crdNames ...string) error {
+	for _, name := range crdNames {
+		crd := &apiextensionsv1beta1.CustomResourceDefinition{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: name}, crd); err != nil {
+			return fmt.Errorf("unable to get CustomResourceDefinition %s: %v", name, err)
+		}
+		if crd.Spec.Conversion == nil || crd.Spec.Conversion.WebhookClientConfig == nil {
+			continue
+		}
+		crd.Spec.Conversion.WebhookClientConfig.CABundle = caBundle
+		if err := c.Update(context.TODO(), crd); err != nil {
+			return fmt.Errorf("unable to update CustomResourceDefinition %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// setCABundle sets the CA bundle of each of the webhooks
+func setCABundle(webhooks []admissionregistrationv1beta1.Webhook, caBundle []byte) {
+	for i := range webhooks {
+		webhooks[i].ClientConfig.CABundle = caBundle
+	}
+}
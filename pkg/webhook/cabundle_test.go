@@ -0,0 +1,129 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	testutils "github.com/pusher/faros/test/utils"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("injectCABundle", func() {
+	var c client.Client
+	var mutating *admissionregistrationv1beta1.MutatingWebhookConfiguration
+	var validating *admissionregistrationv1beta1.ValidatingWebhookConfiguration
+
+	const timeout = time.Second * 5
+
+	newWebhook := func(name string) admissionregistrationv1beta1.Webhook {
+		path := "/" + name
+		return admissionregistrationv1beta1.Webhook{
+			Name: name + ".faros.pusher.com",
+			ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+				Service:  &admissionregistrationv1beta1.ServiceReference{Name: "faros-webhook-service", Namespace: "faros-system", Path: &path},
+				CABundle: []byte("\\n"),
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		c, err = client.New(cfg, client.Options{})
+		Expect(err).NotTo(HaveOccurred())
+
+		mutating = &admissionregistrationv1beta1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "faros-mutating-webhook-configuration"},
+			Webhooks:   []admissionregistrationv1beta1.Webhook{newWebhook("mutate-gittracks")},
+		}
+		Expect(c.Create(context.TODO(), mutating)).To(Succeed())
+
+		validating = &admissionregistrationv1beta1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "faros-validating-webhook-configuration"},
+			Webhooks: []admissionregistrationv1beta1.Webhook{
+				newWebhook("validate-gittracks"),
+				newWebhook("validate-gittrackobjects"),
+			},
+		}
+		Expect(c.Create(context.TODO(), validating)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		testutils.DeleteAll(cfg, timeout,
+			&admissionregistrationv1beta1.MutatingWebhookConfigurationList{},
+			&admissionregistrationv1beta1.ValidatingWebhookConfigurationList{},
+		)
+	})
+
+	It("sets the CA bundle of every webhook", func() {
+		Expect(injectCABundle(c, []byte("ca"), mutating.Name, validating.Name)).To(Succeed())
+
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: mutating.Name}, mutating)).To(Succeed())
+		Expect(mutating.Webhooks[0].ClientConfig.CABundle).To(Equal([]byte("ca")))
+
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: validating.Name}, validating)).To(Succeed())
+		for _, webhook := range validating.Webhooks {
+			Expect(webhook.ClientConfig.CABundle).To(Equal([]byte("ca")))
+		}
+	})
+
+	It("skips configurations without a name", func() {
+		Expect(injectCABundle(c, []byte("ca"), "", validating.Name)).To(Succeed())
+
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: mutating.Name}, mutating)).To(Succeed())
+		Expect(mutating.Webhooks[0].ClientConfig.CABundle).To(Equal([]byte("\\n")))
+	})
+
+	It("errors if a configuration doesn't exist", func() {
+		Expect(injectCABundle(c, []byte("ca"), "missing", validating.Name)).NotTo(Succeed())
+	})
+})
+
+var _ = Describe("injectConversionCABundle", func() {
+	var c client.Client
+
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		Expect(apiextensionsv1beta1.AddToScheme(s)).To(Succeed())
+		var err error
+		c, err = client.New(cfg, client.Options{Scheme: s})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("skips CRDs without a conversion webhook", func() {
+		crd := &apiextensionsv1beta1.CustomResourceDefinition{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: "gittracks.faros.pusher.com"}, crd)).To(Succeed())
+
+		Expect(injectConversionCABundle(c, []byte("ca"), crdNames...)).To(Succeed())
+
+		updated := &apiextensionsv1beta1.CustomResourceDefinition{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: "gittracks.faros.pusher.com"}, updated)).To(Succeed())
+		Expect(updated.ResourceVersion).To(Equal(crd.ResourceVersion))
+	})
+
+	It("errors if a CRD doesn't exist", func() {
+		Expect(injectConversionCABundle(c, []byte("ca"), "missing.faros.pusher.com")).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,147 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// certName is the name of the serving certificate in the cert directory
+	certName = "tls.crt"
+	// keyName is the name of the serving key in the cert directory
+	keyName = "tls.key"
+	// caCertName is the name of the certificate of the CA that signed the
+	// serving certificate in the cert directory
+	caCertName = "ca.crt"
+
+	// certValidity is how long generated certificates are valid for
+	certValidity = 10 * 365 * 24 * time.Hour
+)
+
+// ensureCerts makes sure the directory holds a serving certificate and key,
+// generating a self-signed CA and a certificate for the DNS names if either
+// is missing. It returns the PEM encoded certificate of the CA, if known, for
+// the API server to trust.
+func ensureCerts(dir string, dnsNames []string) ([]byte, error) {
+	_, certErr := os.Stat(filepath.Join(dir, certName))
+	_, keyErr := os.Stat(filepath.Join(dir, keyName))
+	if certErr == nil && keyErr == nil {
+		// The certificate was provided, for example by cert-manager
+		caCert, err := ioutil.ReadFile(filepath.Join(dir, caCertName))
+		if os.IsNotExist(err) {
+			return nil, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to read CA certificate: %v", err)
+		}
+		return caCert, nil
+	}
+
+	caCert, cert, key, err := generateCerts(dnsNames, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("unable to create cert directory: %v", err)
+	}
+	files := map[string][]byte{caCertName: caCert, certName: cert, keyName: key}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			return nil, fmt.Errorf("unable to write %s: %v", name, err)
+		}
+	}
+	return caCert, nil
+}
+
+// generateCerts generates a self-signed CA and a serving certificate signed by
+// it for the DNS names. The certificates and the key of the serving
+// certificate are PEM encoded.
+func generateCerts(dnsNames []string, now time.Time) (caCert, cert, key []byte, err error) {
+	if len(dnsNames) == 0 {
+		return nil, nil, nil, fmt.Errorf("at least one DNS name is required")
+	}
+
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("unable to generate CA key: %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "faros-webhook-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(certValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("unable to create CA certificate: %v", err)
+	}
+
+	servingKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("unable to generate serving key: %v", err)
+	}
+	servingTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(certValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	servingDER, err := x509.CreateCertificate(rand.Reader, servingTemplate, caTemplate, &servingKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("unable to create serving certificate: %v", err)
+	}
+
+	caCert, err = encodePEM("CERTIFICATE", caDER)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	cert, err = encodePEM("CERTIFICATE", servingDER)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	key, err = encodePEM("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(servingKey))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return caCert, cert, key, nil
+}
+
+// encodePEM PEM encodes the DER bytes as a block of the given type
+func encodePEM(blockType string, der []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := pem.Encode(buf, &pem.Block{Type: blockType, Bytes: der}); err != nil {
+		return nil, fmt.Errorf("unable to encode %s: %v", blockType, err)
+	}
+	return buf.Bytes(), nil
+}
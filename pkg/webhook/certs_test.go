@@ -0,0 +1,115 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Certificates", func() {
+	var dir string
+	dnsNames := []string{"faros-webhook-service.faros-system.svc", "faros-webhook-service.faros-system.svc.cluster.local"}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "faros-webhook-certs")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	Context("when no certificate is provided", func() {
+		var caCert []byte
+
+		BeforeEach(func() {
+			var err error
+			caCert, err = ensureCerts(filepath.Join(dir, "serving-certs"), dnsNames)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("writes a serving certificate and key", func() {
+			_, err := tls.LoadX509KeyPair(filepath.Join(dir, "serving-certs", certName), filepath.Join(dir, "serving-certs", keyName))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns a CA trusting the serving certificate for the DNS names", func() {
+			pool := x509.NewCertPool()
+			Expect(pool.AppendCertsFromPEM(caCert)).To(BeTrue())
+
+			data, err := ioutil.ReadFile(filepath.Join(dir, "serving-certs", certName))
+			Expect(err).NotTo(HaveOccurred())
+			block, _ := pem.Decode(data)
+			Expect(block).NotTo(BeNil())
+			cert, err := x509.ParseCertificate(block.Bytes)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, name := range dnsNames {
+				_, err = cert.Verify(x509.VerifyOptions{DNSName: name, Roots: pool, CurrentTime: time.Now()})
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		It("keeps the generated certificate", func() {
+			again, err := ensureCerts(filepath.Join(dir, "serving-certs"), dnsNames)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(again).To(Equal(caCert))
+		})
+	})
+
+	Context("when a certificate is provided", func() {
+		BeforeEach(func() {
+			_, cert, key, err := generateCerts(dnsNames, time.Now())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ioutil.WriteFile(filepath.Join(dir, certName), cert, 0600)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, keyName), key, 0600)).To(Succeed())
+		})
+
+		It("doesn't return a CA without ca.crt", func() {
+			caCert, err := ensureCerts(dir, dnsNames)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(caCert).To(BeNil())
+		})
+
+		It("returns the provided CA", func() {
+			Expect(ioutil.WriteFile(filepath.Join(dir, caCertName), []byte("ca"), 0600)).To(Succeed())
+			caCert, err := ensureCerts(dir, dnsNames)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(caCert).To(Equal([]byte("ca")))
+		})
+
+		It("doesn't replace the certificate", func() {
+			before, err := ioutil.ReadFile(filepath.Join(dir, certName))
+			Expect(err).NotTo(HaveOccurred())
+			_, err = ensureCerts(dir, dnsNames)
+			Expect(err).NotTo(HaveOccurred())
+			after, err := ioutil.ReadFile(filepath.Join(dir, certName))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(after).To(Equal(before))
+		})
+	})
+})
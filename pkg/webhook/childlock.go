@@ -0,0 +1,235 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	gittrackobjectutils "github.com/pusher/faros/pkg/controller/gittrackobject/utils"
+	"github.com/pusher/faros/pkg/utils"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:groups=*,versions=*,resources=*,verbs=update;delete
+// +kubebuilder:webhook:name=validating.children.faros.pusher.com
+// +kubebuilder:webhook:path=/validate-children
+// +kubebuilder:webhook:type=validating,failure-policy=ignore
+
+// childLock rejects changes to children controlled by (Cluster)GitTrackObjects
+// that aren't made by Faros or a member of a break-glass group
+type childLock struct {
+	// client reads (Cluster)GitTrackObjects and GitTracks
+	client client.Client
+
+	// reader reads children which are not included in DELETE requests
+	reader client.Reader
+
+	// users are the usernames Faros authenticates as
+	users []string
+
+	// groups are the break-glass groups whose members may change children
+	groups []string
+}
+
+// Handle implements the admission.Handler interface
+func (l *childLock) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if l.isExempt(req.UserInfo.Username, req.UserInfo.Groups) {
+		return admission.Allowed("")
+	}
+
+	child, err := l.getChild(ctx, req)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if child == nil {
+		return admission.Allowed("")
+	}
+
+	gto, err := l.getController(ctx, child)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	// Children of deleted (Cluster)GitTrackObjects are left to the garbage
+	// collector
+	if gto == nil || gto.GetDeletionTimestamp() != nil {
+		return admission.Allowed("")
+	}
+	// Faros can't revert changes to children it is unable to parse, so there
+	// is nothing to protect
+	desired, err := utils.YAMLToUnstructured(gto.GetSpec().Data)
+	if err != nil {
+		return admission.Allowed("")
+	}
+
+	owner, err := gittrackobjectutils.GetOwner(l.client, gto)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	// Faros makes changes as the GitTrack's ServiceAccount when it has one
+	if owner != nil && owner.Spec.ServiceAccountName != "" &&
+		req.UserInfo.Username == utils.ServiceAccountUsername(owner.Namespace, owner.Spec.ServiceAccountName) {
+		return admission.Allowed("")
+	}
+
+	if req.Operation == admissionv1beta1.Update {
+		updated := &unstructured.Unstructured{}
+		if err := updated.UnmarshalJSON(req.Object.Raw); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		// Only fields set in the repository are locked, leaving metadata and
+		// fields managed by other controllers free to change
+		if !changesDesiredFields(desired, child, updated) {
+			return admission.Allowed("")
+		}
+	}
+	return admission.Denied(lockedMessage(req.Operation, child, gto, owner))
+}
+
+// isExempt returns whether the user is Faros or a member of a break-glass
+// group
+func (l *childLock) isExempt(username string, groups []string) bool {
+	for _, user := range l.users {
+		if username == user {
+			return true
+		}
+	}
+	for _, group := range groups {
+		for _, exempt := range l.groups {
+			if group == exempt {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getChild returns the object as it was before the request. The object being
+// deleted is read from the API as DELETE requests don't include it.
+func (l *childLock) getChild(ctx context.Context, req admission.Request) (*unstructured.Unstructured, error) {
+	child := &unstructured.Unstructured{}
+	if len(req.OldObject.Raw) > 0 {
+		if err := child.UnmarshalJSON(req.OldObject.Raw); err != nil {
+			return nil, fmt.Errorf("unable to decode old object: %v", err)
+		}
+		return child, nil
+	}
+
+	child.SetGroupVersionKind(schema.GroupVersionKind{Group: req.Kind.Group, Version: req.Kind.Version, Kind: req.Kind.Kind})
+	err := l.reader.Get(ctx, types.NamespacedName{Namespace: req.Namespace, Name: req.Name}, child)
+	if err != nil && errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to get %s %s: %v", req.Kind.Kind, req.Name, err)
+	}
+	return child, nil
+}
+
+// getController returns the (Cluster)GitTrackObject controlling the child, or
+// nil if it isn't controlled by one
+func (l *childLock) getController(ctx context.Context, child *unstructured.Unstructured) (farosv1alpha1.GitTrackObjectInterface, error) {
+	ref := metav1.GetControllerOf(child)
+	if ref == nil || !strings.HasPrefix(ref.APIVersion, farosv1alpha1.SchemeGroupVersion.Group+"/") {
+		return nil, nil
+	}
+
+	var gto farosv1alpha1.GitTrackObjectInterface
+	key := types.NamespacedName{Name: ref.Name}
+	switch ref.Kind {
+	case "GitTrackObject":
+		gto = &farosv1alpha1.GitTrackObject{}
+		key.Namespace = child.GetNamespace()
+	case "ClusterGitTrackObject":
+		gto = &farosv1alpha1.ClusterGitTrackObject{}
+	default:
+		return nil, nil
+	}
+
+	err := l.client.Get(ctx, key, gto)
+	if err != nil && errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to get %s %s: %v", ref.Kind, ref.Name, err)
+	}
+	if gto.GetUID() != ref.UID {
+		return nil, nil
+	}
+	return gto, nil
+}
+
+// changesDesiredFields returns whether the update changes any of the fields
+// set in the desired state of the child
+func changesDesiredFields(desired, old, updated *unstructured.Unstructured) bool {
+	return !reflect.DeepEqual(
+		pruneToDesired(old.Object, desired.Object),
+		pruneToDesired(updated.Object, desired.Object),
+	)
+}
+
+// pruneToDesired returns the parts of the value at paths present in the
+// desired value. Lists and scalars are compared as a whole.
+func pruneToDesired(value, desired interface{}) interface{} {
+	desiredMap, ok := desired.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	valueMap, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	pruned := make(map[string]interface{}, len(desiredMap))
+	for key, desiredValue := range desiredMap {
+		if v, ok := valueMap[key]; ok {
+			pruned[key] = pruneToDesired(v, desiredValue)
+		}
+	}
+	return pruned
+}
+
+// lockedMessage explains where a locked child should be changed instead
+func lockedMessage(operation admissionv1beta1.Operation, child *unstructured.Unstructured, gto farosv1alpha1.GitTrackObjectInterface, owner *farosv1alpha1.GitTrack) string {
+	verb := "updated"
+	if operation == admissionv1beta1.Delete {
+		verb = "deleted"
+	}
+	kind := metav1.GetControllerOf(child).Kind
+	msg := fmt.Sprintf("%s %s is managed by Faros through %s %s and may not be %s by hand", child.GetKind(), child.GetName(), kind, gto.GetNamespacedName(), verb)
+	if owner == nil {
+		return msg + fmt.Sprintf(", change the %s instead", kind)
+	}
+	if path := child.GetAnnotations()[utils.SourcePathAnnotation]; path != "" {
+		return msg + fmt.Sprintf(", change file %s in repository %s at reference %s (GitTrack %s/%s) instead",
+			path, owner.Spec.Repository, owner.Spec.Reference, owner.Namespace, owner.Name)
+	}
+	subPath := owner.Spec.SubPath
+	if subPath == "" {
+		subPath = "/"
+	}
+	return msg + fmt.Sprintf(", change it in repository %s at reference %s under path %s (GitTrack %s/%s) instead",
+		owner.Spec.Repository, owner.Spec.Reference, subPath, owner.Namespace, owner.Name)
+}
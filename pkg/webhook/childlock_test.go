@@ -0,0 +1,218 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	"github.com/pusher/faros/pkg/utils"
+	testutils "github.com/pusher/faros/test/utils"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("Child lock webhook", func() {
+	var c client.Client
+	var lock *childLock
+	var gt *farosv1alpha1.GitTrack
+	var gto *farosv1alpha1.GitTrackObject
+	var child *corev1.ConfigMap
+
+	const timeout = time.Second * 5
+	const farosUser = "system:serviceaccount:faros-system:default"
+
+	// newChildRequest returns an admission request for the operation on the
+	// child by the user
+	newChildRequest := func(operation admissionv1beta1.Operation, username string, old, updated *corev1.ConfigMap) admission.Request {
+		req := admission.Request{
+			AdmissionRequest: admissionv1beta1.AdmissionRequest{
+				Operation: operation,
+				Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+				Namespace: child.Namespace,
+				Name:      child.Name,
+				UserInfo:  authenticationv1.UserInfo{Username: username, Groups: []string{"system:authenticated"}},
+			},
+		}
+		if old != nil {
+			raw, err := json.Marshal(old)
+			Expect(err).NotTo(HaveOccurred())
+			req.OldObject = runtime.RawExtension{Raw: raw}
+		}
+		if updated != nil {
+			raw, err := json.Marshal(updated)
+			Expect(err).NotTo(HaveOccurred())
+			req.Object = runtime.RawExtension{Raw: raw}
+		}
+		return req
+	}
+
+	BeforeEach(func() {
+		var err error
+		c, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
+		Expect(err).NotTo(HaveOccurred())
+		lock = &childLock{
+			client: c,
+			reader: c,
+			users:  []string{farosUser},
+			groups: []string{"break-glass"},
+		}
+
+		gt = &farosv1alpha1.GitTrack{
+			ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
+			Spec: farosv1alpha1.GitTrackSpec{
+				Repository:         "git@github.com:example/example.git",
+				Reference:          "master",
+				SubPath:            "config",
+				ServiceAccountName: "deployer",
+			},
+		}
+		Expect(c.Create(context.TODO(), gt)).To(Succeed())
+
+		child = &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
+			Data:       map[string]string{"key": "value"},
+		}
+		gto = &farosv1alpha1.GitTrackObject{
+			ObjectMeta: metav1.ObjectMeta{Name: "configmap-example", Namespace: "default"},
+		}
+		Expect(testutils.SetGitTrackObjectInterfaceSpec(gto, child)).To(Succeed())
+		Expect(controllerutil.SetControllerReference(gt, gto, scheme.Scheme)).To(Succeed())
+		Expect(c.Create(context.TODO(), gto)).To(Succeed())
+
+		Expect(controllerutil.SetControllerReference(gto, child, scheme.Scheme)).To(Succeed())
+		Expect(c.Create(context.TODO(), child)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		testutils.DeleteAll(cfg, timeout,
+			&farosv1alpha1.GitTrackList{},
+			&farosv1alpha1.GitTrackObjectList{},
+			&corev1.ConfigMapList{},
+		)
+	})
+
+	Context("when a field set in the repository is updated", func() {
+		var updated *corev1.ConfigMap
+
+		BeforeEach(func() {
+			updated = child.DeepCopy()
+			updated.Data["key"] = "changed"
+		})
+
+		It("denies other users", func() {
+			resp := lock.Handle(context.TODO(), newChildRequest(admissionv1beta1.Update, "jane", child, updated))
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(string(resp.Result.Reason)).To(ContainSubstring("ConfigMap example is managed by Faros through GitTrackObject default/configmap-example"))
+			Expect(string(resp.Result.Reason)).To(ContainSubstring("repository git@github.com:example/example.git at reference master under path config (GitTrack default/example)"))
+		})
+
+		It("points other users at the file the child was produced from", func() {
+			child.Annotations = map[string]string{utils.SourcePathAnnotation: "config/configmap.yaml"}
+			Expect(c.Update(context.TODO(), child)).To(Succeed())
+			resp := lock.Handle(context.TODO(), newChildRequest(admissionv1beta1.Update, "jane", child, updated))
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(string(resp.Result.Reason)).To(ContainSubstring("change file config/configmap.yaml in repository git@github.com:example/example.git at reference master (GitTrack default/example)"))
+		})
+
+		It("allows Faros", func() {
+			resp := lock.Handle(context.TODO(), newChildRequest(admissionv1beta1.Update, farosUser, child, updated))
+			Expect(resp.Allowed).To(BeTrue())
+		})
+
+		It("allows the GitTrack's ServiceAccount", func() {
+			resp := lock.Handle(context.TODO(), newChildRequest(admissionv1beta1.Update, "system:serviceaccount:default:deployer", child, updated))
+			Expect(resp.Allowed).To(BeTrue())
+		})
+
+		It("allows members of a break-glass group", func() {
+			req := newChildRequest(admissionv1beta1.Update, "jane", child, updated)
+			req.UserInfo.Groups = append(req.UserInfo.Groups, "break-glass")
+			resp := lock.Handle(context.TODO(), req)
+			Expect(resp.Allowed).To(BeTrue())
+		})
+
+		It("allows children of a deleted GitTrackObject", func() {
+			Expect(c.Delete(context.TODO(), gto)).To(Succeed())
+			resp := lock.Handle(context.TODO(), newChildRequest(admissionv1beta1.Update, "jane", child, updated))
+			Expect(resp.Allowed).To(BeTrue())
+		})
+	})
+
+	It("allows updating fields not set in the repository", func() {
+		updated := child.DeepCopy()
+		updated.Annotations = map[string]string{"example.com/annotation": "value"}
+		updated.Data["other"] = "value"
+		resp := lock.Handle(context.TODO(), newChildRequest(admissionv1beta1.Update, "jane", child, updated))
+		Expect(resp.Allowed).To(BeTrue())
+	})
+
+	It("denies deleting the child", func() {
+		resp := lock.Handle(context.TODO(), newChildRequest(admissionv1beta1.Delete, "jane", nil, nil))
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(string(resp.Result.Reason)).To(ContainSubstring("may not be deleted by hand"))
+	})
+
+	It("allows changes to objects not managed by Faros", func() {
+		unmanaged := child.DeepCopy()
+		unmanaged.OwnerReferences = nil
+		updated := unmanaged.DeepCopy()
+		updated.Data["key"] = "changed"
+		resp := lock.Handle(context.TODO(), newChildRequest(admissionv1beta1.Update, "jane", unmanaged, updated))
+		Expect(resp.Allowed).To(BeTrue())
+	})
+
+	It("points to the GitTrackObject when it has no GitTrack", func() {
+		gto.OwnerReferences = nil
+		Expect(c.Update(context.TODO(), gto)).To(Succeed())
+		resp := lock.Handle(context.TODO(), newChildRequest(admissionv1beta1.Delete, "jane", nil, nil))
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(string(resp.Result.Reason)).To(HaveSuffix("change the GitTrackObject instead"))
+	})
+})
+
+var _ = Describe("pruneToDesired", func() {
+	It("keeps only the fields present in the desired value", func() {
+		value := map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "example", "resourceVersion": "1"},
+			"spec": map[string]interface{}{
+				"replicas":   int64(3),
+				"containers": []interface{}{"a", "b"},
+			},
+		}
+		desired := map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "example"},
+			"spec":     map[string]interface{}{"containers": []interface{}{"a"}},
+		}
+		Expect(pruneToDesired(value, desired)).To(Equal(map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "example"},
+			"spec":     map[string]interface{}{"containers": []interface{}{"a", "b"}},
+		}))
+	})
+})
@@ -0,0 +1,190 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pusher/faros/pkg/apis"
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	rlogr "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+// farosScheme holds every version of the Faros API
+var farosScheme = runtime.NewScheme()
+
+func init() {
+	if err := apis.AddToScheme(farosScheme); err != nil {
+		panic(err)
+	}
+}
+
+// convertible is implemented by the versions of the Faros API that are
+// converted to and from the v1alpha1 storage version
+type convertible interface {
+	runtime.Object
+	ConvertTo(dst runtime.Object) error
+	ConvertFrom(src runtime.Object) error
+}
+
+// decode decodes a raw object of a kind of the Faros API
+func decode(gvk schema.GroupVersionKind, raw []byte) (runtime.Object, error) {
+	obj, err := farosScheme.New(gvk)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, obj); err != nil {
+		return nil, fmt.Errorf("unable to decode %s: %v", gvk.Kind, err)
+	}
+	return obj, nil
+}
+
+// decodeHub decodes the object of an admission request in any version of the
+// Faros API and converts it to v1alpha1
+func decodeHub(kind metav1.GroupVersionKind, raw runtime.RawExtension) (runtime.Object, error) {
+	obj, err := decode(schema.GroupVersionKind(kind), raw.Raw)
+	if err != nil {
+		return nil, err
+	}
+	return convert(obj, farosv1alpha1.SchemeGroupVersion.Version)
+}
+
+// convert converts the object to the given version of the Faros API through
+// the v1alpha1 storage version
+func convert(obj runtime.Object, version string) (runtime.Object, error) {
+	gvks, _, err := farosScheme.ObjectKinds(obj)
+	if err != nil {
+		return nil, err
+	}
+	from := gvks[0]
+	to := schema.GroupVersionKind{Group: from.Group, Version: version, Kind: from.Kind}
+	if from == to {
+		return obj, nil
+	}
+
+	hub := obj
+	if from.Version != farosv1alpha1.SchemeGroupVersion.Version {
+		c, ok := obj.(convertible)
+		if !ok {
+			return nil, fmt.Errorf("%s is not convertible", from)
+		}
+		hub, err = farosScheme.New(farosv1alpha1.SchemeGroupVersion.WithKind(from.Kind))
+		if err != nil {
+			return nil, err
+		}
+		if err := c.ConvertTo(hub); err != nil {
+			return nil, err
+		}
+	}
+
+	out := hub
+	if to.Version != farosv1alpha1.SchemeGroupVersion.Version {
+		obj, err := farosScheme.New(to)
+		if err != nil {
+			return nil, err
+		}
+		c, ok := obj.(convertible)
+		if !ok {
+			return nil, fmt.Errorf("%s is not convertible", to)
+		}
+		if err := c.ConvertFrom(hub); err != nil {
+			return nil, err
+		}
+		out = c
+	}
+	out.GetObjectKind().SetGroupVersionKind(to)
+	return out, nil
+}
+
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;update
+
+// converter serves the conversion webhook of the Faros CRDs, converting
+// objects between the versions of the Faros API
+type converter struct{}
+
+// ServeHTTP implements the http.Handler interface
+func (c *converter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	review := &apiextensionsv1beta1.ConversionReview{}
+	if err := json.NewDecoder(r.Body).Decode(review); err != nil {
+		http.Error(w, fmt.Sprintf("unable to decode ConversionReview: %v", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "ConversionReview has no request", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = convertReview(review.Request)
+	review.Request = nil
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		rlogr.Log.WithName("webhook/converter").Error(err, "unable to write ConversionReview")
+	}
+}
+
+// convertReview converts the objects of a ConversionReview to the desired
+// version
+func convertReview(req *apiextensionsv1beta1.ConversionRequest) *apiextensionsv1beta1.ConversionResponse {
+	resp := &apiextensionsv1beta1.ConversionResponse{UID: req.UID}
+	objects, err := convertObjects(req.Objects, req.DesiredAPIVersion)
+	if err != nil {
+		resp.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+		return resp
+	}
+	resp.ConvertedObjects = objects
+	resp.Result = metav1.Status{Status: metav1.StatusSuccess}
+	return resp
+}
+
+// convertObjects converts each of the raw objects to the desired API version
+func convertObjects(objects []runtime.RawExtension, desiredAPIVersion string) ([]runtime.RawExtension, error) {
+	gv, err := schema.ParseGroupVersion(desiredAPIVersion)
+	if err != nil {
+		return nil, err
+	}
+	if gv.Group != farosv1alpha1.SchemeGroupVersion.Group {
+		return nil, fmt.Errorf("unable to convert to %s, not a version of %s", desiredAPIVersion, farosv1alpha1.SchemeGroupVersion.Group)
+	}
+
+	converted := []runtime.RawExtension{}
+	for _, raw := range objects {
+		typeMeta := &metav1.TypeMeta{}
+		if err := json.Unmarshal(raw.Raw, typeMeta); err != nil {
+			return nil, fmt.Errorf("unable to decode object: %v", err)
+		}
+		obj, err := decode(typeMeta.GroupVersionKind(), raw.Raw)
+		if err != nil {
+			return nil, err
+		}
+		out, err := convert(obj, gv.Version)
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert %s to %s: %v", typeMeta.Kind, desiredAPIVersion, err)
+		}
+		data, err := json.Marshal(out)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, runtime.RawExtension{Raw: data})
+	}
+	return converted, nil
+}
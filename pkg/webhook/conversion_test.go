@@ -0,0 +1,203 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	farosv1beta1 "github.com/pusher/faros/pkg/apis/faros/v1beta1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("Conversion webhook", func() {
+	var gt *farosv1alpha1.GitTrack
+	var raw []byte
+
+	BeforeEach(func() {
+		gt = &farosv1alpha1.GitTrack{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "faros.pusher.com/v1alpha1", Kind: "GitTrack"},
+			ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
+			Spec: farosv1alpha1.GitTrackSpec{
+				Repository: "git@github.com:example/example.git",
+				Reference:  "master",
+				DeployKey:  farosv1alpha1.GitTrackDeployKey{SecretName: "deploy-key", Key: "id_rsa", Type: farosv1alpha1.GitCredentialTypeSSH},
+			},
+			Status: farosv1alpha1.GitTrackStatus{ObjectsApplied: 3},
+		}
+		var err error
+		raw, err = json.Marshal(gt)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Context("convertObjects", func() {
+		It("converts v1alpha1 objects to v1beta1", func() {
+			converted, err := convertObjects([]runtime.RawExtension{{Raw: raw}}, "faros.pusher.com/v1beta1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(converted).To(HaveLen(1))
+
+			out := &farosv1beta1.GitTrack{}
+			Expect(json.Unmarshal(converted[0].Raw, out)).To(Succeed())
+			Expect(out.APIVersion).To(Equal("faros.pusher.com/v1beta1"))
+			Expect(out.Kind).To(Equal("GitTrack"))
+			Expect(out.Name).To(Equal("example"))
+			Expect(out.Spec.Source.Repository).To(Equal("git@github.com:example/example.git"))
+			Expect(out.Spec.Source.Credentials).To(Equal(&farosv1beta1.GitTrackCredentials{SecretName: "deploy-key", Key: "id_rsa", Type: farosv1beta1.GitCredentialTypeSSH}))
+			Expect(out.Status.ObjectsApplied).To(BeEquivalentTo(3))
+		})
+
+		It("round trips objects through v1beta1", func() {
+			converted, err := convertObjects([]runtime.RawExtension{{Raw: raw}}, "faros.pusher.com/v1beta1")
+			Expect(err).NotTo(HaveOccurred())
+			converted, err = convertObjects(converted, "faros.pusher.com/v1alpha1")
+			Expect(err).NotTo(HaveOccurred())
+
+			out := &farosv1alpha1.GitTrack{}
+			Expect(json.Unmarshal(converted[0].Raw, out)).To(Succeed())
+			Expect(out).To(Equal(gt))
+		})
+
+		It("embeds the data of GitTrackObjects", func() {
+			gto := &farosv1alpha1.GitTrackObject{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "faros.pusher.com/v1alpha1", Kind: "GitTrackObject"},
+				ObjectMeta: metav1.ObjectMeta{Name: "configmap-example", Namespace: "default"},
+				Spec: farosv1alpha1.GitTrackObjectSpec{
+					Name: "example",
+					Kind: "ConfigMap",
+					Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: example\n"),
+				},
+			}
+			gtoRaw, err := json.Marshal(gto)
+			Expect(err).NotTo(HaveOccurred())
+
+			converted, err := convertObjects([]runtime.RawExtension{{Raw: gtoRaw}}, "faros.pusher.com/v1beta1")
+			Expect(err).NotTo(HaveOccurred())
+			out := map[string]interface{}{}
+			Expect(json.Unmarshal(converted[0].Raw, &out)).To(Succeed())
+			Expect(out["spec"]).To(HaveKeyWithValue("data", map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "example"},
+			}))
+		})
+
+		It("leaves objects of the desired version unchanged", func() {
+			converted, err := convertObjects([]runtime.RawExtension{{Raw: raw}}, "faros.pusher.com/v1alpha1")
+			Expect(err).NotTo(HaveOccurred())
+			out := &farosv1alpha1.GitTrack{}
+			Expect(json.Unmarshal(converted[0].Raw, out)).To(Succeed())
+			Expect(out).To(Equal(gt))
+		})
+
+		It("rejects unknown versions", func() {
+			_, err := convertObjects([]runtime.RawExtension{{Raw: raw}}, "faros.pusher.com/v2")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("rejects other groups", func() {
+			_, err := convertObjects([]runtime.RawExtension{{Raw: raw}}, "apps/v1")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("converter", func() {
+		// review sends the ConversionReview to the converter and returns its
+		// response
+		review := func(req *apiextensionsv1beta1.ConversionRequest) *apiextensionsv1beta1.ConversionResponse {
+			body, err := json.Marshal(&apiextensionsv1beta1.ConversionReview{Request: req})
+			Expect(err).NotTo(HaveOccurred())
+			w := httptest.NewRecorder()
+			(&converter{}).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/convert", bytes.NewReader(body)))
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			out := &apiextensionsv1beta1.ConversionReview{}
+			Expect(json.Unmarshal(w.Body.Bytes(), out)).To(Succeed())
+			Expect(out.Response).NotTo(BeNil())
+			return out.Response
+		}
+
+		It("responds with the converted objects", func() {
+			resp := review(&apiextensionsv1beta1.ConversionRequest{
+				UID:               "uid",
+				DesiredAPIVersion: "faros.pusher.com/v1beta1",
+				Objects:           []runtime.RawExtension{{Raw: raw}},
+			})
+			Expect(resp.UID).To(BeEquivalentTo("uid"))
+			Expect(resp.Result.Status).To(Equal(metav1.StatusSuccess))
+			Expect(resp.ConvertedObjects).To(HaveLen(1))
+		})
+
+		It("reports failures", func() {
+			resp := review(&apiextensionsv1beta1.ConversionRequest{
+				UID:               "uid",
+				DesiredAPIVersion: "faros.pusher.com/v2",
+				Objects:           []runtime.RawExtension{{Raw: raw}},
+			})
+			Expect(resp.UID).To(BeEquivalentTo("uid"))
+			Expect(resp.Result.Status).To(Equal(metav1.StatusFailure))
+			Expect(resp.ConvertedObjects).To(BeEmpty())
+		})
+
+		It("rejects reviews without a request", func() {
+			w := httptest.NewRecorder()
+			(&converter{}).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/convert", bytes.NewReader([]byte("{}"))))
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("admission webhooks", func() {
+		var beta *farosv1beta1.GitTrack
+
+		BeforeEach(func() {
+			beta = &farosv1beta1.GitTrack{}
+			Expect(beta.ConvertFrom(gt)).To(Succeed())
+			beta.TypeMeta = metav1.TypeMeta{APIVersion: "faros.pusher.com/v1beta1", Kind: "GitTrack"}
+		})
+
+		// newBetaRequest returns an admission request for the v1beta1 GitTrack
+		newBetaRequest := func() admission.Request {
+			req := newRequest(admissionv1beta1.Create, "GitTrack", beta)
+			req.Kind.Version = "v1beta1"
+			return req
+		}
+
+		It("patches v1beta1 GitTracks in v1beta1", func() {
+			beta.Spec.Source.Credentials.Type = ""
+			resp := (&gitTrackDefaulter{}).Handle(context.TODO(), newBetaRequest())
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(resp.Patches).To(HaveLen(1))
+			Expect(resp.Patches[0].Path).To(Equal("/spec/source/credentials/type"))
+		})
+
+		It("validates v1beta1 GitTracks", func() {
+			beta.Spec.Source.Reference = ""
+			resp := (&gitTrackValidator{}).Handle(context.TODO(), newBetaRequest())
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(string(resp.Result.Reason)).To(ContainSubstring("reference must be set"))
+		})
+	})
+})
@@ -0,0 +1,175 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	farosclient "github.com/pusher/faros/pkg/utils/client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// subPathPattern matches the characters allowed in the subPath of a GitTrack
+var subPathPattern = regexp.MustCompile(`^[a-zA-Z0-9/\-.]*$`)
+
+// +kubebuilder:webhook:groups=faros.pusher.com,versions=v1alpha1;v1beta1,resources=gittracks,verbs=create;update
+// +kubebuilder:webhook:name=mutating.gittracks.faros.pusher.com
+// +kubebuilder:webhook:path=/mutate-gittracks
+// +kubebuilder:webhook:type=mutating,failure-policy=fail
+
+// gitTrackDefaulter sets the default values of GitTracks
+type gitTrackDefaulter struct{}
+
+// Handle implements the admission.Handler interface
+func (d *gitTrackDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	gt, err := decodeGitTrack(req.Kind, req.Object)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	defaultGitTrack(gt)
+
+	// Patch the object in the version it was submitted in
+	obj, err := convert(gt, req.Kind.Version)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	marshaled, err := json.Marshal(obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// decodeGitTrack decodes a GitTrack of any version and converts it to v1alpha1
+func decodeGitTrack(kind metav1.GroupVersionKind, raw runtime.RawExtension) (*farosv1alpha1.GitTrack, error) {
+	obj, err := decodeHub(kind, raw)
+	if err != nil {
+		return nil, err
+	}
+	gt, ok := obj.(*farosv1alpha1.GitTrack)
+	if !ok {
+		return nil, fmt.Errorf("unexpected kind %s", kind.Kind)
+	}
+	return gt, nil
+}
+
+// defaultGitTrack sets the default values of the GitTrack
+func defaultGitTrack(gt *farosv1alpha1.GitTrack) {
+	if gt.Spec.DeployKey.SecretName != "" && gt.Spec.DeployKey.Type == "" {
+		gt.Spec.DeployKey.Type = farosv1alpha1.GitCredentialTypeSSH
+	}
+}
+
+// +kubebuilder:webhook:groups=faros.pusher.com,versions=v1alpha1;v1beta1,resources=gittracks,verbs=create;update
+// +kubebuilder:webhook:name=validating.gittracks.faros.pusher.com
+// +kubebuilder:webhook:path=/validate-gittracks
+// +kubebuilder:webhook:type=validating,failure-policy=fail
+
+// gitTrackValidator rejects GitTracks with invalid specs
+type gitTrackValidator struct{}
+
+// Handle implements the admission.Handler interface
+func (v *gitTrackValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	gt, err := decodeGitTrack(req.Kind, req.Object)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if errs := validateGitTrack(gt); len(errs) > 0 {
+		return admission.Denied(errs.ToAggregate().Error())
+	}
+	return admission.Allowed("")
+}
+
+// validateGitTrack returns the errors in the spec of the GitTrack
+func validateGitTrack(gt *farosv1alpha1.GitTrack) field.ErrorList {
+	errs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+
+	if strings.TrimSpace(gt.Spec.Repository) == "" {
+		errs = append(errs, field.Required(specPath.Child("repository"), "repository must be set"))
+	}
+	if strings.TrimSpace(gt.Spec.Reference) == "" {
+		errs = append(errs, field.Required(specPath.Child("reference"), "reference must be set"))
+	}
+
+	subPathPath := specPath.Child("subPath")
+	if !subPathPattern.MatchString(gt.Spec.SubPath) {
+		errs = append(errs, field.Invalid(subPathPath, gt.Spec.SubPath, "subPath may only contain letters, digits, '/', '-' and '.'"))
+	} else if strings.HasPrefix(path.Clean("/"+gt.Spec.SubPath), "/..") {
+		errs = append(errs, field.Invalid(subPathPath, gt.Spec.SubPath, "subPath must be within the repository"))
+	}
+
+	errs = append(errs, validateDeployKey(gt.Spec.DeployKey, specPath.Child("deployKey"))...)
+
+	if gt.Spec.Cluster != nil && gt.Spec.Cluster.SecretName == "" {
+		errs = append(errs, field.Required(specPath.Child("cluster", "secretName"), "secretName must be set to deploy to a remote cluster"))
+	}
+
+	if gt.Spec.ServiceAccountName != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(gt.Spec.ServiceAccountName) {
+			errs = append(errs, field.Invalid(specPath.Child("serviceAccountName"), gt.Spec.ServiceAccountName, msg))
+		}
+	}
+
+	for i, rule := range gt.Spec.IgnoreFields {
+		rulePath := specPath.Child("ignoreFields").Index(i)
+		if rule.Kind == "" {
+			errs = append(errs, field.Required(rulePath.Child("kind"), "kind must be set"))
+		}
+		for j, p := range rule.Fields {
+			if _, err := farosclient.ParseFieldPath(p); err != nil {
+				errs = append(errs, field.Invalid(rulePath.Child("fields").Index(j), p, err.Error()))
+			}
+		}
+	}
+	return errs
+}
+
+// validateDeployKey returns the errors in the deploy key of a GitTrack
+func validateDeployKey(deployKey farosv1alpha1.GitTrackDeployKey, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if deployKey == (farosv1alpha1.GitTrackDeployKey{}) {
+		return errs
+	}
+
+	if deployKey.SecretName == "" {
+		errs = append(errs, field.Required(fldPath.Child("secretName"), "secretName must be set when using a deploy key"))
+	}
+	if deployKey.Key == "" {
+		errs = append(errs, field.Required(fldPath.Child("key"), "key must be set when using a deploy key"))
+	}
+
+	switch deployKey.Type {
+	case "", farosv1alpha1.GitCredentialTypeSSH, farosv1alpha1.GitCredentialTypeHTTPBasicAuth:
+	default:
+		errs = append(errs, field.NotSupported(fldPath.Child("type"), deployKey.Type, []string{farosv1alpha1.GitCredentialTypeSSH, farosv1alpha1.GitCredentialTypeHTTPBasicAuth}))
+	}
+	return errs
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// newRequest returns an admission request for the operation on the object
+func newRequest(operation admissionv1beta1.Operation, kind string, obj interface{}) admission.Request {
+	raw, err := json.Marshal(obj)
+	Expect(err).NotTo(HaveOccurred())
+	return admission.Request{
+		AdmissionRequest: admissionv1beta1.AdmissionRequest{
+			Operation: operation,
+			Kind:      metav1.GroupVersionKind{Group: "faros.pusher.com", Version: "v1alpha1", Kind: kind},
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+}
+
+var _ = Describe("GitTrack webhooks", func() {
+	var gt *farosv1alpha1.GitTrack
+
+	BeforeEach(func() {
+		gt = &farosv1alpha1.GitTrack{
+			ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
+			Spec: farosv1alpha1.GitTrackSpec{
+				Repository: "git@github.com:example/example.git",
+				Reference:  "master",
+			},
+		}
+	})
+
+	Context("defaultGitTrack", func() {
+		It("defaults the type of deploy keys to SSH", func() {
+			gt.Spec.DeployKey = farosv1alpha1.GitTrackDeployKey{SecretName: "deploy-key", Key: "id_rsa"}
+			defaultGitTrack(gt)
+			Expect(gt.Spec.DeployKey.Type).To(BeEquivalentTo(farosv1alpha1.GitCredentialTypeSSH))
+		})
+
+		It("keeps the type of deploy keys", func() {
+			gt.Spec.DeployKey = farosv1alpha1.GitTrackDeployKey{SecretName: "credentials", Key: "basic", Type: farosv1alpha1.GitCredentialTypeHTTPBasicAuth}
+			defaultGitTrack(gt)
+			Expect(gt.Spec.DeployKey.Type).To(BeEquivalentTo(farosv1alpha1.GitCredentialTypeHTTPBasicAuth))
+		})
+
+		It("doesn't set a type without a deploy key", func() {
+			defaultGitTrack(gt)
+			Expect(gt.Spec.DeployKey.Type).To(BeEmpty())
+		})
+	})
+
+	Context("gitTrackDefaulter", func() {
+		It("patches the type of deploy keys", func() {
+			gt.Spec.DeployKey = farosv1alpha1.GitTrackDeployKey{SecretName: "deploy-key", Key: "id_rsa"}
+			resp := (&gitTrackDefaulter{}).Handle(context.TODO(), newRequest(admissionv1beta1.Create, "GitTrack", gt))
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(resp.Patches).To(HaveLen(1))
+			Expect(resp.Patches[0].Path).To(Equal("/spec/deployKey/type"))
+			Expect(resp.Patches[0].Value).To(Equal("SSH"))
+		})
+
+		It("doesn't patch GitTracks without a deploy key", func() {
+			resp := (&gitTrackDefaulter{}).Handle(context.TODO(), newRequest(admissionv1beta1.Create, "GitTrack", gt))
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(resp.Patches).To(BeEmpty())
+		})
+	})
+
+	Context("validateGitTrack", func() {
+		It("accepts a valid GitTrack", func() {
+			gt.Spec.SubPath = "deploy/production"
+			gt.Spec.DeployKey = farosv1alpha1.GitTrackDeployKey{SecretName: "deploy-key", Key: "id_rsa", Type: farosv1alpha1.GitCredentialTypeSSH}
+			Expect(validateGitTrack(gt)).To(BeEmpty())
+		})
+
+		It("rejects an empty repository", func() {
+			gt.Spec.Repository = ""
+			errs := validateGitTrack(gt)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.repository"))
+		})
+
+		It("rejects an empty reference", func() {
+			gt.Spec.Reference = " "
+			errs := validateGitTrack(gt)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.reference"))
+		})
+
+		It("rejects a half-filled deploy key", func() {
+			gt.Spec.DeployKey = farosv1alpha1.GitTrackDeployKey{SecretName: "deploy-key"}
+			errs := validateGitTrack(gt)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.deployKey.key"))
+		})
+
+		It("rejects an unknown credential type", func() {
+			gt.Spec.DeployKey = farosv1alpha1.GitTrackDeployKey{SecretName: "deploy-key", Key: "id_rsa", Type: "Token"}
+			errs := validateGitTrack(gt)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.deployKey.type"))
+		})
+
+		It("rejects a subPath with invalid characters", func() {
+			gt.Spec.SubPath = "deploy/*"
+			errs := validateGitTrack(gt)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.subPath"))
+		})
+
+		It("rejects a subPath outside of the repository", func() {
+			gt.Spec.SubPath = "deploy/../../etc"
+			errs := validateGitTrack(gt)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.subPath"))
+		})
+
+		It("rejects a cluster without a Secret", func() {
+			gt.Spec.Cluster = &farosv1alpha1.GitTrackCluster{}
+			errs := validateGitTrack(gt)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.cluster.secretName"))
+		})
+
+		It("rejects an invalid ServiceAccount name", func() {
+			gt.Spec.ServiceAccountName = "Deployer"
+			errs := validateGitTrack(gt)
+			Expect(errs).NotTo(BeEmpty())
+			Expect(errs[0].Field).To(Equal("spec.serviceAccountName"))
+		})
+
+		It("rejects invalid ignored fields", func() {
+			gt.Spec.IgnoreFields = []farosv1alpha1.GitTrackIgnoreRule{{Kind: "Deployment", Group: "apps", Fields: []string{"spec.replicas"}}}
+			errs := validateGitTrack(gt)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.ignoreFields[0].fields[0]"))
+		})
+	})
+
+	Context("gitTrackValidator", func() {
+		It("allows a valid GitTrack", func() {
+			resp := (&gitTrackValidator{}).Handle(context.TODO(), newRequest(admissionv1beta1.Create, "GitTrack", gt))
+			Expect(resp.Allowed).To(BeTrue())
+		})
+
+		It("denies an invalid GitTrack", func() {
+			gt.Spec.Repository = ""
+			resp := (&gitTrackValidator{}).Handle(context.TODO(), newRequest(admissionv1beta1.Create, "GitTrack", gt))
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(string(resp.Result.Reason)).To(ContainSubstring("spec.repository"))
+		})
+	})
+})
@@ -0,0 +1,130 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	"github.com/pusher/faros/pkg/utils"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:groups=faros.pusher.com,versions=v1alpha1;v1beta1,resources=gittrackobjects;clustergittrackobjects,verbs=create;update
+// +kubebuilder:webhook:name=validating.gittrackobjects.faros.pusher.com
+// +kubebuilder:webhook:path=/validate-gittrackobjects
+// +kubebuilder:webhook:type=validating,failure-policy=fail
+
+// gitTrackObjectValidator rejects (Cluster)GitTrackObjects with invalid specs,
+// and creations and changes to their specs not made by Faros
+type gitTrackObjectValidator struct {
+	// users are the usernames Faros authenticates as
+	users []string
+}
+
+// Handle implements the admission.Handler interface
+func (v *gitTrackObjectValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	gto, err := decodeGitTrackObject(req.Kind, req.Object)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if errs := validateGitTrackObject(gto); len(errs) > 0 {
+		return admission.Denied(errs.ToAggregate().Error())
+	}
+
+	// Faros applies the children of (Cluster)GitTrackObjects with its own
+	// permissions, so only Faros may create them from a GitTrack's repository
+	if req.Operation == admissionv1beta1.Create && !v.isFaros(req.UserInfo.Username) {
+		return admission.Denied(fmt.Sprintf("a %s may only be created by Faros, add the child to a GitTrack's repository instead", req.Kind.Kind))
+	}
+
+	// The spec is owned by the GitTrack, so changes made by anyone else would
+	// be reverted on the next sync. Metadata and status may still be changed,
+	// for example by the garbage collector.
+	if req.Operation == admissionv1beta1.Update && !v.isFaros(req.UserInfo.Username) {
+		old, err := decodeGitTrackObject(req.Kind, req.OldObject)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if !reflect.DeepEqual(old.GetSpec(), gto.GetSpec()) {
+			return admission.Denied(fmt.Sprintf("the spec of a %s may only be changed by Faros, change the GitTrack's repository instead", req.Kind.Kind))
+		}
+	}
+	return admission.Allowed("")
+}
+
+// isFaros returns whether the username is one Faros authenticates as
+func (v *gitTrackObjectValidator) isFaros(username string) bool {
+	for _, user := range v.users {
+		if username == user {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeGitTrackObject decodes a GitTrackObject or ClusterGitTrackObject of any
+// version depending on the kind of the request and converts it to v1alpha1
+func decodeGitTrackObject(kind metav1.GroupVersionKind, raw runtime.RawExtension) (farosv1alpha1.GitTrackObjectInterface, error) {
+	obj, err := decodeHub(kind, raw)
+	if err != nil {
+		return nil, err
+	}
+	gto, ok := obj.(farosv1alpha1.GitTrackObjectInterface)
+	if !ok {
+		return nil, fmt.Errorf("unexpected kind %s", kind.Kind)
+	}
+	return gto, nil
+}
+
+// validateGitTrackObject returns the errors in the spec of the
+// (Cluster)GitTrackObject
+func validateGitTrackObject(gto farosv1alpha1.GitTrackObjectInterface) field.ErrorList {
+	errs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+	spec := gto.GetSpec()
+
+	if spec.Name == "" {
+		errs = append(errs, field.Required(specPath.Child("name"), "name must be set"))
+	}
+	if spec.Kind == "" {
+		errs = append(errs, field.Required(specPath.Child("kind"), "kind must be set"))
+	}
+
+	dataPath := specPath.Child("data")
+	child, err := utils.YAMLToUnstructured(spec.Data)
+	if err != nil {
+		return append(errs, field.Invalid(dataPath, "", fmt.Sprintf("unable to unmarshal data: %v", err)))
+	}
+	if child.GetName() == "" {
+		errs = append(errs, field.Invalid(dataPath, "", "child must have a name"))
+	} else if spec.Name != "" && child.GetName() != spec.Name {
+		errs = append(errs, field.Invalid(dataPath, child.GetName(), fmt.Sprintf("name of child doesn't match spec.name %s", spec.Name)))
+	}
+	if spec.Kind != "" && child.GetKind() != spec.Kind {
+		errs = append(errs, field.Invalid(dataPath, child.GetKind(), fmt.Sprintf("kind of child doesn't match spec.kind %s", spec.Kind)))
+	}
+	return errs
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
+	testutils "github.com/pusher/faros/test/utils"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("GitTrackObject webhooks", func() {
+	const farosUser = "system:serviceaccount:faros-system:default"
+
+	var gto *farosv1alpha1.GitTrackObject
+	var child *appsv1.Deployment
+	var validator *gitTrackObjectValidator
+
+	BeforeEach(func() {
+		gto = testutils.ExampleGitTrackObject.DeepCopy()
+		child = testutils.ExampleDeployment.DeepCopy()
+		Expect(testutils.SetGitTrackObjectInterfaceSpec(gto, child)).To(Succeed())
+		validator = &gitTrackObjectValidator{users: []string{farosUser}}
+	})
+
+	Context("validateGitTrackObject", func() {
+		It("accepts a valid GitTrackObject", func() {
+			Expect(validateGitTrackObject(gto)).To(BeEmpty())
+		})
+
+		It("accepts a valid ClusterGitTrackObject", func() {
+			cgto := testutils.ExampleClusterGitTrackObject.DeepCopy()
+			Expect(testutils.SetGitTrackObjectInterfaceSpec(cgto, testutils.ExampleClusterRoleBinding.DeepCopy())).To(Succeed())
+			Expect(validateGitTrackObject(cgto)).To(BeEmpty())
+		})
+
+		It("rejects data that can't be unmarshalled", func() {
+			gto.Spec.Data = []byte("{")
+			errs := validateGitTrackObject(gto)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.data"))
+		})
+
+		It("rejects a child with a different name", func() {
+			gto.Spec.Name = "other"
+			errs := validateGitTrackObject(gto)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.data"))
+		})
+
+		It("rejects a child with a different kind", func() {
+			gto.Spec.Kind = "StatefulSet"
+			errs := validateGitTrackObject(gto)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.data"))
+		})
+
+		It("rejects an empty name and kind", func() {
+			gto.Spec.Name = ""
+			gto.Spec.Kind = ""
+			errs := validateGitTrackObject(gto)
+			Expect(errs).To(HaveLen(2))
+			Expect(errs[0].Field).To(Equal("spec.name"))
+			Expect(errs[1].Field).To(Equal("spec.kind"))
+		})
+	})
+
+	Context("gitTrackObjectValidator", func() {
+		var req admission.Request
+		var updated *farosv1alpha1.GitTrackObject
+
+		BeforeEach(func() {
+			updated = gto.DeepCopy()
+			updatedChild := child.DeepCopy()
+			replicas := int32(5)
+			updatedChild.Spec.Replicas = &replicas
+			Expect(testutils.SetGitTrackObjectInterfaceSpec(updated, updatedChild)).To(Succeed())
+
+			req = newRequest(admissionv1beta1.Update, "GitTrackObject", updated)
+			old, err := json.Marshal(gto)
+			Expect(err).NotTo(HaveOccurred())
+			req.OldObject = runtime.RawExtension{Raw: old}
+		})
+
+		It("allows Faros to create a valid GitTrackObject", func() {
+			req := newRequest(admissionv1beta1.Create, "GitTrackObject", gto)
+			req.UserInfo = authenticationv1.UserInfo{Username: farosUser}
+			resp := validator.Handle(context.TODO(), req)
+			Expect(resp.Allowed).To(BeTrue())
+		})
+
+		It("denies creating an invalid GitTrackObject", func() {
+			gto.Spec.Name = "other"
+			req := newRequest(admissionv1beta1.Create, "GitTrackObject", gto)
+			req.UserInfo = authenticationv1.UserInfo{Username: farosUser}
+			resp := validator.Handle(context.TODO(), req)
+			Expect(resp.Allowed).To(BeFalse())
+		})
+
+		It("denies other users creating a GitTrackObject", func() {
+			req := newRequest(admissionv1beta1.Create, "GitTrackObject", gto)
+			req.UserInfo = authenticationv1.UserInfo{Username: "jane"}
+			resp := validator.Handle(context.TODO(), req)
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(string(resp.Result.Reason)).To(ContainSubstring("may only be created by Faros"))
+		})
+
+		It("denies other users creating a ClusterGitTrackObject", func() {
+			cgto := testutils.ExampleClusterGitTrackObject.DeepCopy()
+			Expect(testutils.SetGitTrackObjectInterfaceSpec(cgto, testutils.ExampleClusterRoleBinding.DeepCopy())).To(Succeed())
+			req := newRequest(admissionv1beta1.Create, "ClusterGitTrackObject", cgto)
+			req.UserInfo = authenticationv1.UserInfo{Username: "jane"}
+			resp := validator.Handle(context.TODO(), req)
+			Expect(resp.Allowed).To(BeFalse())
+		})
+
+		It("allows Faros to change the spec", func() {
+			req.UserInfo = authenticationv1.UserInfo{Username: farosUser}
+			resp := validator.Handle(context.TODO(), req)
+			Expect(resp.Allowed).To(BeTrue())
+		})
+
+		It("denies other users changing the spec", func() {
+			req.UserInfo = authenticationv1.UserInfo{Username: "jane"}
+			resp := validator.Handle(context.TODO(), req)
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(string(resp.Result.Reason)).To(ContainSubstring("may only be changed by Faros"))
+		})
+
+		It("allows other users changing the metadata", func() {
+			updated = gto.DeepCopy()
+			updated.SetFinalizers(nil)
+			updated.SetAnnotations(map[string]string{"example": "annotation"})
+			old := req.OldObject
+			req = newRequest(admissionv1beta1.Update, "GitTrackObject", updated)
+			req.OldObject = old
+			req.UserInfo = authenticationv1.UserInfo{Username: "system:serviceaccount:kube-system:generic-garbage-collector"}
+			resp := validator.Handle(context.TODO(), req)
+			Expect(resp.Allowed).To(BeTrue())
+		})
+	})
+})
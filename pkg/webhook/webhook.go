@@ -0,0 +1,170 @@
+/*
+Copyright 2018 Pusher Ltd.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+
+	farosflags "github.com/pusher/faros/pkg/flags"
+	"github.com/pusher/faros/pkg/utils"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	rwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:port=9443,cert-dir=/tmp/k8s-webhook-server/serving-certs
+// +kubebuilder:webhook:service=faros-system:faros-webhook-service,selector=control-plane:faros
+// +kubebuilder:webhook:mutating-webhook-config-name=faros-mutating-webhook-configuration
+// +kubebuilder:webhook:validating-webhook-config-name=faros-validating-webhook-configuration
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations;validatingwebhookconfigurations,verbs=get;update
+
+// crdNames are the names of the Faros CRDs, which may convert between versions
+// through the conversion webhook
+var crdNames = []string{
+	"gittracks.faros.pusher.com",
+	"gittrackobjects.faros.pusher.com",
+	"clustergittrackobjects.faros.pusher.com",
+}
+
+// Options configure the webhook server
+type Options struct {
+	// Host is the address the webhook server listens on. Defaults to all
+	// addresses.
+	Host string
+
+	// Port is the port the webhook server listens on
+	Port int
+
+	// CertDir is the directory containing the serving certificate and key of
+	// the webhook server. A self-signed certificate is generated if none is
+	// provided.
+	CertDir string
+
+	// ServiceName is the name of the Service the API server reaches the
+	// webhook server through
+	ServiceName string
+
+	// ServiceNamespace is the namespace of the Service the API server reaches
+	// the webhook server through
+	ServiceNamespace string
+
+	// MutatingWebhookConfigName is the name of the
+	// MutatingWebhookConfiguration to inject the CA bundle into
+	MutatingWebhookConfigName string
+
+	// ValidatingWebhookConfigName is the name of the
+	// ValidatingWebhookConfiguration to inject the CA bundle into
+	ValidatingWebhookConfigName string
+
+	// EnableChildLock enables the webhook rejecting changes to children not
+	// made by Faros
+	EnableChildLock bool
+
+	// ChildLockWebhookConfigName is the name of the
+	// ValidatingWebhookConfiguration of the child lock to inject the CA bundle
+	// into
+	ChildLockWebhookConfigName string
+}
+
+// dnsNames returns the names the API server may address the Service by
+func (o Options) dnsNames() []string {
+	return []string{
+		fmt.Sprintf("%s.%s.svc", o.ServiceName, o.ServiceNamespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", o.ServiceName, o.ServiceNamespace),
+	}
+}
+
+// AddToManager adds a webhook server serving the Faros admission webhooks to
+// the Manager
+func AddToManager(mgr manager.Manager, opts Options) error {
+	caBundle, err := ensureCerts(opts.CertDir, opts.dnsNames())
+	if err != nil {
+		return fmt.Errorf("unable to ensure webhook certificates: %v", err)
+	}
+	// The cache isn't started yet so read and write the configurations
+	// directly
+	s := runtime.NewScheme()
+	if err := scheme.AddToScheme(s); err != nil {
+		return fmt.Errorf("unable to create scheme: %v", err)
+	}
+	if err := apiextensionsv1beta1.AddToScheme(s); err != nil {
+		return fmt.Errorf("unable to create scheme: %v", err)
+	}
+	c, err := client.New(mgr.GetConfig(), client.Options{Scheme: s})
+	if err != nil {
+		return fmt.Errorf("unable to create client: %v", err)
+	}
+	if caBundle != nil {
+		validatingNames := []string{opts.ValidatingWebhookConfigName}
+		if opts.EnableChildLock {
+			validatingNames = append(validatingNames, opts.ChildLockWebhookConfigName)
+		}
+		err = injectCABundle(c, caBundle, opts.MutatingWebhookConfigName, validatingNames...)
+		if err != nil {
+			return fmt.Errorf("unable to inject CA bundle: %v", err)
+		}
+		err = injectConversionCABundle(c, caBundle, crdNames...)
+		if err != nil {
+			return fmt.Errorf("unable to inject CA bundle: %v", err)
+		}
+	}
+
+	users, err := farosUsers(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+
+	server := &rwebhook.Server{
+		Host:    opts.Host,
+		Port:    opts.Port,
+		CertDir: opts.CertDir,
+	}
+	server.Register("/convert", &converter{})
+	server.Register("/mutate-gittracks", &admission.Webhook{Handler: &gitTrackDefaulter{}})
+	server.Register("/validate-gittracks", &admission.Webhook{Handler: &gitTrackValidator{}})
+	server.Register("/validate-gittrackobjects", &admission.Webhook{Handler: &gitTrackObjectValidator{users: users}})
+	if opts.EnableChildLock {
+		// Children are read directly as the cache only holds the kinds the
+		// controllers watch
+		server.Register("/validate-children", &admission.Webhook{Handler: &childLock{
+			client: mgr.GetClient(),
+			reader: c,
+			users:  users,
+			groups: farosflags.BreakGlassGroups,
+		}})
+	}
+	return mgr.Add(server)
+}
+
+// farosUsers returns the usernames Faros authenticates to the API server as:
+// the user of its own credentials, when it can be determined, and those given
+// by the faros-user flag
+func farosUsers(config *rest.Config) ([]string, error) {
+	users := append([]string{}, farosflags.Users...)
+	user, err := utils.UsernameFor(config)
+	if err == nil {
+		users = append(users, user)
+	} else if len(users) == 0 {
+		return nil, fmt.Errorf("unable to determine the user Faros authenticates as, set --faros-user: %v", err)
+	}
+	return users, nil
+}
@@ -222,6 +222,30 @@ func WithGitTrackObjectStatusConditions(matcher gtypes.GomegaMatcher) gtypes.Gom
 	}, matcher)
 }
 
+// WithGitTrackObjectLastHandledReconcileAt returns the GitTrackObject's last
+// handled reconcile request
+func WithGitTrackObjectLastHandledReconcileAt(matcher gtypes.GomegaMatcher) gtypes.GomegaMatcher {
+	return gomega.WithTransform(func(gto farosv1alpha1.GitTrackObjectInterface) string {
+		return gto.GetStatus().LastHandledReconcileAt
+	}, matcher)
+}
+
+// WithGitTrackObjectObservedDataHash returns the GitTrackObject's
+// ObservedDataHash
+func WithGitTrackObjectObservedDataHash(matcher gtypes.GomegaMatcher) gtypes.GomegaMatcher {
+	return gomega.WithTransform(func(gto farosv1alpha1.GitTrackObjectInterface) string {
+		return gto.GetStatus().ObservedDataHash
+	}, matcher)
+}
+
+// WithGitTrackObjectAppliedDataHash returns the GitTrackObject's
+// AppliedDataHash
+func WithGitTrackObjectAppliedDataHash(matcher gtypes.GomegaMatcher) gtypes.GomegaMatcher {
+	return gomega.WithTransform(func(gto farosv1alpha1.GitTrackObjectInterface) string {
+		return gto.GetStatus().AppliedDataHash
+	}, matcher)
+}
+
 // WithGitTrackObjectConditionType returns the GitTrackObjectCondition's type
 func WithGitTrackObjectConditionType(matcher gtypes.GomegaMatcher) gtypes.GomegaMatcher {
 	return gomega.WithTransform(func(c farosv1alpha1.GitTrackObjectCondition) farosv1alpha1.GitTrackObjectConditionType {
@@ -303,6 +327,13 @@ func WithEventType(matcher gtypes.GomegaMatcher) gtypes.GomegaMatcher {
 	}, matcher)
 }
 
+// WithEventCount returns the number of times the event occurred
+func WithEventCount(matcher gtypes.GomegaMatcher) gtypes.GomegaMatcher {
+	return gomega.WithTransform(func(ev *corev1.Event) int32 {
+		return ev.Count
+	}, matcher)
+}
+
 // WithContainers returns the deployment's containers
 func WithContainers(matcher gtypes.GomegaMatcher) gtypes.GomegaMatcher {
 	return gomega.WithTransform(func(dep *appsv1.Deployment) []corev1.Container {
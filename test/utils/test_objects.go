@@ -21,6 +21,7 @@ import (
 
 	farosv1alpha1 "github.com/pusher/faros/pkg/apis/faros/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
@@ -156,6 +157,31 @@ var ExampleDeployment = &appsv1.Deployment{
 	},
 }
 
+// ExampleJob is an example Job object for use within test suites
+var ExampleJob = &batchv1.Job{
+	TypeMeta: metav1.TypeMeta{
+		APIVersion: "batch/v1",
+		Kind:       "Job",
+	},
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "example",
+		Namespace: "default",
+	},
+	Spec: batchv1.JobSpec{
+		Template: corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				RestartPolicy: corev1.RestartPolicyNever,
+				Containers: []corev1.Container{
+					{
+						Name:  "nginx",
+						Image: "nginx",
+					},
+				},
+			},
+		},
+	},
+}
+
 // ExampleClusterRoleBinding is an example ClusterRoleBinding object for use within test suites
 var ExampleClusterRoleBinding = &rbacv1.ClusterRoleBinding{
 	TypeMeta: metav1.TypeMeta{